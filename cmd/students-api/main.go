@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
@@ -8,21 +10,386 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
 	"github.com/Priyang1310/Students-API-GO/internal/config"
-	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/student"
-	"github.com/Priyang1310/Students-API-GO/internal/storage/sqlite"
+	"github.com/Priyang1310/Students-API-GO/internal/grading"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/attachment"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/login"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/reportcard"
+	"github.com/Priyang1310/Students-API-GO/internal/http/middleware"
+	"github.com/Priyang1310/Students-API-GO/internal/http/routes"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/report"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/memory"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/postgres"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
 )
 
+// newStorage initializes the storage backend selected by storage.driver in the configuration.
+// It returns an error for an unknown driver or when the backend cannot be initialized.
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.Storage.Driver {
+	case "", "sqlite":
+		// The sqlite backend is only built in with the sqlite_fts5 tag, see sqlite.go
+		return newSqlite(cfg)
+	case "postgres":
+		db, err := postgres.New(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case "memory":
+		// The memory backend keeps everything in process and never touches storage_path
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+// courseRoutes registers the course catalog and enrollment routes when the storage backend supports them.
+// Only the sqlite backend implements storage.CourseStorage, the other backends serve students only.
+func courseRoutes(router routes.Router, db storage.Storage) {
+	courses, ok := db.(storage.CourseStorage)
+	if !ok {
+		slog.Warn("Storage backend has no course support, course routes are disabled")
+		return
+	}
+
+	routes.Courses(router, courses)
+}
+
+// gradeRoutes registers the grade and transcript routes when the storage backend supports them.
+// Letters and GPAs are computed with the grading scale of the tenant of the request.
+func gradeRoutes(router routes.Router, db storage.Storage, scales tenant.Settings[grading.Scale]) {
+	grades, ok := db.(storage.GradeStorage)
+	if !ok {
+		slog.Warn("Storage backend has no grade support, grade routes are disabled")
+		return
+	}
+
+	routes.Grades(router, grades, scales)
+}
+
+// attendanceRoutes registers the attendance routes when the storage backend supports them.
+func attendanceRoutes(router routes.Router, db storage.Storage) {
+	records, ok := db.(storage.AttendanceStorage)
+	if !ok {
+		slog.Warn("Storage backend has no attendance support, attendance routes are disabled")
+		return
+	}
+
+	routes.Attendance(router, records)
+}
+
+// guardianRoutes registers the guardian and emergency contact routes when the storage backend supports them.
+func guardianRoutes(router routes.Router, db storage.Storage) {
+	guardians, ok := db.(storage.GuardianStorage)
+	if !ok {
+		slog.Warn("Storage backend has no guardian support, guardian routes are disabled")
+		return
+	}
+
+	routes.Guardians(router, guardians)
+}
+
+// reportCardRoutes registers the report card route when the storage backend has both courses and grades.
+func reportCardRoutes(router routes.Router, db storage.Storage, scales tenant.Settings[grading.Scale], templates tenant.Settings[*report.Template]) {
+	source, ok := db.(reportcard.Storage)
+	if !ok {
+		slog.Warn("Storage backend has no course or grade support, report cards are disabled")
+		return
+	}
+
+	routes.ReportCards(router, source, scales, templates)
+}
+
+// attachmentRoutes registers the attachment routes when the storage backend supports them.
+func attachmentRoutes(router routes.Router, db storage.Storage, limits tenant.Settings[attachment.Limits]) {
+	attachments, ok := db.(storage.AttachmentStorage)
+	if !ok {
+		slog.Warn("Storage backend has no attachment support, attachment routes are disabled")
+		return
+	}
+
+	routes.Attachments(router, attachments, limits)
+}
+
+// historyRoutes registers the student history routes when the storage backend keeps one.
+// The sqlite and postgres backends implement storage.AuditStorage, the memory backend does not record changes.
+func historyRoutes(router routes.Router, db storage.Storage) {
+	history, ok := db.(storage.AuditStorage)
+	if !ok {
+		slog.Warn("Storage backend does not keep a student history, history routes are disabled")
+		return
+	}
+
+	routes.History(router, history)
+}
+
+// apiKeyRoutes registers the routes issuing and revoking API keys when the storage backend supports them.
+// Only the scopes the policy grants the caller can be put on a new key, so none is issued while authentication is disabled.
+func apiKeyRoutes(router routes.Router, db storage.Storage, policy *rbac.Policy) {
+	keys, ok := db.(storage.APIKeyStorage)
+	if !ok {
+		slog.Warn("Storage backend has no API key support, API key routes are disabled")
+		return
+	}
+
+	routes.APIKeys(router, keys, policy)
+}
+
+// newScale builds the grading scale configured by a grading section, an empty scale is the standard A-F 4.0 scale.
+func newScale(cfg config.Grading) (grading.Scale, error) {
+	bands := make([]grading.Band, len(cfg.Scale))
+	for i, band := range cfg.Scale {
+		bands[i] = grading.Band(band)
+	}
+	return grading.NewScale(bands)
+}
+
+// perTenant builds a setting of every tenant from a section of the configuration, e.g. the grading scale.
+// The tenants override the section with override, field by field, the other requests get the setting of the section itself.
+// A setting that cannot be built, e.g. a broken report card template of one school, fails at startup.
+func perTenant[S, T any](cfg config.Tenancy, section S, override func(config.Tenant) S, build func(S) (T, error)) tenant.Settings[T] {
+	setting, err := build(section)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	settings := tenant.Settings[T]{Default: setting, Tenants: make(map[string]T)}
+	if !cfg.Enabled {
+		return settings
+	}
+
+	for id, school := range cfg.Tenants {
+		setting, err := build(config.Overlay(section, override(school)))
+		if err != nil {
+			log.Fatalf("tenant %s: %v", id, err)
+		}
+		settings.Tenants[id] = setting
+	}
+	return settings
+}
+
+// resolveTenant wraps the handler in the middleware resolving the tenant of every request, when tenancy is enabled.
+// Without it every request belongs to the default tenant, i.e. the instance serves a single school.
+// Clients whose token names no tenant only reach the default one, unless the policy grants them rbac.CrossTenant.
+// Without authentication there is no client to hold to a tenant, every request reaches the tenant it names.
+func resolveTenant(handler http.Handler, cfg config.Tenancy, policy *rbac.Policy, authenticated bool) http.Handler {
+	if !cfg.Enabled {
+		slog.Info("Tenancy is disabled, every request belongs to the default tenant")
+		return handler
+	}
+
+	resolver, err := tenant.NewResolver(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	slog.Info("Tenancy enabled", slog.Int("tenants", len(cfg.Tenants)), slog.String("header", cfg.Header), slog.String("domain", cfg.Domain))
+	if !authenticated {
+		slog.Warn("Authentication is disabled, every request reaches the tenant it names")
+	}
+	return middleware.Tenant(resolver, policy)(handler)
+}
+
+// singleSignOn builds the staff sign-in configured by the oidc section, it returns nil when the sign-in is disabled.
+func singleSignOn(cfg *config.Config) *auth.OIDC {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+
+	sso, err := auth.NewOIDC(context.Background(), cfg.OIDC, cfg.Auth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return sso
+}
+
+// loginRoutes serves the sign-in routes in front of the handler, so they can be reached without being signed in.
+// Every other request is passed on to the handler.
+func loginRoutes(handler http.Handler, sso *auth.OIDC) http.Handler {
+	if sso == nil {
+		return handler
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	mux.HandleFunc("GET /auth/login", login.Login(sso))       // Sign in with the identity provider
+	mux.HandleFunc("GET /auth/callback", login.Callback(sso)) // Finish the sign-in and set the session cookie
+	mux.HandleFunc("POST /auth/logout", login.Logout(sso))    // Clear the session cookie
+	return mux
+}
+
+// authenticate wraps the handler in the authentication and authorization middlewares configured by the auth and rbac sections.
+// Every request must carry a valid token, an API key when the storage backend keeps them, or the session cookie
+// of a signed-in staff member when sso is not nil, and the roles or scopes of its client must grant the permission the policy requires for the route.
+// With authentication disabled every route is open to anyone, which is only acceptable for local development.
+func authenticate(handler http.Handler, router *http.ServeMux, db storage.Storage, sso *auth.OIDC, policy *rbac.Policy, cfg *config.Config) http.Handler {
+	if !cfg.Auth.Enabled {
+		slog.Warn("Authentication is disabled, every route is open to anyone")
+		return handler
+	}
+
+	tokens, err := auth.NewJWT(cfg.Auth)
+	if err != nil {
+		log.Fatal(err)
+	}
+	authenticators := []auth.Authenticator{tokens}
+	if keys, ok := db.(storage.APIKeyStorage); ok {
+		authenticators = append(authenticators, auth.NewAPIKeys(keys))
+	}
+	if sso != nil {
+		authenticators = append(authenticators, sso)
+	}
+
+	// Authentication runs first, so the authorization finds the identity in the context
+	handler = middleware.Authorize(policy, router)(handler)
+	return middleware.Authenticate(authenticators...)(handler)
+}
+
+// newRouter creates the router serving the routes of the API over the storage backend.
+func newRouter(db storage.Storage, policy *rbac.Policy, scales tenant.Settings[grading.Scale],
+	reportCards tenant.Settings[*report.Template], limits tenant.Settings[attachment.Limits]) *http.ServeMux {
+	// Create a new HTTP request multiplexer to handle incoming requests.
+	router := http.NewServeMux()
+	registerRoutes(router, db, policy, scales, reportCards, limits)
+	return router
+}
+
+// registerRoutes registers the routes of the API over the storage backend.
+// The student routes are always served, the routes of the optional subsystems when the backend implements them.
+func registerRoutes(router routes.Router, db storage.Storage, policy *rbac.Policy, scales tenant.Settings[grading.Scale],
+	reportCards tenant.Settings[*report.Template], limits tenant.Settings[attachment.Limits]) {
+	// The student routes are served by every storage backend.
+	routes.Students(router, db)
+
+	// Register the routes of the optional subsystems the storage backend implements.
+	historyRoutes(router, db)
+	apiKeyRoutes(router, db, policy)
+	courseRoutes(router, db)
+	gradeRoutes(router, db, scales)
+	attendanceRoutes(router, db)
+	guardianRoutes(router, db)
+	reportCardRoutes(router, db, scales, reportCards)
+	attachmentRoutes(router, db, limits)
+}
+
+// purgeTrash deletes the students whose time in the trash exceeds the configured retention for good.
+// It purges once at startup and then every purge interval, until the context is cancelled.
+// A retention of zero keeps deleted students forever, nothing is started then.
+func purgeTrash(ctx context.Context, db storage.Storage, cfg config.Trash) {
+	if cfg.Retention <= 0 || cfg.PurgeInterval <= 0 {
+		slog.Info("Trash purge disabled, deleted students are kept until restored")
+		return
+	}
+
+	purge := func() {
+		purged, err := db.PurgeDeletedStudents(ctx, time.Now().Add(-cfg.Retention))
+		if err != nil {
+			slog.Error("Could not purge deleted students", slog.String("error", err.Error()))
+			return
+		}
+		if purged > 0 {
+			slog.Info("Purged deleted students", slog.Int64("count", purged))
+		}
+	}
+
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for purge(); ; {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purge()
+		}
+	}
+}
+
+// newHandler builds the handler serving the API over the storage backend with the settings of the configuration,
+// i.e. the router of every route wrapped in the middlewares applied to every request.
+// A setting that cannot be built, e.g. the rbac policy or a report card template, fails at startup.
+func newHandler(cfg *config.Config, db storage.Storage) http.Handler {
+	// Build the rbac policy, the routes require its permissions and API keys are limited to those of their issuer.
+	policy, err := rbac.NewPolicy(cfg.RBAC.Roles, cfg.RBAC.Routes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Build the grading scale used to turn scores into letters and grade points, for every tenant overriding it.
+	scales := perTenant(cfg.Tenancy, cfg.Grading, func(school config.Tenant) config.Grading { return school.Grading }, newScale)
+
+	// Build the report card templates, so a broken template fails at startup rather than on the first download.
+	// A school without a report_card.school override prints its own name.
+	reportCards := perTenant(cfg.Tenancy, cfg.ReportCard, func(school config.Tenant) config.ReportCard {
+		if school.ReportCard.School == "" {
+			school.ReportCard.School = school.Name
+		}
+		return school.ReportCard
+	}, report.New)
+
+	// Build the limits applied to uploaded files, the attachment directory is shared by the tenants.
+	limits := perTenant(cfg.Tenancy, cfg.Attachments, func(school config.Tenant) config.Attachments { return school.Attachments },
+		func(section config.Attachments) (attachment.Limits, error) {
+			return attachment.Limits{MaxSize: section.MaxSize, AllowedTypes: section.AllowedTypes}, nil
+		})
+
+	// Create the HTTP request multiplexer serving the API.
+	router := newRouter(db, policy, scales, reportCards, limits)
+
+	// Set up the staff sign-in with the identity provider, if enabled.
+	sso := singleSignOn(cfg)
+
+	// Wrap the router in the middlewares applied to every request, the last one added runs first:
+	// the storage query deadline, the tenant of the request, which depends on the client, the authentication
+	// and authorization of the client, the sign-in routes that must be reached before being authenticated,
+	// and the actor and request ID recorded in the audit log.
+	// The actor named by a proxy is replaced by the authenticated identity when authentication is enabled.
+	var handler http.Handler = router
+	handler = middleware.QueryTimeout(cfg.Storage.QueryTimeout, router, routes.AttachmentTransfers...)(handler)
+	handler = resolveTenant(handler, cfg.Tenancy, policy, cfg.Auth.Enabled)
+	handler = authenticate(handler, router, db, sso, policy, cfg)
+	handler = loginRoutes(handler, sso)
+	handler = middleware.Actor(handler)
+	handler = middleware.RequestID(handler)
+
+	return handler
+}
+
 // The main function is the entry point of the program.
 func main() {
 	// Load the configuration from the environment or a configuration file.
 	// The config.MustLoad function returns a Config object or panics if there's an error.
 	cfg := config.MustLoad()
 
+	// "students-api -config <path> migrate ..." manages the schema instead of starting the server.
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		runMigrate(cfg, args[1:])
+		return
+	}
+
+	// Apply the configured student validation rules before any request is validated.
+	err := validation.Configure(validation.Rules{
+		AgeMin:        cfg.Validation.AgeMin,
+		AgeMax:        cfg.Validation.AgeMax,
+		NameMinLength: cfg.Validation.NameMinLength,
+		NameMaxLength: cfg.Validation.NameMaxLength,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Initialize the database storage using the provided configuration.
-	// The sqlite.New function returns a Storage object or an error if the database cannot be initialized.
-	storage, err := sqlite.New(cfg)
+	// Pending schema migrations are applied while the storage is initialized.
+	// The newStorage function returns the configured Storage backend or an error if it cannot be initialized.
+	storage, err := newStorage(cfg)
 
 	// If there's an error initializing the database, log the error and exit the program.
 	if err != nil {
@@ -30,27 +397,23 @@ func main() {
 	}
 
 	// Log a message indicating that the storage has been initialized.
-	slog.Info("Storage Initialized!", slog.String("env", cfg.Env))
+	slog.Info("Storage Initialized!", slog.String("env", cfg.Env), slog.String("driver", cfg.Storage.Driver))
 
-	// Create a new HTTP request multiplexer to handle incoming requests.
-	router := http.NewServeMux()
-
-	// Define the routes for the API endpoints.
-	// Each route is associated with a specific handler function that will be called when the route is accessed.
-	router.HandleFunc("POST /api/students", student.New(storage))               // Create a new student
-	router.HandleFunc("GET /api/students/{id}", student.GetById(storage))       // Get a student by ID
-	router.HandleFunc("GET /api/students", student.GetAll(storage))             // Get all students
-	router.HandleFunc("PUT /api/students/{id}", student.Update(storage))        // Update a student
-	router.HandleFunc("DELETE /api/students/{id}", student.DeleteById(storage)) // Delete a student by ID
-	router.HandleFunc("DELETE /api/students", student.DeleteAll(storage))       // Delete all students
+	// Build the handler serving the API over the storage.
+	handler := newHandler(cfg, storage)
 
 	// Create a new HTTP server with the specified address and handler.
 	// The server will listen for incoming requests on the specified address and route them to the associated handler functions.
 	server := http.Server{
 		Addr:    cfg.Addr, // The address the server will listen on (e.g., ":3000")
-		Handler: router,   // The router that will handle incoming requests
+		Handler: handler,  // The router that will handle incoming requests, wrapped in the middlewares
 	}
 
+	// Purge the students deleted longer ago than the retention in the background, until the server stops.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go purgeTrash(ctx, storage, cfg.Trash)
+
 	// Create a channel to listen for OS signals (like interrupt or termination).
 	done := make(chan os.Signal, 1)
 
@@ -74,6 +437,9 @@ func main() {
 	// This will block the main goroutine until a signal is received.
 	<-done
 
+	// Stop the background purge.
+	cancel()
+
 	// Log a message indicating that the server has been stopped.
 	fmt.Println("\n=======================")
 	log.Println("Server gracefully stopped")
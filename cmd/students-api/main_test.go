@@ -0,0 +1,298 @@
+//go:build sqlite_fts5
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/grading"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/attachment"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/report"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/sqlite"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// newTestServer serves the API with the handler main builds, over a new sqlite database and the settings of config/local.yaml
+// Tenancy is enabled with the north-high and south-academy schools, authentication is disabled
+func newTestServer(t *testing.T) (*httptest.Server, *sqlite.Sqlite) {
+	t.Helper()
+
+	cfg := config.Config{}
+	if err := cleanenv.ReadConfig(filepath.Join("..", "..", "config", "local.yaml"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.StoragePath = filepath.Join(t.TempDir(), "students.db")
+	cfg.Tenancy.Enabled = true
+
+	db, err := sqlite.New(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Db.Close() })
+
+	server := httptest.NewServer(newHandler(&cfg, db))
+	t.Cleanup(server.Close)
+	return server, db
+}
+
+// call sends a request for the tenant with the given JSON body, and returns the response and its body
+func call(t *testing.T, server *httptest.Server, school string, method string, path string, body string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Tenant-ID", school)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(data)
+}
+
+func TestCrossTenantReadsAreNotFound(t *testing.T) {
+	server, db := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), "north-high")
+
+	studentId, err := db.CreateStudent(ctx, "Ada Lovelace", "ada@example.com", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	courseId, err := db.CreateCourse(ctx, "CS101", "Computing", 3, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EnrollStudent(ctx, studentId, courseId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.RecordGrade(ctx, studentId, courseId, "Fall 2025", "2025-09-01", 95); err != nil {
+		t.Fatal(err)
+	}
+	file, err := db.CreateAttachment(ctx, studentId, "notes.pdf", "application/pdf", strings.NewReader("%PDF-1.4 notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	student := "/api/students/" + strconv.FormatInt(studentId, 10)
+	paths := []string{
+		student,
+		student + "/grades",
+		student + "/transcript",
+		student + "/report-card.pdf",
+		student + "/enrollments",
+		student + "/attachments",
+		student + "/attachments/" + strconv.FormatInt(file.Id, 10),
+		"/api/courses/" + strconv.FormatInt(courseId, 10),
+	}
+
+	for _, path := range paths {
+		if res, body := call(t, server, "north-high", http.MethodGet, path, ""); res.StatusCode != http.StatusOK {
+			t.Errorf("GET %s in its own tenant: got %d %s, want 200", path, res.StatusCode, body)
+		}
+		if res, body := call(t, server, "south-academy", http.MethodGet, path, ""); res.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s from another tenant: got %d %s, want 404", path, res.StatusCode, body)
+		}
+	}
+
+	// Nor can another tenant change them
+	if res, body := call(t, server, "south-academy", http.MethodDelete, student+"/attachments/"+strconv.FormatInt(file.Id, 10), ""); res.StatusCode != http.StatusNotFound {
+		t.Errorf("DELETE of an attachment from another tenant: got %d %s, want 404", res.StatusCode, body)
+	}
+	if res, body := call(t, server, "south-academy", http.MethodPost, student+"/grades", `{"course_id":`+strconv.FormatInt(courseId, 10)+`,"term":"Spring 2026","term_start":"2026-01-12","score":50}`); res.StatusCode != http.StatusNotFound {
+		t.Errorf("POST of a grade from another tenant: got %d %s, want 404", res.StatusCode, body)
+	}
+}
+
+func TestTenantHeaderWithoutAuthentication(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	// Without authentication any request reaches the tenant it names, but only a configured one
+	if res, body := call(t, server, "south-academy", http.MethodGet, "/api/students", ""); res.StatusCode != http.StatusOK {
+		t.Errorf("configured tenant: got %d %s, want 200", res.StatusCode, body)
+	}
+	if res, body := call(t, server, "east-high", http.MethodGet, "/api/students", ""); res.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown tenant: got %d %s, want 404", res.StatusCode, body)
+	}
+}
+
+// decode unmarshals the body of a response, failing the test if it is not the expected JSON
+func decode(t *testing.T, body string, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+}
+
+func TestGuardianLinks(t *testing.T) {
+	server, db := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), "north-high")
+
+	// Two siblings share their mother
+	var students []string
+	for _, name := range []string{"Ada Lovelace", "Byron Lovelace"} {
+		id, err := db.CreateStudent(ctx, name, strings.ToLower(strings.Fields(name)[0])+"@example.com", 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		students = append(students, "/api/students/"+strconv.FormatInt(id, 10))
+	}
+
+	res, body := call(t, server, "north-high", http.MethodPost, students[0]+"/guardians",
+		`{"name":"Anne Lovelace","relationship":"Mother","phone":"0044 20 7946 0000","priority":1}`)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("POST %s/guardians: got %d %s, want 201", students[0], res.StatusCode, body)
+	}
+	var mother types.Guardian
+	decode(t, body, &mother)
+	guardian := "/guardians/" + strconv.FormatInt(mother.Id, 10)
+	guardianStudents := "/api" + guardian + "/students"
+
+	res, body = call(t, server, "north-high", http.MethodPost, students[1]+guardian, `{"relationship":"mother","priority":2}`)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("POST %s%s: got %d %s, want 201", students[1], guardian, res.StatusCode, body)
+	}
+	var linked types.Guardian
+	decode(t, body, &linked)
+	if linked.Id != mother.Id || linked.Phone != "+442079460000" || linked.Priority != 2 {
+		t.Fatalf("linked guardian: got %+v, want the mother's details with the sibling's priority", linked)
+	}
+	if res, body := call(t, server, "north-high", http.MethodPost, students[1]+guardian, `{"relationship":"mother"}`); res.StatusCode != http.StatusConflict {
+		t.Fatalf("linking the guardian twice: got %d %s, want 409", res.StatusCode, body)
+	}
+
+	// listStudents returns the names of the students of the guardian, or the status if they cannot be listed
+	listStudents := func(school string) ([]string, int) {
+		t.Helper()
+
+		res, body := call(t, server, school, http.MethodGet, guardianStudents, "")
+		if res.StatusCode != http.StatusOK {
+			return nil, res.StatusCode
+		}
+		var list struct {
+			Data []types.Student `json:"data"`
+		}
+		decode(t, body, &list)
+		names := []string{}
+		for _, student := range list.Data {
+			names = append(names, student.Name)
+		}
+		return names, res.StatusCode
+	}
+
+	if names, _ := listStudents("north-high"); strings.Join(names, ", ") != "Ada Lovelace, Byron Lovelace" {
+		t.Fatalf("GET %s: got %v, want both siblings", guardianStudents, names)
+	}
+	if _, status := listStudents("south-academy"); status != http.StatusNotFound {
+		t.Fatalf("GET %s from another tenant: got %d, want 404", guardianStudents, status)
+	}
+
+	// Unlinking one sibling keeps the guardian of the other
+	if res, body := call(t, server, "north-high", http.MethodDelete, students[0]+guardian, ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE %s%s: got %d %s, want 200", students[0], guardian, res.StatusCode, body)
+	}
+	if names, _ := listStudents("north-high"); strings.Join(names, ", ") != "Byron Lovelace" {
+		t.Fatalf("GET %s after unlinking Ada: got %v, want only Byron", guardianStudents, names)
+	}
+	if res, _ := call(t, server, "north-high", http.MethodGet, students[0]+guardian, ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET of the unlinked guardian: got %d, want 404", res.StatusCode)
+	}
+
+	// The guardian is deleted with their last link
+	if res, body := call(t, server, "north-high", http.MethodDelete, students[1]+guardian, ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE %s%s: got %d %s, want 200", students[1], guardian, res.StatusCode, body)
+	}
+	if _, status := listStudents("north-high"); status != http.StatusNotFound {
+		t.Fatalf("GET %s after the last unlink: got %d, want 404", guardianStudents, status)
+	}
+}
+
+func TestReportCardPDF(t *testing.T) {
+	server, db := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), "north-high")
+
+	studentId, err := db.CreateStudent(ctx, "Ada Lovelace", "ada@example.com", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	courseId, err := db.CreateCourse(ctx, "CS101", "Computing", 3, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EnrollStudent(ctx, studentId, courseId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.RecordGrade(ctx, studentId, courseId, "Fall 2025", "2025-09-01", 95); err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/api/students/" + strconv.FormatInt(studentId, 10) + "/report-card.pdf"
+	res, body := call(t, server, "north-high", http.MethodGet, path, "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: got %d %s, want 200", path, res.StatusCode, body)
+	}
+	if contentType := res.Header.Get("Content-Type"); contentType != "application/pdf" {
+		t.Fatalf("GET %s: got Content-Type %q, want application/pdf", path, contentType)
+	}
+	if len(body) == 0 || !strings.HasPrefix(body, "%PDF") {
+		t.Fatalf("GET %s: got %d bytes starting with %.8q, want a PDF document", path, len(body), body)
+	}
+}
+
+// patterns records the patterns of the routes registered with it
+type patterns []string
+
+func (p *patterns) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	*p = append(*p, pattern)
+}
+
+func TestDefaultRoutesMatchRouter(t *testing.T) {
+	_, db := newTestServer(t)
+	policy, err := rbac.NewPolicy(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The sqlite backend implements every optional subsystem, so every route is registered
+	var registered patterns
+	registerRoutes(&registered, db, policy, tenant.Settings[grading.Scale]{}, tenant.Settings[*report.Template]{}, tenant.Settings[attachment.Limits]{})
+
+	// A route missing from the table is reserved to admins, which is never what a new route means
+	seen := map[string]bool{}
+	for _, pattern := range registered {
+		seen[pattern] = true
+		if _, ok := rbac.DefaultRoutes[pattern]; !ok {
+			t.Errorf("route %q has no entry in rbac.DefaultRoutes", pattern)
+		}
+	}
+
+	// An entry naming no route is a leftover of a renamed or removed route
+	for pattern := range rbac.DefaultRoutes {
+		if !seen[pattern] {
+			t.Errorf("rbac.DefaultRoutes entry %q names no registered route", pattern)
+		}
+	}
+}
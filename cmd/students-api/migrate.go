@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/migrate"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/postgres"
+)
+
+// migrateUsage describes the arguments accepted by the migrate subcommand.
+const migrateUsage = "usage: students-api -config <path> migrate up | down [steps] | status"
+
+// newMigrator opens the configured database without migrating it and returns its migrator.
+// The memory driver has no schema, so it is rejected.
+func newMigrator(cfg *config.Config) (*migrate.Migrator, error) {
+	switch cfg.Storage.Driver {
+	case "", "sqlite":
+		return sqliteMigrator(cfg)
+	case "postgres":
+		db, err := postgres.Open(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return db.Migrator()
+	default:
+		return nil, fmt.Errorf("storage driver %q does not support migrations", cfg.Storage.Driver)
+	}
+}
+
+// runMigrate implements the migrate subcommand.
+// "up" applies every pending migration, "down" reverts the last one (or the given number of steps)
+// and "status" lists every migration with the time it was applied.
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	migrator, err := newMigrator(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("applied %d migration(s)", count)
+
+	case "down":
+		// Revert a single migration unless a number of steps is given
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps %q", args[1])
+			}
+		}
+
+		count, err := migrator.Down(steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("reverted %d migration(s)", count)
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
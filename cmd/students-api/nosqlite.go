@@ -0,0 +1,25 @@
+//go:build !sqlite_fts5
+
+package main
+
+import (
+	"errors"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/migrate"
+)
+
+// errNoSqlite is returned for the sqlite driver by a binary built without the sqlite_fts5 tag.
+// The sqlite backend is only compiled in with the tag, its student search needs the FTS5 extension.
+var errNoSqlite = errors.New("the sqlite storage driver is not built in, build with -tags sqlite_fts5 (make build) or use the postgres or memory driver")
+
+// newSqlite refuses the sqlite driver, it is not built in.
+func newSqlite(cfg *config.Config) (storage.Storage, error) {
+	return nil, errNoSqlite
+}
+
+// sqliteMigrator refuses the sqlite driver, it is not built in.
+func sqliteMigrator(cfg *config.Config) (*migrate.Migrator, error) {
+	return nil, errNoSqlite
+}
@@ -0,0 +1,28 @@
+//go:build sqlite_fts5
+
+package main
+
+import (
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/migrate"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/sqlite"
+)
+
+// newSqlite initializes the sqlite storage backend, applying its pending migrations.
+func newSqlite(cfg *config.Config) (storage.Storage, error) {
+	db, err := sqlite.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// sqliteMigrator opens the sqlite database without migrating it and returns its migrator.
+func sqliteMigrator(cfg *config.Config) (*migrate.Migrator, error) {
+	db, err := sqlite.Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return db.Migrator()
+}
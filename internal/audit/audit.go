@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// Anonymous is the actor recorded for changes made by an unidentified client
+const Anonymous = "anonymous"
+
+// contextKey is the type of the context keys of this package, it avoids collisions with other packages
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+)
+
+// ignored lists the student fields that are not reported as changes, every write bumps the version anyway
+var ignored = map[string]bool{"id": true, "version": true}
+
+// WithActor returns a copy of the context carrying the identity of whoever makes the request
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the identity carried by the context, or Anonymous if there is none
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return Anonymous
+}
+
+// WithRequestID returns a copy of the context carrying the ID of the request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by the context, or "" if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// NewEntry function describes a change of a student made on behalf of the context's actor
+// It takes the action and the student before and after the change (nil when it did not exist)
+// and returns the entry with its field changes, ready to be appended to the history
+func NewEntry(ctx context.Context, action string, before *types.Student, after *types.Student) types.AuditEntry {
+	entry := types.AuditEntry{
+		Action:    action,
+		Actor:     Actor(ctx),
+		RequestId: RequestID(ctx),
+		At:        time.Now().UTC(),
+		Before:    before,
+		After:     after,
+		Changes:   Diff(before, after),
+	}
+
+	if after != nil {
+		entry.StudentId = after.Id
+	} else if before != nil {
+		entry.StudentId = before.Id
+	}
+
+	return entry
+}
+
+// Diff function compares two versions of a student field by field, as they appear in JSON
+// It returns the changed fields keyed by their JSON name, a field missing on one side is null
+func Diff(before *types.Student, after *types.Student) map[string]types.FieldChange {
+	old, current := fields(before), fields(after)
+
+	changes := make(map[string]types.FieldChange)
+	for name, value := range current {
+		if !ignored[name] && !reflect.DeepEqual(old[name], value) {
+			changes[name] = types.FieldChange{Before: old[name], After: value}
+		}
+	}
+	for name, value := range old {
+		if _, ok := current[name]; !ok && !ignored[name] {
+			changes[name] = types.FieldChange{Before: value, After: nil}
+		}
+	}
+
+	return changes
+}
+
+// fields returns the JSON fields of a student, nil for a missing student
+func fields(student *types.Student) map[string]any {
+	if student == nil {
+		return nil
+	}
+
+	// Encoding a student cannot fail, and decoding it back yields the same values a client sees
+	raw, _ := json.Marshal(student)
+	var values map[string]any
+	json.Unmarshal(raw, &values)
+	return values
+}
+
+// AsOf function returns the student as they were at the given time, according to their history
+// The entries must be in the order they were recorded, it reports false if the student did not exist
+// or was in the trash at that time
+// A history starting with an update or a deletion predates the audit log: the state before its
+// first entry is then taken as the state at every earlier time
+func AsOf(entries []types.AuditEntry, at time.Time) (types.Student, bool) {
+	if len(entries) == 0 {
+		return types.Student{}, false
+	}
+
+	// The last change made at or before the time holds the state of the student at that time
+	state := entries[0].Before
+	for _, entry := range entries {
+		if entry.At.After(at) {
+			break
+		}
+		state = entry.After
+	}
+
+	if state == nil || state.DeletedAt != nil {
+		return types.Student{}, false
+	}
+	return *state, true
+}
@@ -0,0 +1,113 @@
+package audit_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+func TestDiff(t *testing.T) {
+	ann := &types.Student{Id: 1, Name: "Ann Lee", Email: "ann@example.edu", Age: 15, Version: 1}
+	renamed := &types.Student{Id: 1, Name: "Ann Smith", Email: "ann@example.edu", Age: 15, Version: 2}
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	trashed := &types.Student{Id: 1, Name: "Ann Smith", Email: "ann@example.edu", Age: 15, Version: 3, DeletedAt: &deletedAt}
+
+	tests := []struct {
+		name   string
+		before *types.Student
+		after  *types.Student
+		want   map[string]types.FieldChange
+	}{
+		{"create", nil, ann, map[string]types.FieldChange{
+			"name":  {Before: nil, After: "Ann Lee"},
+			"email": {Before: nil, After: "ann@example.edu"},
+			"age":   {Before: nil, After: float64(15)},
+		}},
+		{"update", ann, renamed, map[string]types.FieldChange{
+			"name": {Before: "Ann Lee", After: "Ann Smith"},
+		}},
+		{"delete", renamed, trashed, map[string]types.FieldChange{
+			"deleted_at": {Before: nil, After: "2024-05-01T12:00:00Z"},
+		}},
+		{"restore", trashed, renamed, map[string]types.FieldChange{
+			"deleted_at": {Before: "2024-05-01T12:00:00Z", After: nil},
+		}},
+		{"only the version changed", ann, &types.Student{Id: 1, Name: "Ann Lee", Email: "ann@example.edu", Age: 15, Version: 9}, map[string]types.FieldChange{}},
+	}
+
+	for _, tt := range tests {
+		if got := audit.Diff(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Diff of a %s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	ann := &types.Student{Id: 7, Name: "Ann Lee", Email: "ann@example.edu", Age: 15, Version: 1}
+
+	// Without an actor in the context the change is anonymous
+	if entry := audit.NewEntry(context.Background(), types.AuditCreate, nil, ann); entry.Actor != audit.Anonymous || entry.StudentId != 7 {
+		t.Fatalf("NewEntry without an actor: got actor %q and student %d, want %q and 7", entry.Actor, entry.StudentId, audit.Anonymous)
+	}
+
+	ctx := audit.WithRequestID(audit.WithActor(context.Background(), "registrar"), "req-1")
+	entry := audit.NewEntry(ctx, types.AuditDelete, ann, nil)
+	if entry.Actor != "registrar" || entry.RequestId != "req-1" || entry.StudentId != 7 || entry.Action != types.AuditDelete {
+		t.Fatalf("NewEntry: got %+v, want the delete of student 7 by registrar in req-1", entry)
+	}
+}
+
+func TestAsOf(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+
+	v1 := &types.Student{Id: 1, Name: "Ann Lee", Age: 15, Version: 1}
+	v2 := &types.Student{Id: 1, Name: "Ann Smith", Age: 15, Version: 2}
+	deletedAt := at(3)
+	v3 := &types.Student{Id: 1, Name: "Ann Smith", Age: 15, Version: 3, DeletedAt: &deletedAt}
+	v4 := &types.Student{Id: 1, Name: "Ann Smith", Age: 15, Version: 4}
+
+	history := []types.AuditEntry{
+		{Action: types.AuditCreate, At: at(1), After: v1},
+		{Action: types.AuditUpdate, At: at(2), Before: v1, After: v2},
+		{Action: types.AuditDelete, At: at(3), Before: v2, After: v3},
+		{Action: types.AuditRestore, At: at(4), Before: v3, After: v4},
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want *types.Student
+	}{
+		{"before the creation", at(0), nil},
+		{"at the creation", at(1), v1},
+		{"between two changes", at(2).Add(30 * time.Minute), v2},
+		{"in the trash", at(3).Add(time.Minute), nil},
+		{"after the restore", at(10), v4},
+	}
+
+	for _, tt := range tests {
+		got, ok := audit.AsOf(history, tt.at)
+		if tt.want == nil {
+			if ok {
+				t.Errorf("AsOf %s: got %+v, want no student", tt.name, got)
+			}
+			continue
+		}
+		if !ok || got.Version != tt.want.Version {
+			t.Errorf("AsOf %s: got version %d (found %v), want version %d", tt.name, got.Version, ok, tt.want.Version)
+		}
+	}
+
+	// A history starting with an update predates the audit log, the student existed before it
+	if got, ok := audit.AsOf(history[1:], at(0)); !ok || got.Version != 1 {
+		t.Errorf("AsOf before a history starting with an update: got version %d (found %v), want version 1", got.Version, ok)
+	}
+	if _, ok := audit.AsOf(nil, at(0)); ok {
+		t.Errorf("AsOf of an empty history: got a student, want none")
+	}
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+const (
+	// apiKeyPrefix starts every API key, it makes a leaked key easy to recognize, e.g. by secret scanners
+	apiKeyPrefix = "sapi_"
+	// apiKeyBytes is the number of random bytes of a key
+	apiKeyBytes = 32
+	// displayLength is the number of characters of a key kept as its prefix
+	displayLength = len(apiKeyPrefix) + 8
+	// touchInterval is how stale the last use of a key may get, so that not every request writes to the database
+	touchInterval = time.Minute
+)
+
+// NewAPIKey generates a random API key and returns it with its display prefix and its hash
+// The key is only ever given to the client, the prefix and the hash are what is stored
+func NewAPIKey() (key string, prefix string, hash string, err error) {
+	random := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", "", fmt.Errorf("generating an API key: %w", err)
+	}
+
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+	return key, key[:displayLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of an API key
+// A fast hash is enough, the keys are random and too long to be guessed, unlike passwords
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeys struct authenticates service clients by the API key of the Authorization header
+// The scopes of the key become the scopes of the identity, the key has no roles and belongs to the tenant it was issued in
+type APIKeys struct {
+	storage storage.APIKeyStorage
+}
+
+// NewAPIKeys function builds the API key authenticator checking keys against the storage
+func NewAPIKeys(storage storage.APIKeyStorage) *APIKeys {
+	return &APIKeys{storage: storage}
+}
+
+// Scheme returns the HTTP authentication scheme of API keys
+func (a *APIKeys) Scheme() string {
+	return "ApiKey"
+}
+
+// Authenticate checks the API key of the Authorization header, e.g. "Authorization: ApiKey sapi_..."
+// It returns ErrNoCredentials if the request has no API key, and an error if the key is unknown, revoked or expired
+func (a *APIKeys) Authenticate(r *http.Request) (Identity, error) {
+	scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, a.Scheme()) {
+		return Identity{}, ErrNoCredentials
+	}
+
+	apiKey, err := a.storage.GetAPIKeyByHash(r.Context(), HashAPIKey(strings.TrimSpace(key)))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return Identity{}, fmt.Errorf("invalid API key")
+		}
+		return Identity{}, fmt.Errorf("checking API key: %w", err)
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil {
+		return Identity{}, fmt.Errorf("API key %s was revoked", apiKey.Prefix)
+	}
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return Identity{}, fmt.Errorf("API key %s has expired", apiKey.Prefix)
+	}
+
+	a.touch(r.Context(), apiKey, now)
+
+	return Identity{
+		Subject: fmt.Sprintf("apikey:%d", apiKey.Id),
+		Scopes:  apiKey.Scopes,
+		Tenant:  apiKey.Tenant,
+		Method:  "apikey",
+	}, nil
+}
+
+// touch records the use of a key, unless its last use was recorded less than touchInterval ago
+// Failing to record it does not reject the request, it is only logged
+func (a *APIKeys) touch(ctx context.Context, apiKey types.APIKey, now time.Time) {
+	if apiKey.LastUsedAt != nil && now.Sub(*apiKey.LastUsedAt) < touchInterval {
+		return
+	}
+
+	if err := a.storage.TouchAPIKey(ctx, apiKey.Id, now); err != nil {
+		slog.Warn("Could not record the use of an API key", slog.Int64("id", apiKey.Id), slog.String("error", err.Error()))
+	}
+}
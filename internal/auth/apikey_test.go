@@ -0,0 +1,139 @@
+package auth_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// keyStore is a storage.APIKeyStorage holding keys by hash and recording when they were touched
+type keyStore struct {
+	byHash  map[string]types.APIKey
+	touched []int64
+}
+
+func (s *keyStore) CreateAPIKey(ctx context.Context, key types.APIKey, hash string) (types.APIKey, error) {
+	key.Id = int64(len(s.byHash) + 1)
+	s.byHash[hash] = key
+	return key, nil
+}
+
+func (s *keyStore) GetAPIKeys(ctx context.Context) ([]types.APIKey, error) {
+	return nil, nil
+}
+
+func (s *keyStore) GetAPIKeyByHash(ctx context.Context, hash string) (types.APIKey, error) {
+	key, ok := s.byHash[hash]
+	if !ok {
+		return types.APIKey{}, storage.UnknownAPIKey()
+	}
+	return key, nil
+}
+
+func (s *keyStore) RevokeAPIKey(ctx context.Context, id int64) (types.APIKey, error) {
+	return types.APIKey{}, storage.APIKeyNotFound(id)
+}
+
+func (s *keyStore) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "sapi_") || !strings.HasPrefix(key, prefix) || len(prefix) != len("sapi_")+8 {
+		t.Fatalf("got key %q with prefix %q", key, prefix)
+	}
+	if hash != auth.HashAPIKey(key) || strings.Contains(hash, key) || len(hash) != 64 {
+		t.Fatalf("got hash %q of key %q, want its hex SHA-256", hash, key)
+	}
+
+	other, _, _, err := auth.NewAPIKey()
+	if err != nil || other == key {
+		t.Fatalf("got the same key twice: %q, %v", other, err)
+	}
+}
+
+func TestAPIKeysAuthenticate(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	recent, stale := now.Add(-10*time.Second), now.Add(-2*time.Minute)
+
+	tests := []struct {
+		name    string
+		key     types.APIKey
+		header  string // header is the Authorization header, the stored key is sent when empty
+		wantErr bool
+		touched bool
+	}{
+		{"never used", types.APIKey{Scopes: []string{"grades:read"}, Tenant: "north-high"}, "", false, true},
+		{"not expired", types.APIKey{ExpiresAt: &future}, "", false, true},
+		{"used within the last minute", types.APIKey{LastUsedAt: &recent}, "", false, false},
+		{"used over a minute ago", types.APIKey{LastUsedAt: &stale}, "", false, true},
+		{"expired", types.APIKey{ExpiresAt: &past}, "", true, false},
+		{"revoked", types.APIKey{RevokedAt: &past}, "", true, false},
+		{"unknown key", types.APIKey{}, "ApiKey sapi_unknown", true, false},
+		{"scheme in lower case", types.APIKey{}, "apikey ", false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, prefix, hash, err := auth.NewAPIKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			store := &keyStore{byHash: make(map[string]types.APIKey)}
+			test.key.Prefix = prefix
+			stored, _ := store.CreateAPIKey(context.Background(), test.key, hash)
+
+			req := httptest.NewRequest("GET", "/api/students", nil)
+			switch {
+			case test.header == "":
+				req.Header.Set("Authorization", "ApiKey "+key)
+			case strings.HasSuffix(test.header, " "):
+				req.Header.Set("Authorization", test.header+key)
+			default:
+				req.Header.Set("Authorization", test.header)
+			}
+
+			identity, err := auth.NewAPIKeys(store).Authenticate(req)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want the key rejected", identity)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if identity.Subject != "apikey:1" || identity.Method != "apikey" || identity.Tenant != test.key.Tenant ||
+					len(identity.Scopes) != len(test.key.Scopes) || len(identity.Roles) != 0 {
+					t.Fatalf("got %+v, want the identity of key %+v", identity, stored)
+				}
+			}
+			if touched := len(store.touched) > 0; touched != test.touched {
+				t.Fatalf("got the last use recorded %v, want %v", touched, test.touched)
+			}
+		})
+	}
+}
+
+func TestAPIKeysWithoutKey(t *testing.T) {
+	keys := auth.NewAPIKeys(&keyStore{byHash: make(map[string]types.APIKey)})
+
+	for _, header := range []string{"", "Bearer eyJhbGciOiJIUzI1NiJ9", "ApiKey"} {
+		req := httptest.NewRequest("GET", "/api/students", nil)
+		req.Header.Set("Authorization", header)
+		if _, err := keys.Authenticate(req); err != auth.ErrNoCredentials {
+			t.Errorf("Authorization %q: got %v, want ErrNoCredentials", header, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request carries no credentials of its kind
+// It lets the next Authenticator try, while any other error rejects the request
+var ErrNoCredentials = errors.New("missing credentials")
+
+// ErrCrossSite is returned by an Authenticator relying on cookies for a state-changing request sent by another site
+// The browser attaches the cookies to any request to the server, so the credentials prove nothing about who sent it
+var ErrCrossSite = errors.New("cross-site request refused")
+
+// Identity struct describes the authenticated client of a request
+type Identity struct {
+	Subject string         // Subject identifies the client, e.g. the sub claim of its token
+	Roles   []string       // Roles are the roles granted to the client, e.g. the roles claim of its token
+	Scopes  []string       // Scopes are permissions granted to the client directly, e.g. the scopes of its API key
+	Tenant  string         // Tenant is the school the client belongs to, e.g. the tenant claim of its token, "" if it is bound to none
+	Method  string         // Method is how the client authenticated, e.g. "jwt"
+	Claims  map[string]any // Claims holds every claim of the client's token, it is nil for other methods
+}
+
+// Authenticator is implemented by every way a client can prove its identity
+type Authenticator interface {
+	// Scheme is the HTTP authentication scheme of the credentials, it is announced in WWW-Authenticate
+	// An empty scheme announces nothing, e.g. for cookies
+	Scheme() string
+	// Authenticate checks the credentials of the request and returns the identity they prove
+	// It returns ErrNoCredentials if the request carries none of its kind
+	Authenticate(r *http.Request) (Identity, error)
+}
+
+// contextKey is the type of the context keys of this package, it avoids collisions with other packages
+type contextKey int
+
+const identityKey contextKey = iota
+
+// WithIdentity returns a copy of the context carrying the identity of the client
+// The subject also becomes the actor recorded in the audit log
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	ctx = audit.WithActor(ctx, identity.Subject)
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// FromContext returns the identity of the client carried by the context
+// It reports false when the request was not authenticated, e.g. because authentication is disabled
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey).(Identity)
+	return identity, ok
+}
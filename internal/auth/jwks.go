@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// jsonWebKey struct holds the members of a JSON Web Key (RFC 7517) needed for RSA signature keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// loadJWKS reads the RSA signature keys of a JSON Web Key Set file, keyed by their kid
+// Keys of other types or meant for encryption are skipped, a set without any usable key is an error
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJWKS(raw, path)
+}
+
+// parseJWKS parses the RSA signature keys of a JSON Web Key Set, keyed by their kid
+// source names the set in the errors, e.g. its path or URL
+func parseJWKS(raw []byte, source string) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: invalid modulus", source, key.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%s: key %q: invalid exponent", source, key.Kid)
+		}
+		if _, ok := keys[key.Kid]; ok {
+			return nil, fmt.Errorf("%s: key id %q is used twice", source, key.Kid)
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no RSA signature key found", source)
+	}
+
+	return keys, nil
+}
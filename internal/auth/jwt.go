@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWT struct authenticates clients by the JWT bearer token of the Authorization header
+// HS256 tokens are checked with a shared secret, RS256 tokens with RSA public keys selected by their kid header
+type JWT struct {
+	parser      *jwt.Parser
+	secret      []byte                    // secret checks HS256 tokens, nil if they are not accepted
+	rsaKeys     map[string]*rsa.PublicKey // rsaKeys check RS256 tokens by kid, the key of a PEM file has the kid ""
+	tenantClaim string                    // tenantClaim is the claim binding a client to one school
+}
+
+// NewJWT function builds the JWT authenticator configured by the auth section of the config
+// It fails if no key is configured or a key file cannot be read
+func NewJWT(cfg config.Auth) (*JWT, error) {
+	j := &JWT{rsaKeys: make(map[string]*rsa.PublicKey), tenantClaim: cfg.TenantClaim}
+
+	var methods []string
+	if cfg.HMACSecret != "" {
+		j.secret = []byte(cfg.HMACSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if cfg.PublicKeyPath != "" {
+		pem, err := os.ReadFile(cfg.PublicKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("auth public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("auth public key %s: %w", cfg.PublicKeyPath, err)
+		}
+		j.rsaKeys[""] = key
+	}
+
+	if cfg.JWKSPath != "" {
+		keys, err := loadJWKS(cfg.JWKSPath)
+		if err != nil {
+			return nil, fmt.Errorf("auth JWKS: %w", err)
+		}
+		for kid, key := range keys {
+			j.rsaKeys[kid] = key
+		}
+	}
+
+	if len(j.rsaKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("auth is enabled but no key is configured, set auth.hmac_secret, auth.public_key_path or auth.jwks_path")
+	}
+
+	// Only the configured algorithms are accepted, so an RS256 public key can never be abused as an HS256 secret
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	j.parser = jwt.NewParser(options...)
+
+	return j, nil
+}
+
+// Scheme returns the HTTP authentication scheme of JWT bearer tokens
+func (j *JWT) Scheme() string {
+	return "Bearer"
+}
+
+// Authenticate checks the bearer token of the Authorization header
+// It returns ErrNoCredentials if the request has no bearer token
+func (j *JWT) Authenticate(r *http.Request) (Identity, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Identity{}, ErrNoCredentials
+	}
+
+	return j.Verify(strings.TrimSpace(token))
+}
+
+// Verify checks the signature and the claims of a token and returns the identity it proves
+// The sub claim is mandatory, the optional roles claim lists the roles of the client
+// and the optional tenant claim, named by auth.tenant_claim, binds it to one school
+func (j *JWT) Verify(token string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	if _, err := j.parser.ParseWithClaims(token, claims, j.key); err != nil {
+		return Identity{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return Identity{}, fmt.Errorf("invalid token: the sub claim is required")
+	}
+
+	roles, err := stringList(claims["roles"])
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid token: the roles claim %w", err)
+	}
+
+	tenant, ok := claims[j.tenantClaim].(string)
+	if !ok && claims[j.tenantClaim] != nil {
+		return Identity{}, fmt.Errorf("invalid token: the %s claim must be a string", j.tenantClaim)
+	}
+
+	return Identity{
+		Subject: subject,
+		Roles:   roles,
+		Tenant:  tenant,
+		Method:  "jwt",
+		Claims:  claims,
+	}, nil
+}
+
+// key returns the key checking the signature of a token, according to its algorithm and kid header
+func (j *JWT) key(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return j.secret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := j.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		// A token without kid is fine as long as there is a single key to check it with
+		if kid == "" && len(j.rsaKeys) == 1 {
+			for _, key := range j.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+// stringList converts a claim holding a string or a list of strings
+func stringList(claim any) ([]string, error) {
+	switch value := claim.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.Fields(value), nil
+	case []any:
+		list := make([]string, 0, len(value))
+		for _, item := range value {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must only hold strings")
+			}
+			list = append(list, text)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("must be a string or a list of strings")
+	}
+}
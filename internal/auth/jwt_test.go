@@ -0,0 +1,266 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+// newKey generates an RSA key for signing RS256 test tokens
+func newKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// writePEM writes the public key of key to a PEM file and returns its path
+func writePEM(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "public.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// jwk returns the JSON Web Key of the public key of key
+func jwk(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// writeJWKS writes a JSON Web Key Set holding the given keys and returns its path
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sign signs the claims with the method and key, setting the kid header when it is not empty
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// claims returns valid claims for the subject, with the given overrides; a nil override removes the claim
+func claims(overrides jwt.MapClaims) jwt.MapClaims {
+	now := time.Now()
+	c := jwt.MapClaims{
+		"sub": "registrar@school.edu",
+		"iss": "https://idp.school.edu",
+		"aud": "students-api",
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(c, name)
+			continue
+		}
+		c[name] = value
+	}
+	return c
+}
+
+func TestVerify(t *testing.T) {
+	key, other := newKey(t), newKey(t)
+	pemPath := writePEM(t, key)
+	jwksPath := writeJWKS(t, jwk("k1", key), jwk("k2", other))
+	pemBytes, err := os.ReadFile(pemPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hmac := config.Auth{HMACSecret: secret, Issuer: "https://idp.school.edu", Audience: "students-api", TenantClaim: "tenant"}
+	rsaPEM := config.Auth{PublicKeyPath: pemPath, TenantClaim: "tenant"}
+	rsaJWKS := config.Auth{JWKSPath: jwksPath, TenantClaim: "tenant"}
+
+	tests := []struct {
+		name  string
+		cfg   config.Auth
+		token string
+		want  *auth.Identity // want is nil when the token must be rejected
+	}{
+		{"HS256", hmac, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"roles": []string{"registrar"}})),
+			&auth.Identity{Subject: "registrar@school.edu", Roles: []string{"registrar"}, Method: "jwt"}},
+		{"roles as a space separated string", hmac, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"roles": "teacher read-only"})),
+			&auth.Identity{Subject: "registrar@school.edu", Roles: []string{"teacher", "read-only"}, Method: "jwt"}},
+		{"RS256 with a PEM key", rsaPEM, sign(t, jwt.SigningMethodRS256, key, "", claims(nil)),
+			&auth.Identity{Subject: "registrar@school.edu", Method: "jwt"}},
+		{"RS256 with a JWKS kid", rsaJWKS, sign(t, jwt.SigningMethodRS256, other, "k2", claims(nil)),
+			&auth.Identity{Subject: "registrar@school.edu", Method: "jwt"}},
+
+		{"expired", hmac, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})), nil},
+		{"missing exp", hmac, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"exp": nil})), nil},
+		{"missing sub", hmac, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"sub": nil})), nil},
+		{"wrong secret", hmac, sign(t, jwt.SigningMethodHS256, []byte(strings.Repeat("x", 32)), "", claims(nil)), nil},
+		{"wrong issuer", hmac, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"iss": "https://evil.example"})), nil},
+		{"wrong audience", hmac, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"aud": "another-api"})), nil},
+		{"roles not strings", hmac, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"roles": []int{1}})), nil},
+		{"HS256 signed with the RSA public key", rsaPEM, sign(t, jwt.SigningMethodHS256, pemBytes, "", claims(nil)), nil},
+		{"HS256 against an RS256 key", rsaPEM, sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(nil)), nil},
+		{"alg none", hmac, sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claims(nil)), nil},
+		{"RS256 against an HS256 secret", hmac, sign(t, jwt.SigningMethodRS256, key, "", claims(nil)), nil},
+		{"unknown kid", rsaJWKS, sign(t, jwt.SigningMethodRS256, key, "k3", claims(nil)), nil},
+		{"kid of another key", rsaJWKS, sign(t, jwt.SigningMethodRS256, key, "k2", claims(nil)), nil},
+		{"no kid with several keys", rsaJWKS, sign(t, jwt.SigningMethodRS256, key, "", claims(nil)), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			j, err := auth.NewJWT(test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			identity, err := j.Verify(test.token)
+			if test.want == nil {
+				if err == nil {
+					t.Fatalf("got %+v, want the token rejected", identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			identity.Claims = nil
+			if !reflect.DeepEqual(identity, *test.want) {
+				t.Fatalf("got %+v, want %+v", identity, *test.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTenantClaim(t *testing.T) {
+	tests := []struct {
+		name    string
+		claim   string
+		claims  jwt.MapClaims
+		want    string
+		wantErr bool
+	}{
+		{"default claim", "tenant", jwt.MapClaims{"tenant": "north-high"}, "north-high", false},
+		{"configured claim", "school", jwt.MapClaims{"school": "north-high", "tenant": "south-high"}, "north-high", false},
+		{"no claim", "school", jwt.MapClaims{"tenant": "south-high"}, "", false},
+		{"not a string", "school", jwt.MapClaims{"school": []string{"north-high"}}, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			j, err := auth.NewJWT(config.Auth{HMACSecret: secret, TenantClaim: test.claim})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			identity, err := j.Verify(sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(test.claims)))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identity.Tenant != test.want {
+				t.Fatalf("got tenant %q, want %q", identity.Tenant, test.want)
+			}
+		})
+	}
+}
+
+func TestVerifyLeeway(t *testing.T) {
+	j, err := auth.NewJWT(config.Auth{HMACSecret: secret, Leeway: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Expired within the tolerated clock skew
+	if _, err := j.Verify(sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"exp": time.Now().Add(-30 * time.Second).Unix()}))); err != nil {
+		t.Fatalf("token expired within the leeway: %v", err)
+	}
+	if _, err := j.Verify(sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"exp": time.Now().Add(-2 * time.Minute).Unix()}))); err == nil {
+		t.Fatal("token expired beyond the leeway: got no error")
+	}
+}
+
+func TestNewJWTRejectsBadKeys(t *testing.T) {
+	key := newKey(t)
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ecKey := jwk("ec", key)
+	ecKey["kty"] = "EC"
+	encryption := jwk("enc", key)
+	encryption["use"] = "enc"
+
+	tests := []struct {
+		name string
+		cfg  config.Auth
+	}{
+		{"no key", config.Auth{}},
+		{"missing PEM file", config.Auth{PublicKeyPath: filepath.Join(dir, "missing.pem")}},
+		{"invalid PEM file", config.Auth{PublicKeyPath: notPEM}},
+		{"missing JWKS file", config.Auth{JWKSPath: filepath.Join(dir, "missing.json")}},
+		{"JWKS that is not JSON", config.Auth{JWKSPath: notPEM}},
+		{"JWKS without RSA signature keys", config.Auth{JWKSPath: writeJWKS(t, ecKey, encryption)}},
+		{"JWKS with a kid used twice", config.Auth{JWKSPath: writeJWKS(t, jwk("k1", key), jwk("k1", newKey(t)))}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := auth.NewJWT(test.cfg); err == nil {
+				t.Fatal("got no error")
+			}
+		})
+	}
+}
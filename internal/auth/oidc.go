@@ -0,0 +1,561 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// SessionCookie is the name of the cookie holding the session of a signed-in staff member
+	SessionCookie = "students_session"
+	// loginCookie holds the state of a sign-in in progress, between the login and the callback routes
+	loginCookie = "students_login"
+	// loginTTL is how long a staff member has to sign in at the identity provider
+	loginTTL = 10 * time.Minute
+
+	// The audiences of the tokens signed with the session secret, so a login state can never pass for a session
+	sessionAudience = "students-api:session"
+	loginAudience   = "students-api:login"
+
+	// keysRefreshInterval is how often, at most, the keys of the identity provider are fetched again for an unknown kid
+	keysRefreshInterval = time.Minute
+)
+
+var (
+	// ErrLoginState is returned by FinishLogin when the callback does not belong to a sign-in started by the client
+	ErrLoginState = errors.New("invalid login state")
+	// ErrLoginRejected is returned by FinishLogin when the identity provider refused to sign the staff member in
+	ErrLoginRejected = errors.New("sign-in rejected")
+)
+
+// providerMetadata struct holds the members of the discovery document of an identity provider used by the flow
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDC struct signs staff in with the OpenID Connect authorization code flow of an identity provider
+// The identity proven by the ID token is kept in a session cookie signed with the session secret,
+// which authenticates the following requests like a bearer token would
+type OIDC struct {
+	cfg      config.OIDC
+	client   *http.Client
+	oauth    *oauth2.Config
+	keys     *remoteKeys
+	idTokens *jwt.Parser // idTokens checks the ID tokens of the identity provider
+	sessions *jwt.Parser // sessions checks the session cookies signed by this server
+	logins   *jwt.Parser // logins checks the login states signed by this server
+	secret   []byte
+	origin   string // origin is the scheme and host of the redirect URL, the pages allowed to change data with the session
+}
+
+// NewOIDC function builds the OpenID Connect sign-in configured by the oidc section of the config
+// It reads the discovery document and the keys of the identity provider, so it fails if the provider is unreachable
+// bearer is the auth section: its leeway is the clock skew tolerated when checking the ID tokens, and the session secret
+// must differ from its HMAC secret, or a session cookie would pass as an HS256 bearer token
+func NewOIDC(ctx context.Context, cfg config.OIDC, bearer config.Auth) (*OIDC, error) {
+	switch {
+	case cfg.Issuer == "":
+		return nil, fmt.Errorf("oidc: issuer is required")
+	case cfg.ClientID == "" || cfg.RedirectURL == "":
+		return nil, fmt.Errorf("oidc: client_id and redirect_url are required")
+	case len(cfg.SessionSecret) < 32:
+		return nil, fmt.Errorf("oidc: session_secret must be at least 32 bytes long")
+	case cfg.SessionSecret == bearer.HMACSecret:
+		return nil, fmt.Errorf("oidc: session_secret must differ from auth.hmac_secret")
+	case !slices.Contains(cfg.Scopes, "openid"):
+		return nil, fmt.Errorf("oidc: the openid scope is required")
+	}
+
+	redirect, err := url.Parse(cfg.RedirectURL)
+	if err != nil || redirect.Scheme == "" || redirect.Host == "" {
+		return nil, fmt.Errorf("oidc: redirect_url %q is not an absolute URL", cfg.RedirectURL)
+	}
+
+	o := &OIDC{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		secret: []byte(cfg.SessionSecret),
+		origin: redirect.Scheme + "://" + redirect.Host,
+	}
+
+	var meta providerMetadata
+	discovery := strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := o.getJSON(ctx, discovery, &meta); err != nil {
+		return nil, fmt.Errorf("oidc: discovery: %w", err)
+	}
+	// The issuer of the document must be the configured one, or the tokens would be checked against the wrong provider
+	if meta.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("oidc: discovery: issuer %q does not match the configured %q", meta.Issuer, cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: discovery: the authorization, token and jwks endpoints are required")
+	}
+
+	o.keys = &remoteKeys{url: meta.JWKSURI, fetch: o.get}
+	if err := o.keys.refresh(ctx); err != nil {
+		return nil, fmt.Errorf("oidc: %w", err)
+	}
+
+	o.oauth = &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  meta.AuthorizationEndpoint,
+			TokenURL: meta.TokenEndpoint,
+		},
+	}
+
+	o.idTokens = jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(cfg.ClientID),
+		jwt.WithLeeway(bearer.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	o.sessions = jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(sessionAudience),
+		jwt.WithExpirationRequired(),
+	)
+	o.logins = jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(loginAudience),
+		jwt.WithExpirationRequired(),
+	)
+
+	return o, nil
+}
+
+// StartLogin begins the sign-in of a staff member and returns the URL of the identity provider to redirect them to
+// The state, nonce and PKCE verifier of the sign-in are kept in a short-lived signed cookie until the callback
+// returnTo is a local path the callback redirects to once signed in, loginHint is passed on to the identity provider
+func (o *OIDC) StartLogin(w http.ResponseWriter, returnTo string, loginHint string) (string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	// Only local paths are followed, so the callback cannot be turned into an open redirect
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.HasPrefix(returnTo, "/\\") {
+		returnTo = ""
+	}
+
+	expires := time.Now().Add(loginTTL)
+	login, err := o.sign(jwt.MapClaims{
+		"aud":       loginAudience,
+		"exp":       expires.Unix(),
+		"state":     state,
+		"nonce":     nonce,
+		"verifier":  verifier,
+		"return_to": returnTo,
+	})
+	if err != nil {
+		return "", err
+	}
+	o.setCookie(w, loginCookie, login, "/auth/callback", expires)
+
+	options := []oauth2.AuthCodeOption{
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	}
+	if loginHint != "" {
+		options = append(options, oauth2.SetAuthURLParam("login_hint", loginHint))
+	}
+
+	return o.oauth.AuthCodeURL(state, options...), nil
+}
+
+// FinishLogin completes the sign-in at the callback: it exchanges the code for an ID token, checks it
+// and sets the session cookie of the identity it proves
+// It returns the identity and the path the sign-in should return to, which may be empty
+// It fails with ErrLoginState if the callback does not match the sign-in started by the client,
+// with ErrLoginRejected if the identity provider refused the sign-in, and with any other error if it could not be asked
+func (o *OIDC) FinishLogin(w http.ResponseWriter, r *http.Request) (Identity, string, error) {
+	cookie, err := r.Cookie(loginCookie)
+	if err != nil {
+		return Identity{}, "", fmt.Errorf("%w: no sign-in in progress", ErrLoginState)
+	}
+	// The login state is single use, whatever the outcome
+	o.setCookie(w, loginCookie, "", "/auth/callback", time.Unix(0, 0))
+
+	login := jwt.MapClaims{}
+	if _, err := o.logins.ParseWithClaims(cookie.Value, login, o.secretKey); err != nil {
+		return Identity{}, "", fmt.Errorf("%w: %v", ErrLoginState, err)
+	}
+
+	query := r.URL.Query()
+	if query.Get("state") == "" || query.Get("state") != login["state"] {
+		return Identity{}, "", fmt.Errorf("%w: the state does not match", ErrLoginState)
+	}
+	if code := query.Get("error"); code != "" {
+		return Identity{}, "", fmt.Errorf("%w: %s %s", ErrLoginRejected, code, query.Get("error_description"))
+	}
+	if query.Get("code") == "" {
+		return Identity{}, "", fmt.Errorf("%w: no authorization code", ErrLoginState)
+	}
+
+	verifier, _ := login["verifier"].(string)
+	ctx := context.WithValue(r.Context(), oauth2.HTTPClient, o.client)
+	token, err := o.oauth.Exchange(ctx, query.Get("code"), oauth2.VerifierOption(verifier))
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode != "" {
+			return Identity{}, "", fmt.Errorf("%w: %s %s", ErrLoginRejected, retrieveErr.ErrorCode, retrieveErr.ErrorDescription)
+		}
+		return Identity{}, "", fmt.Errorf("oidc: token exchange: %w", err)
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return Identity{}, "", fmt.Errorf("oidc: the token response has no id_token")
+	}
+
+	identity, err := o.verifyIDToken(rawIDToken, login["nonce"])
+	if err != nil {
+		return Identity{}, "", err
+	}
+
+	identity, err = o.startSession(w, identity)
+	if err != nil {
+		return Identity{}, "", err
+	}
+
+	returnTo, _ := login["return_to"].(string)
+	return identity, returnTo, nil
+}
+
+// verifyIDToken checks the signature, the claims and the nonce of an ID token and returns the identity it proves
+// The roles and the tenant of the staff member are read from the configured roles and tenant claims
+func (o *OIDC) verifyIDToken(rawIDToken string, nonce any) (Identity, error) {
+	claims := jwt.MapClaims{}
+	if _, err := o.idTokens.ParseWithClaims(rawIDToken, claims, o.keys.key); err != nil {
+		return Identity{}, fmt.Errorf("oidc: invalid id_token: %w", err)
+	}
+
+	// The nonce binds the ID token to this sign-in, a token replayed from another one is rejected
+	if claims["nonce"] == nil || claims["nonce"] != nonce {
+		return Identity{}, fmt.Errorf("oidc: invalid id_token: the nonce does not match")
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return Identity{}, fmt.Errorf("oidc: invalid id_token: the sub claim is required")
+	}
+
+	roles, err := stringList(claims[o.cfg.RolesClaim])
+	if err != nil {
+		return Identity{}, fmt.Errorf("oidc: invalid id_token: the %s claim %w", o.cfg.RolesClaim, err)
+	}
+
+	tenant, ok := claims[o.cfg.TenantClaim].(string)
+	if !ok && claims[o.cfg.TenantClaim] != nil {
+		return Identity{}, fmt.Errorf("oidc: invalid id_token: the %s claim must be a string", o.cfg.TenantClaim)
+	}
+
+	return Identity{
+		Subject: subject,
+		Roles:   roles,
+		Tenant:  tenant,
+		Method:  "oidc",
+		Claims:  claims,
+	}, nil
+}
+
+// startSession sets the session cookie of an identity and returns the identity as the session cookie will prove it
+// Only the subject, the roles, the tenant, the name and the email are kept, to keep the cookie small
+func (o *OIDC) startSession(w http.ResponseWriter, identity Identity) (Identity, error) {
+	now := time.Now()
+	expires := now.Add(o.cfg.SessionTTL)
+
+	claims := jwt.MapClaims{
+		"aud":   sessionAudience,
+		"sub":   identity.Subject,
+		"roles": identity.Roles,
+		"iat":   now.Unix(),
+		"exp":   expires.Unix(),
+	}
+	if identity.Tenant != "" {
+		claims["tenant"] = identity.Tenant
+	}
+	for _, name := range []string{"name", "email"} {
+		if value, ok := identity.Claims[name].(string); ok {
+			claims[name] = value
+		}
+	}
+
+	session, err := o.sign(claims)
+	if err != nil {
+		return Identity{}, err
+	}
+	o.setCookie(w, SessionCookie, session, "/", expires)
+
+	identity.Claims = claims
+	return identity, nil
+}
+
+// Logout ends the session of the client by clearing its session cookie
+// The session of a stolen cookie stays valid until it expires, keep the session TTL short
+func (o *OIDC) Logout(w http.ResponseWriter) {
+	o.setCookie(w, SessionCookie, "", "/", time.Unix(0, 0))
+}
+
+// Scheme returns no HTTP authentication scheme, a session cookie cannot be asked for with a WWW-Authenticate challenge
+func (o *OIDC) Scheme() string {
+	return ""
+}
+
+// Authenticate checks the session cookie of the request
+// It returns ErrNoCredentials if the request has no session cookie, and ErrCrossSite if a request changing data
+// does not come from a page of the server: SameSite=Lax alone lets through requests of sibling subdomains and older browsers
+func (o *OIDC) Authenticate(r *http.Request) (Identity, error) {
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil || cookie.Value == "" {
+		return Identity{}, ErrNoCredentials
+	}
+	if !safeMethod(r.Method) && !o.sameOrigin(r) {
+		return Identity{}, fmt.Errorf("%w: the session only changes data from %s", ErrCrossSite, o.origin)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := o.sessions.ParseWithClaims(cookie.Value, claims, o.secretKey); err != nil {
+		return Identity{}, fmt.Errorf("invalid session, sign in again: %w", err)
+	}
+
+	subject, _ := claims.GetSubject()
+	tenant, _ := claims["tenant"].(string)
+	roles, err := stringList(claims["roles"])
+	if subject == "" || err != nil {
+		return Identity{}, fmt.Errorf("invalid session, sign in again")
+	}
+
+	return Identity{
+		Subject: subject,
+		Roles:   roles,
+		Tenant:  tenant,
+		Method:  "oidc",
+		Claims:  claims,
+	}, nil
+}
+
+// safeMethod reports whether a request method only reads data
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// sameOrigin reports whether a request was sent by a page of the server, as told by its Origin header or else its Referer
+// A request telling neither is refused, browsers send the Origin of every request changing data
+func (o *OIDC) sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer, err := url.Parse(r.Referer())
+		if err != nil || referer.Host == "" {
+			return false
+		}
+		origin = referer.Scheme + "://" + referer.Host
+	}
+	return strings.EqualFold(origin, o.origin)
+}
+
+// sign signs claims with the session secret
+func (o *OIDC) sign(claims jwt.MapClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(o.secret)
+}
+
+// secretKey returns the session secret, it checks the signature of the session cookies and login states
+func (o *OIDC) secretKey(*jwt.Token) (any, error) {
+	return o.secret, nil
+}
+
+// setCookie sets an HTTP-only cookie, an expiry in the past deletes it
+// SameSite=Lax keeps the cookies out of cross-site form posts, while the redirect of the identity provider still carries them
+func (o *OIDC) setCookie(w http.ResponseWriter, name string, value string, path string, expires time.Time) {
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge <= 0 {
+		maxAge = -1
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   o.cfg.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// get fetches a document of the identity provider
+func (o *OIDC) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+// getJSON fetches a JSON document of the identity provider into v
+func (o *OIDC) getJSON(ctx context.Context, url string, v any) error {
+	raw, err := o.get(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	return nil
+}
+
+// remoteKeys struct caches the signature keys of an identity provider
+// They are fetched again when a token names an unknown kid, as the provider rotates its keys
+// The lock only guards the cache, the keys are fetched without it so a slow provider never blocks the lookup of a known kid
+type remoteKeys struct {
+	url   string
+	fetch func(ctx context.Context, url string) ([]byte, error)
+
+	mu       sync.Mutex
+	keys     map[string]*rsa.PublicKey
+	fetched  time.Time
+	inflight *keysFetch // inflight is the fetch in progress, nil when there is none
+}
+
+// keysFetch is a fetch of the keys shared by every caller asking for one while it is in progress
+type keysFetch struct {
+	done chan struct{} // done is closed once the fetch is over
+	err  error         // err is the error of the fetch, set before done is closed
+}
+
+// refresh fetches the keys again
+// A caller arriving while a fetch is in progress waits for that one rather than starting another
+func (k *remoteKeys) refresh(ctx context.Context) error {
+	k.mu.Lock()
+	if call := k.inflight; call != nil {
+		k.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return fmt.Errorf("keys: %w", ctx.Err())
+		}
+	}
+	call := &keysFetch{done: make(chan struct{})}
+	k.inflight = call
+	k.mu.Unlock()
+
+	keys, err := k.download(ctx)
+
+	k.mu.Lock()
+	if err == nil {
+		k.keys = keys
+		k.fetched = time.Now()
+	}
+	call.err = err
+	k.inflight = nil
+	k.mu.Unlock()
+	close(call.done)
+
+	return err
+}
+
+// download fetches and parses the keys, without touching the cache
+func (k *remoteKeys) download(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	raw, err := k.fetch(ctx, k.url)
+	if err != nil {
+		return nil, fmt.Errorf("keys: %w", err)
+	}
+	keys, err := parseJWKS(raw, k.url)
+	if err != nil {
+		return nil, fmt.Errorf("keys: %w", err)
+	}
+	return keys, nil
+}
+
+// cached returns the cached key of a kid, and whether the cache is old enough to be fetched again
+// A token without kid is fine as long as there is a single key to check it with
+func (k *remoteKeys) cached(kid string) (*rsa.PublicKey, bool, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key, ok := k.keys[kid]; ok {
+		return key, true, false
+	}
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true, false
+		}
+	}
+	return nil, false, time.Since(k.fetched) >= keysRefreshInterval
+}
+
+// key returns the key checking the signature of an ID token, selected by its kid header
+func (k *remoteKeys) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok, stale := k.cached(kid)
+	if ok {
+		return key, nil
+	}
+	// A new kid most likely means the keys were rotated, but a flood of unknown kids must not flood the provider
+	if stale {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := k.refresh(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok, _ := k.cached(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// randomToken returns 32 random bytes, base64url encoded
+func randomToken() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
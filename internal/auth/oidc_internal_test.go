@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksOf returns a JSON Web Key Set holding the public keys, keyed by kid
+func jwksOf(t *testing.T, keys map[string]*rsa.PrivateKey) []byte {
+	t.Helper()
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jsonWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	raw, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestRemoteKeysRefreshOnUnknownKid(t *testing.T) {
+	old, rotated := rsaKey(t), rsaKey(t)
+
+	// The provider serves the old key first, then rotates to the new one
+	served := map[string]*rsa.PrivateKey{"old": old}
+	fetches := 0
+	keys := &remoteKeys{url: "https://idp.example/jwks", fetch: func(ctx context.Context, url string) ([]byte, error) {
+		fetches++
+		return jwksOf(t, served), nil
+	}}
+	if err := keys.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	served = map[string]*rsa.PrivateKey{"old": old, "new": rotated}
+
+	lookup := func(kid string) (any, error) {
+		return keys.key(&jwt.Token{Header: map[string]any{"kid": kid}})
+	}
+
+	// A known kid never fetches the keys
+	if _, err := lookup("old"); err != nil || fetches != 1 {
+		t.Fatalf("known kid: got %v after %d fetches, want the key after 1", err, fetches)
+	}
+
+	// An unknown kid right after a fetch waits for the refresh interval, so a flood of them cannot flood the provider
+	if _, err := lookup("new"); err == nil || fetches != 1 {
+		t.Fatalf("unknown kid within the refresh interval: got %v after %d fetches, want an error after 1", err, fetches)
+	}
+
+	// Once the interval has passed the keys are fetched again and the rotated key is found
+	keys.fetched = time.Now().Add(-keysRefreshInterval)
+	key, err := lookup("new")
+	if err != nil || fetches != 2 {
+		t.Fatalf("unknown kid after the refresh interval: got %v after %d fetches, want the key after 2", err, fetches)
+	}
+	if !rotated.PublicKey.Equal(key) {
+		t.Fatal("got another key than the rotated one")
+	}
+
+	// A kid the provider does not have either is still unknown after the refresh
+	keys.fetched = time.Now().Add(-keysRefreshInterval)
+	if _, err := lookup("missing"); err == nil || fetches != 3 {
+		t.Fatalf("kid missing at the provider: got %v after %d fetches, want an error after 3", err, fetches)
+	}
+}
+
+func TestRemoteKeysRefreshFailure(t *testing.T) {
+	keys := &remoteKeys{url: "https://idp.example/jwks", fetch: func(ctx context.Context, url string) ([]byte, error) {
+		return nil, fmt.Errorf("GET %s: 503 Service Unavailable", url)
+	}}
+
+	if _, err := keys.key(&jwt.Token{Header: map[string]any{"kid": "k1"}}); err == nil {
+		t.Fatal("got a key while the provider is down")
+	}
+}
+
+func TestRemoteKeysSlowRefresh(t *testing.T) {
+	old, rotated := rsaKey(t), rsaKey(t)
+
+	served := jwksOf(t, map[string]*rsa.PrivateKey{"old": old})
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	keys := &remoteKeys{url: "https://idp.example/jwks", fetch: func(ctx context.Context, url string) ([]byte, error) {
+		// Every fetch but the first hangs until released, like a slow provider
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		return served, nil
+	}}
+	if err := keys.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	served = jwksOf(t, map[string]*rsa.PrivateKey{"old": old, "new": rotated})
+	keys.fetched = time.Now().Add(-keysRefreshInterval)
+
+	lookup := func(kid string) (any, error) {
+		return keys.key(&jwt.Token{Header: map[string]any{"kid": kid}})
+	}
+
+	// Concurrent callbacks with the rotated kid share a single fetch
+	errs := make(chan error, 5)
+	for range cap(errs) {
+		go func() {
+			_, err := lookup("new")
+			errs <- err
+		}()
+	}
+	for fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// While that fetch hangs, a known kid is still answered at once
+	found := make(chan error, 1)
+	go func() {
+		_, err := lookup("old")
+		found <- err
+	}()
+	select {
+	case err := <-found:
+		if err != nil {
+			t.Fatalf("known kid during a refresh: got %v, want the key", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("known kid during a refresh: blocked by the fetch")
+	}
+
+	close(release)
+	for range cap(errs) {
+		if err := <-errs; err != nil {
+			t.Fatalf("rotated kid: got %v, want the key", err)
+		}
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Fatalf("got %d fetches, want the concurrent lookups to share 1 refresh", n-1)
+	}
+}
+
+// rsaKey generates an RSA key for signing test tokens
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
@@ -0,0 +1,150 @@
+package grading
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// Band represents one letter grade of a Scale
+type Band struct {
+	Letter   string  // Letter is the letter grade (e.g. "A-")
+	MinScore float64 // MinScore is the lowest score, out of 100, earning the letter
+	Points   float64 // Points is the grade point value of the letter
+}
+
+// Scale maps scores to letter grades, its bands are ordered from the highest MinScore down
+type Scale []Band
+
+// DefaultScale is the standard A-F scale on 4.0 points, used when the config declares none
+var DefaultScale = Scale{
+	{Letter: "A", MinScore: 93, Points: 4.0},
+	{Letter: "A-", MinScore: 90, Points: 3.7},
+	{Letter: "B+", MinScore: 87, Points: 3.3},
+	{Letter: "B", MinScore: 83, Points: 3.0},
+	{Letter: "B-", MinScore: 80, Points: 2.7},
+	{Letter: "C+", MinScore: 77, Points: 2.3},
+	{Letter: "C", MinScore: 73, Points: 2.0},
+	{Letter: "C-", MinScore: 70, Points: 1.7},
+	{Letter: "D+", MinScore: 67, Points: 1.3},
+	{Letter: "D", MinScore: 65, Points: 1.0},
+	{Letter: "F", MinScore: 0, Points: 0.0},
+}
+
+// NewScale builds a Scale from its bands, in any order
+// It returns DefaultScale when there are no bands, and an error if a letter or minimum score
+// is declared twice or no band starts at 0, which would leave low scores without a letter
+func NewScale(bands []Band) (Scale, error) {
+	if len(bands) == 0 {
+		return DefaultScale, nil
+	}
+
+	scale := append(Scale(nil), bands...)
+	sort.Slice(scale, func(i, j int) bool {
+		return scale[i].MinScore > scale[j].MinScore
+	})
+
+	letters := make(map[string]bool)
+	for i, band := range scale {
+		if band.Letter == "" {
+			return nil, fmt.Errorf("grading scale: band with min_score %g has no letter", band.MinScore)
+		}
+		if letters[band.Letter] {
+			return nil, fmt.Errorf("grading scale: letter %q is declared twice", band.Letter)
+		}
+		if i > 0 && scale[i-1].MinScore == band.MinScore {
+			return nil, fmt.Errorf("grading scale: min_score %g is declared twice", band.MinScore)
+		}
+		letters[band.Letter] = true
+	}
+
+	if scale[len(scale)-1].MinScore != 0 {
+		return nil, fmt.Errorf("grading scale: the lowest band must start at min_score 0")
+	}
+
+	return scale, nil
+}
+
+// Band returns the band a score falls in
+func (s Scale) Band(score float64) Band {
+	for _, band := range s {
+		if score >= band.MinScore {
+			return band
+		}
+	}
+	return s[len(s)-1]
+}
+
+// Apply fills in the letter and points of a grade from its score
+func (s Scale) Apply(grade *types.Grade) {
+	band := s.Band(*grade.Score)
+	grade.Letter = band.Letter
+	grade.Points = band.Points
+}
+
+// Transcript builds the transcript of a student from their grades
+// The GPA of each term and the cumulative GPA are weighted by the credits of the courses,
+// courses without credits are listed but do not count towards the GPA
+// Term names are free text (e.g. "Fall 2025"), so the terms are ordered by the day they started,
+// and by name when two terms started the same day
+// The grades of a term are expected to share its start, the earliest one is the start of the term otherwise
+func (s Scale) Transcript(studentId int64, grades []types.CourseGrade) types.Transcript {
+	transcript := types.Transcript{StudentId: studentId, Terms: []types.TermSummary{}}
+
+	byTerm := make(map[string]*types.TermSummary)
+	var terms []string
+
+	for _, grade := range grades {
+		s.Apply(&grade.Grade)
+
+		term, ok := byTerm[grade.Term]
+		if !ok {
+			term = &types.TermSummary{Term: grade.Term, TermStart: grade.TermStart}
+			byTerm[grade.Term] = term
+			terms = append(terms, grade.Term)
+		}
+		term.Grades = append(term.Grades, grade)
+
+		// Starts are YYYY-MM-DD, so they compare as strings
+		if grade.TermStart < term.TermStart {
+			term.TermStart = grade.TermStart
+		}
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+		a, b := byTerm[terms[i]].TermStart, byTerm[terms[j]].TermStart
+		if a != b {
+			return a < b
+		}
+		return terms[i] < terms[j]
+	})
+
+	var totalPoints float64
+	for _, name := range terms {
+		term := byTerm[name]
+
+		var termPoints float64
+		for _, grade := range term.Grades {
+			term.Credits += grade.Course.Credits
+			termPoints += grade.Points * float64(grade.Course.Credits)
+		}
+		term.GPA = gpa(termPoints, term.Credits)
+
+		transcript.Credits += term.Credits
+		totalPoints += termPoints
+		transcript.Terms = append(transcript.Terms, *term)
+	}
+	transcript.GPA = gpa(totalPoints, transcript.Credits)
+
+	return transcript
+}
+
+// gpa divides the credit weighted points by the credits and rounds the result to two decimals
+func gpa(points float64, credits int) float64 {
+	if credits == 0 {
+		return 0
+	}
+	return math.Round(points/float64(credits)*100) / 100
+}
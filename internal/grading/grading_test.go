@@ -0,0 +1,128 @@
+package grading_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/grading"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// courseGrade returns the grade of a course worth credits, in a term starting on termStart
+func courseGrade(code string, credits int, term string, termStart string, score float64) types.CourseGrade {
+	return types.CourseGrade{
+		Grade:  types.Grade{Term: term, TermStart: termStart, Score: &score, GradedAt: time.Now().UTC()},
+		Course: types.Course{Code: code, Credits: credits},
+	}
+}
+
+func TestTranscriptOrdersTermsByStart(t *testing.T) {
+	// By name "Fall 2025" < "Spring 2026" < "Summer 2025", which is not the order they were taught in
+	grades := []types.CourseGrade{
+		courseGrade("CS101", 3, "Spring 2026", "2026-01-12", 95),
+		courseGrade("MA101", 3, "Fall 2025", "2025-09-01", 95),
+		courseGrade("PH101", 3, "Summer 2025", "2025-06-02", 95),
+		courseGrade("MA102", 3, "Fall 2025", "2025-09-01", 95),
+		// Two terms starting the same day are ordered by name
+		courseGrade("EN101", 3, "Winter 2026", "2026-01-12", 95),
+	}
+	// A grade back-filled long after the term ran is still listed in its term's place
+	backFilled := courseGrade("HI101", 3, "Fall 2024", "2024-09-02", 95)
+	backFilled.GradedAt = time.Now().UTC().AddDate(1, 0, 0)
+	grades = append(grades, backFilled)
+
+	transcript := grading.DefaultScale.Transcript(1, grades)
+
+	var got []string
+	for _, term := range transcript.Terms {
+		got = append(got, term.Term+" "+term.TermStart)
+	}
+	want := []string{"Fall 2024 2024-09-02", "Summer 2025 2025-06-02", "Fall 2025 2025-09-01", "Spring 2026 2026-01-12", "Winter 2026 2026-01-12"}
+	if len(got) != len(want) {
+		t.Fatalf("got terms %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got terms %q, want %q", got, want)
+		}
+	}
+}
+
+func TestTranscriptGPA(t *testing.T) {
+	tests := []struct {
+		name    string
+		grades  []types.CourseGrade
+		credits int
+		gpa     float64
+	}{
+		{"no grades", nil, 0, 0},
+		{"weighted by credits", []types.CourseGrade{
+			courseGrade("CS101", 4, "2025", "2025-09-01", 95), // A, 4.0
+			courseGrade("MA101", 1, "2025", "2025-09-01", 50), // F, 0.0
+		}, 5, 3.2},
+		{"zero-credit courses do not count", []types.CourseGrade{
+			courseGrade("CS101", 3, "2025", "2025-09-01", 85), // B, 3.0
+			courseGrade("PE101", 0, "2025", "2025-09-01", 10), // F, 0.0
+		}, 3, 3},
+		{"only zero-credit courses", []types.CourseGrade{
+			courseGrade("PE101", 0, "2025", "2025-09-01", 95),
+		}, 0, 0},
+		{"rounded to two decimals", []types.CourseGrade{
+			courseGrade("CS101", 1, "2025", "2025-09-01", 91), // A-, 3.7
+			courseGrade("MA101", 1, "2025", "2025-09-01", 88), // B+, 3.3
+			courseGrade("PH101", 1, "2025", "2025-09-01", 84), // B, 3.0
+		}, 3, 3.33},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transcript := grading.DefaultScale.Transcript(1, test.grades)
+			if transcript.Credits != test.credits || transcript.GPA != test.gpa {
+				t.Fatalf("got %d credits and GPA %v, want %d and %v", transcript.Credits, transcript.GPA, test.credits, test.gpa)
+			}
+		})
+	}
+}
+
+func TestTranscriptTermAndCumulativeGPA(t *testing.T) {
+	transcript := grading.DefaultScale.Transcript(1, []types.CourseGrade{
+		courseGrade("CS101", 3, "Fall 2025", "2025-09-01", 95),   // A, 4.0
+		courseGrade("MA101", 1, "Fall 2025", "2025-09-01", 75),   // C, 2.0
+		courseGrade("CS102", 2, "Spring 2026", "2026-01-12", 81), // B-, 2.7
+		courseGrade("PE101", 0, "Spring 2026", "2026-01-12", 0),  // F, but without credits
+	})
+
+	if len(transcript.Terms) != 2 {
+		t.Fatalf("got %d terms, want 2", len(transcript.Terms))
+	}
+	fallTerm, springTerm := transcript.Terms[0], transcript.Terms[1]
+	if fallTerm.Credits != 4 || fallTerm.GPA != 3.5 {
+		t.Fatalf("Fall 2025: got %d credits and GPA %v, want 4 and 3.5", fallTerm.Credits, fallTerm.GPA)
+	}
+	if springTerm.Credits != 2 || springTerm.GPA != 2.7 {
+		t.Fatalf("Spring 2026: got %d credits and GPA %v, want 2 and 2.7", springTerm.Credits, springTerm.GPA)
+	}
+	if len(springTerm.Grades) != 2 || springTerm.Grades[1].Letter != "F" {
+		t.Fatalf("Spring 2026: got grades %+v, want the zero-credit course listed with its letter", springTerm.Grades)
+	}
+
+	// (3*4.0 + 1*2.0 + 2*2.7) / 6 = 3.233...
+	if transcript.Credits != 6 || transcript.GPA != 3.23 {
+		t.Fatalf("cumulative: got %d credits and GPA %v, want 6 and 3.23", transcript.Credits, transcript.GPA)
+	}
+}
+
+func TestScaleBand(t *testing.T) {
+	tests := []struct {
+		score  float64
+		letter string
+	}{
+		{100, "A"}, {93, "A"}, {92.99, "A-"}, {65, "D"}, {64.9, "F"}, {0, "F"},
+	}
+
+	for _, test := range tests {
+		if band := grading.DefaultScale.Band(test.score); band.Letter != test.letter {
+			t.Errorf("Band(%v): got %s, want %s", test.score, band.Letter, test.letter)
+		}
+	}
+}
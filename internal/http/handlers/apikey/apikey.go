@@ -0,0 +1,121 @@
+package apikey
+
+import (
+	"fmt"      // Package for formatted I/O
+	"log/slog" // Package for structured logging
+	"net/http" // Package for HTTP client and server
+	"time"     // Package for checking the expiry
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/request"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+)
+
+// New returns an HTTP handler function for issuing an API key
+// This function handles the HTTP request to create a key with the given name, scopes and optional expiry
+// The key is only part of this response, it cannot be retrieved afterwards
+// A caller can only grant the permissions it holds itself, so a key may never outrank the client that issued it,
+// and no key is issued while authentication is disabled
+func New(storage storage.APIKeyStorage, policy *rbac.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Without authentication there is no caller whose permissions bound the scopes, and the keys would never be checked
+		identity, ok := auth.FromContext(r.Context())
+		if !ok {
+			response.WriteError(w, r, http.StatusForbidden, fmt.Errorf("API keys can only be issued when authentication is enabled"))
+			return
+		}
+
+		// Decode, normalize and validate the request
+		var req types.APIKeyRequest
+		if !request.DecodeJSON(w, r, &req) {
+			return
+		}
+		if !response.Validated(w, r, validation.APIKeyRequest(&req)) {
+			return
+		}
+
+		// Scopes are permissions of the rbac policy, e.g. "grades:read" or "students:*"
+		for _, scope := range req.Scopes {
+			if err := rbac.CheckGrant(scope); err != nil {
+				response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("invalid scope: %w", err))
+				return
+			}
+		}
+
+		for _, scope := range req.Scopes {
+			if !policy.Holds(identity.Roles, identity.Scopes, scope) {
+				slog.Warn("API key scope denied", slog.String("subject", identity.Subject), slog.String("scope", scope))
+				response.WriteError(w, r, http.StatusForbidden, fmt.Errorf("scope %q exceeds your own permissions", scope))
+				return
+			}
+		}
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("expires_at must be in the future"))
+			return
+		}
+
+		key, prefix, hash, err := auth.NewAPIKey()
+		if err != nil {
+			response.WriteError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		created, err := storage.CreateAPIKey(r.Context(), types.APIKey{
+			Name:      req.Name,
+			Prefix:    prefix,
+			Scopes:    req.Scopes,
+			CreatedBy: audit.Actor(r.Context()),
+			ExpiresAt: req.ExpiresAt,
+		}, hash)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("API Key Issued Successfully!", slog.Int64("id", created.Id), slog.String("prefix", created.Prefix))
+
+		response.WriteJSON(w, http.StatusCreated, types.IssuedAPIKey{APIKey: created, Key: key})
+	}
+}
+
+// GetAll returns an HTTP handler function for listing the API keys
+// This function handles the HTTP request to get every key, revoked and expired ones included, without the keys themselves
+func GetAll(storage storage.APIKeyStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keys, err := storage.GetAPIKeys(r.Context())
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": keys})
+	}
+}
+
+// Revoke returns an HTTP handler function for revoking an API key
+// This function handles the HTTP request to reject a key from now on, the key stays listed with its revocation time
+func Revoke(storage storage.APIKeyStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the key ID from the URL path
+		id, err := request.PathID(r, "id", "api key")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		revoked, err := storage.RevokeAPIKey(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("API Key Revoked Successfully!", slog.Int64("id", id))
+
+		response.WriteJSON(w, http.StatusOK, revoked)
+	}
+}
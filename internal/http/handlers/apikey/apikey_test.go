@@ -0,0 +1,103 @@
+package apikey_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/apikey"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// keyStore is a storage.APIKeyStorage keeping the keys it is given
+type keyStore struct {
+	created []types.APIKey
+}
+
+func (s *keyStore) CreateAPIKey(ctx context.Context, key types.APIKey, hash string) (types.APIKey, error) {
+	key.Id = int64(len(s.created) + 1)
+	s.created = append(s.created, key)
+	return key, nil
+}
+
+func (s *keyStore) GetAPIKeys(ctx context.Context) ([]types.APIKey, error) {
+	return s.created, nil
+}
+
+func (s *keyStore) GetAPIKeyByHash(ctx context.Context, hash string) (types.APIKey, error) {
+	return types.APIKey{}, storage.UnknownAPIKey()
+}
+
+func (s *keyStore) RevokeAPIKey(ctx context.Context, id int64) (types.APIKey, error) {
+	return types.APIKey{}, storage.APIKeyNotFound(id)
+}
+
+func (s *keyStore) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	policy, err := rbac.NewPolicy(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registrar := &auth.Identity{Subject: "registrar@school.edu", Roles: []string{rbac.RoleRegistrar}}
+	importer := &auth.Identity{Subject: "apikey:1", Scopes: []string{"students:*"}}
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		identity *auth.Identity // identity is nil when authentication is disabled
+		body     string
+		status   int
+	}{
+		{"scopes held by a role", registrar, `{"name":"import","scopes":["students:write","courses:*"]}`, http.StatusCreated},
+		{"scopes held by a scope", importer, `{"name":"import","scopes":["students:read","students:*"]}`, http.StatusCreated},
+		{"scope beyond the roles", registrar, `{"name":"import","scopes":["students:write","grades:write"]}`, http.StatusForbidden},
+		{"wildcard beyond the roles", registrar, `{"name":"import","scopes":["students:*"]}`, http.StatusForbidden},
+		{"everything", registrar, `{"name":"import","scopes":["*"]}`, http.StatusForbidden},
+		{"scope beyond the scopes", importer, `{"name":"import","scopes":["courses:read"]}`, http.StatusForbidden},
+		{"malformed scope", registrar, `{"name":"import","scopes":["students:*:read"]}`, http.StatusBadRequest},
+		{"no scopes", registrar, `{"name":"import","scopes":[]}`, http.StatusBadRequest},
+		{"expired", registrar, `{"name":"import","scopes":["students:read"],"expires_at":"` + past + `"}`, http.StatusBadRequest},
+		{"authentication disabled", nil, `{"name":"import","scopes":["students:read"]}`, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &keyStore{}
+			req := httptest.NewRequest(http.MethodPost, "/api/api-keys", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			if test.identity != nil {
+				req = req.WithContext(auth.WithIdentity(req.Context(), *test.identity))
+			}
+			res := httptest.NewRecorder()
+			apikey.New(store, policy).ServeHTTP(res, req)
+
+			if res.Code != test.status {
+				t.Fatalf("got %d %s, want %d", res.Code, res.Body, test.status)
+			}
+			if test.status != http.StatusCreated {
+				if len(store.created) != 0 {
+					t.Fatalf("got keys stored %+v, want none", store.created)
+				}
+				return
+			}
+
+			var issued types.IssuedAPIKey
+			if err := json.Unmarshal(res.Body.Bytes(), &issued); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(issued.Key, issued.Prefix) || issued.CreatedBy != test.identity.Subject {
+				t.Fatalf("got %+v, want the key issued by %s", issued, test.identity.Subject)
+			}
+		})
+	}
+}
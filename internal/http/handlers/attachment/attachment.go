@@ -0,0 +1,254 @@
+package attachment
+
+import (
+	"errors"        // Package for error handling
+	"fmt"           // Package for formatted I/O
+	"io"            // Package for I/O primitives
+	"log/slog"      // Package for structured logging
+	"mime"          // Package for media type headers
+	"net/http"      // Package for HTTP client and server
+	"path/filepath" // Package for file name handling
+	"strings"       // Package for string manipulation
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/request"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	// formField is the multipart form field holding the uploaded file
+	formField = "file"
+	// sniffLength is how much of the file is read to detect its type, the same amount mimetype reads by default
+	sniffLength = 3072
+	// maxFileName is the longest file name kept, longer names are cut
+	maxFileName = 255
+)
+
+// errTooLarge is reported when an uploaded file exceeds the configured size limit
+var errTooLarge = errors.New("file is too large")
+
+// Limits holds the restrictions applied to uploaded files
+type Limits struct {
+	MaxSize      int64    // MaxSize is the largest accepted file, in bytes
+	AllowedTypes []string // AllowedTypes lists the accepted media types, as detected from the content
+}
+
+// New returns an HTTP handler function for attaching a file to a student
+// This function handles the multipart/form-data request carrying the file in its "file" field
+// The type is detected from the content rather than trusted from the client, and the size is limited while streaming
+// The limits are the ones of the tenant of the request
+func New(storage storage.AttachmentStorage, limits tenant.Settings[Limits]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Leave some room for the multipart framing around the file
+		limit := limits.For(r.Context())
+		r.Body = http.MaxBytesReader(w, r.Body, limit.MaxSize+1<<20)
+
+		reader, err := r.MultipartReader()
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("body must be multipart/form-data: %w", err))
+			return
+		}
+
+		// Find the file among the parts of the form
+		var part io.Reader
+		var fileName string
+		for part == nil {
+			next, err := reader.NextPart()
+			if err == io.EOF {
+				response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("form field %q is missing", formField))
+				return
+			}
+			if err != nil {
+				writeUploadError(w, r, err)
+				return
+			}
+			if next.FormName() == formField {
+				part, fileName = next, next.FileName()
+			}
+		}
+
+		// Sniff the type from the first bytes, then put them back in front of the rest of the content
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(part, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			writeUploadError(w, r, err)
+			return
+		}
+		head = head[:n]
+
+		if n == 0 {
+			response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("file is empty"))
+			return
+		}
+
+		detected := mimetype.Detect(head)
+		if !allowed(detected, limit.AllowedTypes) {
+			response.WriteError(w, r, http.StatusUnsupportedMediaType, fmt.Errorf("files of type %s are not accepted", detected.String()))
+			return
+		}
+
+		content := &limitedReader{r: io.MultiReader(strings.NewReader(string(head)), part), remaining: limit.MaxSize}
+
+		attachment, err := storage.CreateAttachment(r.Context(), studentId, cleanFileName(fileName, detected), detected.String(), content)
+		if err != nil {
+			writeUploadError(w, r, err)
+			return
+		}
+
+		slog.Info("Attachment Stored Successfully!", slog.Int64("student_id", studentId), slog.Int64("id", attachment.Id))
+
+		response.WriteJSON(w, http.StatusCreated, attachment)
+	}
+}
+
+// GetAll returns an HTTP handler function for listing the attachments of a student
+// This function handles the HTTP request to get the metadata of every attachment, newest first
+func GetAll(storage storage.AttachmentStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		attachments, err := storage.GetStudentAttachments(r.Context(), studentId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": attachments})
+	}
+}
+
+// Download returns an HTTP handler function for downloading an attachment
+// This function handles the HTTP request to get the content of an attachment
+// Range, If-Range and If-None-Match requests are supported, the checksum serves as the ETag
+func Download(storage storage.AttachmentStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student and attachment IDs from the URL path
+		studentId, id, ok := ids(w, r)
+		if !ok {
+			return
+		}
+
+		attachment, file, err := storage.OpenAttachment(r.Context(), studentId, id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		defer file.Close()
+
+		// The stored type was detected from the content, browsers must not guess another one
+		w.Header().Set("Content-Type", attachment.ContentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("ETag", `"`+attachment.Checksum+`"`)
+
+		// ServeContent answers Range requests with 206 Partial Content and handles the conditional headers
+		http.ServeContent(w, r, attachment.FileName, attachment.CreatedAt, file)
+	}
+}
+
+// Delete returns an HTTP handler function for deleting an attachment
+// This function handles the HTTP request to remove an attachment, metadata and content
+// Deleting the student instead keeps the file, it is only removed once the student is purged from the trash
+func Delete(storage storage.AttachmentStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student and attachment IDs from the URL path
+		studentId, id, ok := ids(w, r)
+		if !ok {
+			return
+		}
+
+		if err := storage.DeleteAttachment(r.Context(), studentId, id); err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Attachment Deleted Successfully!", slog.Int64("student_id", studentId), slog.Int64("id", id))
+
+		response.WriteJSON(w, http.StatusOK, "attachment deleted successfully")
+	}
+}
+
+// ids parses the student and attachment IDs of a /api/students/{id}/attachments/{attachmentId} path
+// It writes a 400 problem and returns false if either is malformed
+func ids(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	studentId, err := request.PathID(r, "id", "student")
+	if err != nil {
+		response.WriteError(w, r, http.StatusBadRequest, err)
+		return 0, 0, false
+	}
+
+	id, err := request.PathID(r, "attachmentId", "attachment")
+	if err != nil {
+		response.WriteError(w, r, http.StatusBadRequest, err)
+		return 0, 0, false
+	}
+
+	return studentId, id, true
+}
+
+// allowed reports whether the detected type is one of the accepted types (or an alias of one)
+func allowed(detected *mimetype.MIME, allowedTypes []string) bool {
+	for _, allowedType := range allowedTypes {
+		if detected.Is(allowedType) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFileName keeps the base name of a client supplied file name, without any directory
+// Backslashes separate directories too, as in the names sent by Windows browsers
+// A missing name, or one that is only a directory like "..", is replaced by "attachment" with the extension of the detected type
+func cleanFileName(name string, detected *mimetype.MIME) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == ".." || name == "/" || strings.TrimSpace(name) == "" {
+		name = "attachment" + detected.Extension()
+	}
+	if len(name) > maxFileName {
+		name = name[len(name)-maxFileName:]
+	}
+	return name
+}
+
+// writeUploadError responds with the status matching an error raised while receiving or storing a file
+func writeUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, errTooLarge) || errors.As(err, &maxBytesErr) {
+		response.WriteError(w, r, http.StatusRequestEntityTooLarge, errTooLarge)
+		return
+	}
+
+	response.WriteStorageError(w, r, err)
+}
+
+// limitedReader reads from r until remaining bytes have been read, and fails with errTooLarge after that
+// Unlike io.LimitReader it reports the overflow instead of silently truncating the file
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+// Read implements io.Reader
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, errTooLarge
+	}
+	return n, err
+}
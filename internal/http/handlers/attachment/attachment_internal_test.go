@@ -0,0 +1,52 @@
+package attachment
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+func TestCleanFileName(t *testing.T) {
+	pdf := mimetype.Lookup("application/pdf")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{"/var/www/report.pdf", "report.pdf"},
+		{`C:\Users\ada\report.pdf`, "report.pdf"},
+		{`..\..\windows\win.ini`, "win.ini"},
+		{"reports/../report.pdf", "report.pdf"},
+		{"..", "attachment.pdf"},
+		{`..\..`, "attachment.pdf"},
+		{"../", "attachment.pdf"},
+		{"/", "attachment.pdf"},
+		{"", "attachment.pdf"},
+		{"   ", "attachment.pdf"},
+		{strings.Repeat("a", 300) + ".pdf", strings.Repeat("a", maxFileName-4) + ".pdf"},
+	}
+	for _, tt := range tests {
+		if got := cleanFileName(tt.name, pdf); got != tt.want {
+			t.Errorf("cleanFileName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	// Content of exactly the limit is read whole
+	content, err := io.ReadAll(&limitedReader{r: strings.NewReader("12345"), remaining: 5})
+	if err != nil || string(content) != "12345" {
+		t.Fatalf("content at the limit: got %q and error %v, want it whole", content, err)
+	}
+
+	// A single byte over the limit fails instead of being cut off
+	_, err = io.ReadAll(&limitedReader{r: strings.NewReader("123456"), remaining: 5})
+	if !errors.Is(err, errTooLarge) {
+		t.Fatalf("content over the limit: got %v, want errTooLarge", err)
+	}
+}
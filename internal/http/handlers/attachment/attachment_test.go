@@ -0,0 +1,220 @@
+package attachment_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/attachment"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// pdf is the content of a small PDF file, detected as application/pdf
+const pdf = "%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n"
+
+// png is the signature of a PNG file, detected as image/png
+const png = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00"
+
+// fileStorage keeps the attachments of student 1 as files in a directory
+type fileStorage struct {
+	dir         string
+	attachments []types.Attachment
+}
+
+func (s *fileStorage) CreateAttachment(ctx context.Context, studentId int64, fileName string, contentType string, content io.Reader) (types.Attachment, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return types.Attachment{}, err
+	}
+
+	attachment := types.Attachment{
+		Id:          int64(len(s.attachments) + 1),
+		StudentId:   studentId,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Checksum:    "checksum-" + fileName,
+		CreatedAt:   time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC),
+		BlobKey:     filepath.Join(s.dir, fileName),
+	}
+	if err := os.WriteFile(attachment.BlobKey, data, 0o600); err != nil {
+		return types.Attachment{}, err
+	}
+
+	s.attachments = append(s.attachments, attachment)
+	return attachment, nil
+}
+
+func (s *fileStorage) GetStudentAttachments(ctx context.Context, studentId int64) ([]types.Attachment, error) {
+	return s.attachments, nil
+}
+
+func (s *fileStorage) OpenAttachment(ctx context.Context, studentId int64, id int64) (types.Attachment, *os.File, error) {
+	if studentId != 1 || id < 1 || id > int64(len(s.attachments)) {
+		return types.Attachment{}, nil, storage.AttachmentNotFound(studentId, id)
+	}
+
+	attachment := s.attachments[id-1]
+	file, err := os.Open(attachment.BlobKey)
+	return attachment, file, err
+}
+
+func (s *fileStorage) DeleteAttachment(ctx context.Context, studentId int64, id int64) error {
+	return storage.AttachmentNotFound(studentId, id)
+}
+
+// newServer starts the attachment routes, registered like main does, accepting PDF files of up to maxSize bytes
+func newServer(t *testing.T, maxSize int64) (*httptest.Server, *fileStorage) {
+	t.Helper()
+
+	files := &fileStorage{dir: t.TempDir()}
+	limits := tenant.Settings[attachment.Limits]{Default: attachment.Limits{MaxSize: maxSize, AllowedTypes: []string{"application/pdf"}}}
+
+	router := http.NewServeMux()
+	router.HandleFunc("POST /api/students/{id}/attachments", attachment.New(files, limits))
+	router.HandleFunc("GET /api/students/{id}/attachments/{attachmentId}", attachment.Download(files))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, files
+}
+
+// upload sends the content as the file of a multipart form and returns the response and its body
+func upload(t *testing.T, server *httptest.Server, fileName string, content string) (*http.Response, string) {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(part, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.Post(server.URL+"/api/students/1/attachments", form.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(data)
+}
+
+// download gets the first attachment with the given headers (name, value, ...) and returns the response and its body
+func download(t *testing.T, server *httptest.Server, headers ...string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/students/1/attachments/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(data)
+}
+
+func TestUpload(t *testing.T) {
+	server, files := newServer(t, int64(len(pdf)))
+
+	// The directories of the client's file name are dropped
+	res, body := upload(t, server, `..\..\report.pdf`, pdf)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("upload: got %d %s, want 201", res.StatusCode, body)
+	}
+	if got := files.attachments[0]; got.FileName != "report.pdf" || got.ContentType != "application/pdf" || got.Size != int64(len(pdf)) {
+		t.Fatalf("upload: stored %+v, want report.pdf as application/pdf", got)
+	}
+
+	// A byte over the limit is rejected rather than cut off
+	if res, body := upload(t, server, "large.pdf", pdf+"x"); res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("upload over the size limit: got %d %s, want 413", res.StatusCode, body)
+	}
+
+	// The type is sniffed from the content, the extension of the name does not matter
+	if res, body := upload(t, server, "photo.pdf", png); res.StatusCode != http.StatusUnsupportedMediaType || !strings.Contains(body, "image/png") {
+		t.Fatalf("upload of a PNG named .pdf: got %d %s, want 415 naming image/png", res.StatusCode, body)
+	}
+
+	if res, body := upload(t, server, "empty.pdf", ""); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("upload of an empty file: got %d %s, want 400", res.StatusCode, body)
+	}
+	if len(files.attachments) != 1 {
+		t.Fatalf("got %d attachments stored, want only the accepted one", len(files.attachments))
+	}
+}
+
+func TestDownload(t *testing.T) {
+	server, _ := newServer(t, 1<<20)
+	if res, body := upload(t, server, "report.pdf", pdf); res.StatusCode != http.StatusCreated {
+		t.Fatalf("upload: got %d %s, want 201", res.StatusCode, body)
+	}
+
+	res, body := download(t, server)
+	if res.StatusCode != http.StatusOK || body != pdf {
+		t.Fatalf("download: got %d %q, want 200 and the file", res.StatusCode, body)
+	}
+	if res.Header.Get("Content-Type") != "application/pdf" || res.Header.Get("X-Content-Type-Options") != "nosniff" ||
+		res.Header.Get("Content-Disposition") != `attachment; filename=report.pdf` {
+		t.Fatalf("download: got headers %v, want the stored type, nosniff and the file name", res.Header)
+	}
+	tag := res.Header.Get("ETag")
+	if tag != `"checksum-report.pdf"` {
+		t.Fatalf("download: got ETag %s, want the quoted checksum", tag)
+	}
+
+	// A range is answered with just its bytes
+	res, body = download(t, server, "Range", "bytes=0-3")
+	if res.StatusCode != http.StatusPartialContent || body != "%PDF" {
+		t.Fatalf("download of bytes 0-3: got %d %q, want 206 and %%PDF", res.StatusCode, body)
+	}
+	if want := "bytes 0-3/" + strconv.Itoa(len(pdf)); res.Header.Get("Content-Range") != want {
+		t.Fatalf("download of bytes 0-3: got Content-Range %q, want %q", res.Header.Get("Content-Range"), want)
+	}
+
+	// If-Range resumes only while the file is unchanged, otherwise the whole file is sent
+	if res, body := download(t, server, "Range", "bytes=4-", "If-Range", tag); res.StatusCode != http.StatusPartialContent || body != pdf[4:] {
+		t.Fatalf("download with a matching If-Range: got %d %q, want 206 and the rest of the file", res.StatusCode, body)
+	}
+	if res, body := download(t, server, "Range", "bytes=4-", "If-Range", `"stale"`); res.StatusCode != http.StatusOK || body != pdf {
+		t.Fatalf("download with a stale If-Range: got %d %q, want 200 and the whole file", res.StatusCode, body)
+	}
+
+	if res, _ := download(t, server, "Range", "bytes=5000-"); res.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("download past the end: got %d, want 416", res.StatusCode)
+	}
+	if res, _ := download(t, server, "If-None-Match", tag); res.StatusCode != http.StatusNotModified {
+		t.Fatalf("download with If-None-Match: got %d, want 304", res.StatusCode)
+	}
+}
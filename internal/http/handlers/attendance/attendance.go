@@ -0,0 +1,153 @@
+package attendance
+
+import (
+	"fmt"      // Package for formatted I/O
+	"log/slog" // Package for structured logging
+	"net/http" // Package for HTTP client and server
+	"time"     // Package for dates
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/request"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+)
+
+const (
+	// dateLayout is the format of the dates of attendance records and ranges
+	dateLayout = "2006-01-02"
+	// defaultRangeDays is the length of the date range used when a request gives no "from" date
+	defaultRangeDays = 30
+)
+
+// New returns an HTTP handler function for recording the attendance of a class
+// This function handles the HTTP request to submit the attendance sheet of one day
+// It validates the sheet and stores every record at once, resubmitting a sheet corrects it
+func New(storage storage.AttendanceStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Decode and validate the sheet
+		var sheet types.AttendanceSheet
+		if !request.DecodeJSON(w, r, &sheet) {
+			return
+		}
+		if !response.Validated(w, r, validation.AttendanceSheet(&sheet)) {
+			return
+		}
+
+		// Store the records, an unknown student fails the whole sheet with 404
+		records, err := storage.RecordAttendance(r.Context(), sheet.Date, sheet.Records)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Attendance Recorded Successfully!", slog.String("date", sheet.Date), slog.Int("records", len(records)))
+
+		response.WriteJSON(w, http.StatusCreated, map[string]any{"data": records})
+	}
+}
+
+// GetByStudent returns an HTTP handler function for listing the attendance records of a student
+// This function handles the HTTP request to get the records of a student between the "from" and "to" dates
+func GetByStudent(storage storage.AttendanceStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		from, to, err := dateRange(r)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		records, err := storage.GetStudentAttendance(r.Context(), studentId, from, to)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": records})
+	}
+}
+
+// StudentSummary returns an HTTP handler function for the attendance rate of a student
+// This function handles the HTTP request to count the records of a student between the "from" and "to" dates
+func StudentSummary(storage storage.AttendanceStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		from, to, err := dateRange(r)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		summaries, err := storage.SummarizeAttendance(r.Context(), studentId, from, to)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		// A single student always has exactly one summary
+		response.WriteJSON(w, http.StatusOK, summaries[0])
+	}
+}
+
+// Summary returns an HTTP handler function for the attendance rates of every student
+// This function handles the HTTP request to count the records of each student between the "from" and "to" dates
+func Summary(storage storage.AttendanceStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := dateRange(r)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		summaries, err := storage.SummarizeAttendance(r.Context(), 0, from, to)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": summaries})
+	}
+}
+
+// dateRange reads the "from" and "to" query parameters of the request
+// "to" defaults to today and "from" to defaultRangeDays before "to", both ends are included
+func dateRange(r *http.Request) (string, string, error) {
+	query := r.URL.Query()
+
+	to := time.Now().UTC()
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return "", "", fmt.Errorf("to must be a date formatted as YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -defaultRangeDays)
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return "", "", fmt.Errorf("from must be a date formatted as YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return "", "", fmt.Errorf("from must not be after to")
+	}
+
+	return from.Format(dateLayout), to.Format(dateLayout), nil
+}
@@ -0,0 +1,228 @@
+package attendance_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/handlertest"
+	"github.com/Priyang1310/Students-API-GO/internal/http/routes"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// register is a storage.AttendanceStorage of students 1 to 3, keeping the records in memory
+// Like the sqlite backend, a sheet naming an unknown student is refused as a whole
+type register struct {
+	records []types.Attendance
+	from    string // from is the start of the last range asked for
+	to      string // to is the end of the last range asked for
+}
+
+func (r *register) RecordAttendance(ctx context.Context, date string, records []types.Attendance) ([]types.Attendance, error) {
+	for _, record := range records {
+		if record.StudentId > 3 {
+			return nil, storage.StudentNotFound(record.StudentId)
+		}
+	}
+
+	stored := make([]types.Attendance, 0, len(records))
+	for _, record := range records {
+		record.Id = int64(len(r.records) + 1)
+		record.Date = date
+		r.records = append(r.records, record)
+		stored = append(stored, record)
+	}
+	return stored, nil
+}
+
+func (r *register) GetStudentAttendance(ctx context.Context, studentId int64, from string, to string) ([]types.Attendance, error) {
+	r.from, r.to = from, to
+	if studentId > 3 {
+		return nil, storage.StudentNotFound(studentId)
+	}
+	records := []types.Attendance{}
+	for _, record := range r.records {
+		if record.StudentId == studentId && record.Date >= from && record.Date <= to {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (r *register) SummarizeAttendance(ctx context.Context, studentId int64, from string, to string) ([]types.AttendanceSummary, error) {
+	r.from, r.to = from, to
+	if studentId > 3 {
+		return nil, storage.StudentNotFound(studentId)
+	}
+
+	summaries := []types.AttendanceSummary{}
+	for id := int64(1); id <= 3; id++ {
+		if studentId != 0 && id != studentId {
+			continue
+		}
+		summary := types.AttendanceSummary{StudentId: id, From: from, To: to}
+		for _, record := range r.records {
+			if record.StudentId == id && record.Date >= from && record.Date <= to && record.Status == types.AttendancePresent {
+				summary.Days++
+				summary.Present++
+			}
+		}
+		summary.Rate = storage.AttendanceRate(summary)
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// newRouter registers the attendance routes the way main does
+func newRouter(r *register) *http.ServeMux {
+	router := http.NewServeMux()
+	routes.Attendance(router, r)
+	return router
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		field  string // field is the invalid field of a validation problem
+	}{
+		{"valid", `{"date":"2025-09-01","records":[{"student_id":1,"status":"Present"},{"student_id":2,"status":"late"}]}`, http.StatusCreated, ""},
+		{"empty body", "", http.StatusBadRequest, ""},
+		{"malformed body", `{"date":"2025-09-01","records":[`, http.StatusBadRequest, ""},
+		{"missing date", `{"records":[{"student_id":1,"status":"present"}]}`, http.StatusBadRequest, "date"},
+		{"invalid date", `{"date":"01/09/2025","records":[{"student_id":1,"status":"present"}]}`, http.StatusBadRequest, "date"},
+		{"no records", `{"date":"2025-09-01","records":[]}`, http.StatusBadRequest, "records"},
+		{"student twice", `{"date":"2025-09-01","records":[{"student_id":1,"status":"present"},{"student_id":1,"status":"absent"}]}`, http.StatusBadRequest, "records"},
+		{"unknown status", `{"date":"2025-09-01","records":[{"student_id":1,"status":"asleep"}]}`, http.StatusBadRequest, "status"},
+		{"missing student", `{"date":"2025-09-01","records":[{"status":"present"}]}`, http.StatusBadRequest, "student_id"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &register{}
+			res := handlertest.Serve(newRouter(r), http.MethodPost, "/api/attendance", test.body)
+			if res.Code != test.status {
+				t.Fatalf("got %d %s, want %d", res.Code, res.Body, test.status)
+			}
+			if test.status == http.StatusCreated {
+				var stored struct {
+					Data []types.Attendance `json:"data"`
+				}
+				if err := json.Unmarshal(res.Body.Bytes(), &stored); err != nil {
+					t.Fatal(err)
+				}
+				if len(stored.Data) != 2 || stored.Data[0].Status != types.AttendancePresent || stored.Data[1].Date != "2025-09-01" {
+					t.Fatalf("got %+v, want both records of the day, statuses lowercased", stored.Data)
+				}
+				return
+			}
+
+			if len(r.records) != 0 {
+				t.Fatalf("got %d records stored, want none", len(r.records))
+			}
+			if test.field == "" {
+				return
+			}
+			problem := handlertest.Problem(t, res)
+			if problem.Type != response.TypeValidation || len(problem.Errors) != 1 || problem.Errors[0].Field != test.field {
+				t.Fatalf("got %+v, want a validation problem on %s", problem, test.field)
+			}
+		})
+	}
+}
+
+func TestNewUnknownStudentFailsSheet(t *testing.T) {
+	r := &register{}
+
+	// The third student is unknown, the two valid records before it are not kept either
+	res := handlertest.Serve(newRouter(r), http.MethodPost, "/api/attendance",
+		`{"date":"2025-09-01","records":[{"student_id":1,"status":"present"},{"student_id":2,"status":"absent"},{"student_id":9,"status":"present"}]}`)
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("got %d %s, want 404", res.Code, res.Body)
+	}
+
+	problem := handlertest.Problem(t, res)
+	if !strings.Contains(problem.Detail, "9") {
+		t.Fatalf("got %+v, want the unknown student named", problem)
+	}
+	if len(r.records) != 0 {
+		t.Fatalf("got %d records stored, want none", len(r.records))
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	today := time.Now().UTC()
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		from   string
+		to     string
+	}{
+		{"defaults", "", http.StatusOK, today.AddDate(0, 0, -30).Format("2006-01-02"), today.Format("2006-01-02")},
+		{"from only", "?from=2025-09-01", http.StatusOK, "2025-09-01", today.Format("2006-01-02")},
+		{"to only", "?to=2025-09-30", http.StatusOK, "2025-08-31", "2025-09-30"},
+		{"both", "?from=2025-09-01&to=2025-09-30", http.StatusOK, "2025-09-01", "2025-09-30"},
+		{"single day", "?from=2025-09-01&to=2025-09-01", http.StatusOK, "2025-09-01", "2025-09-01"},
+		{"invalid from", "?from=yesterday", http.StatusBadRequest, "", ""},
+		{"invalid to", "?to=2025-13-01", http.StatusBadRequest, "", ""},
+		{"from after to", "?from=2025-10-01&to=2025-09-01", http.StatusBadRequest, "", ""},
+	}
+
+	for _, test := range tests {
+		for _, path := range []string{"/api/students/1/attendance", "/api/students/1/attendance/summary", "/api/attendance/summary"} {
+			r := &register{}
+			res := handlertest.Serve(newRouter(r), http.MethodGet, path+test.query, "")
+			if res.Code != test.status {
+				t.Errorf("%s, GET %s: got %d %s, want %d", test.name, path, res.Code, res.Body, test.status)
+				continue
+			}
+			if r.from != test.from || r.to != test.to {
+				t.Errorf("%s, GET %s: got range %s to %s, want %s to %s", test.name, path, r.from, r.to, test.from, test.to)
+			}
+		}
+	}
+}
+
+func TestSummaries(t *testing.T) {
+	r := &register{}
+	router := newRouter(r)
+	handlertest.Serve(router, http.MethodPost, "/api/attendance", `{"date":"2025-09-01","records":[{"student_id":1,"status":"present"},{"student_id":2,"status":"absent"}]}`)
+
+	// A single student gets their summary alone, not wrapped in a list
+	res := handlertest.Serve(router, http.MethodGet, "/api/students/1/attendance/summary?from=2025-09-01&to=2025-09-30", "")
+	var summary types.AttendanceSummary
+	if err := json.Unmarshal(res.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if res.Code != http.StatusOK || summary.StudentId != 1 || summary.Present != 1 || summary.Rate != 1 {
+		t.Fatalf("student summary: got %d %s, want student 1 present once", res.Code, res.Body)
+	}
+
+	res = handlertest.Serve(router, http.MethodGet, "/api/attendance/summary?from=2025-09-01&to=2025-09-30", "")
+	var summaries struct {
+		Data []types.AttendanceSummary `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &summaries); err != nil {
+		t.Fatal(err)
+	}
+	if res.Code != http.StatusOK || len(summaries.Data) != 3 {
+		t.Fatalf("summary: got %d %s, want a data list of every student", res.Code, res.Body)
+	}
+
+	for _, path := range []string{"/api/students/9/attendance", "/api/students/9/attendance/summary"} {
+		if res := handlertest.Serve(router, http.MethodGet, path, ""); res.Code != http.StatusNotFound {
+			t.Errorf("GET %s: got %d %s, want 404", path, res.Code, res.Body)
+		}
+	}
+	if res := handlertest.Serve(router, http.MethodGet, "/api/students/0/attendance", ""); res.Code != http.StatusBadRequest {
+		t.Errorf("GET of student 0: got %d %s, want 400", res.Code, res.Body)
+	}
+}
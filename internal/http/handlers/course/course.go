@@ -0,0 +1,160 @@
+package course
+
+import (
+	"log/slog" // Package for structured logging
+	"net/http" // Package for HTTP client and server
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/request"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+)
+
+// enrollRequest is the body of an enrollment request
+type enrollRequest struct {
+	CourseId int64 `json:"course_id" validate:"required,gt=0"`
+}
+
+// New returns an HTTP handler function for creating a new course
+// This function handles the HTTP request to add a course to the catalog
+// It validates the course data, creates the course in the storage, and returns the created course's ID
+func New(storage storage.CourseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Declare a variable to hold the course data
+		var course types.Course
+
+		// Decode the JSON request body into the course variable
+		if !request.DecodeJSON(w, r, &course) {
+			return
+		}
+
+		// Normalize and validate the course, responding with the invalid fields if it fails
+		if !response.Validated(w, r, validation.Course(&course)) {
+			return
+		}
+
+		// Create the course in the storage, a code that is already in use is reported as 409
+		lastID, err := storage.CreateCourse(r.Context(), course.Code, course.Title, course.Credits, course.Capacity)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Course Created Successfully!", slog.Int64("id", lastID))
+
+		// Respond with the new ID and HTTP status 201 Created
+		response.WriteJSON(w, http.StatusCreated, map[string]int64{"id": lastID})
+	}
+}
+
+// GetById returns an HTTP handler function for getting a course by ID
+// This function handles the HTTP request to get a course by ID
+// It retrieves the course, including its number of enrollments, from the storage
+func GetById(storage storage.CourseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the course ID from the URL path
+		id, err := request.PathID(r, "id", "course")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		course, err := storage.GetCourseById(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, course)
+	}
+}
+
+// GetAll returns an HTTP handler function for listing the course catalog
+// This function handles the HTTP request to get every course ordered by code
+func GetAll(storage storage.CourseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		courses, err := storage.GetAllCourses(r.Context())
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": courses})
+	}
+}
+
+// Students returns an HTTP handler function for listing the students enrolled in a course
+// This function handles the HTTP request to get the class list of a course, ordered by name
+func Students(storage storage.CourseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the course ID from the URL path
+		id, err := request.PathID(r, "id", "course")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		students, err := storage.GetCourseStudents(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": students})
+	}
+}
+
+// Enroll returns an HTTP handler function for enrolling a student in a course
+// This function handles the HTTP request to enroll the student of the URL in the course of the body
+// A full course or a duplicate enrollment is reported as 409 Conflict
+func Enroll(storage storage.CourseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Decode and validate the course to enroll in
+		var req enrollRequest
+		if !request.DecodeJSON(w, r, &req) {
+			return
+		}
+		if !response.Validated(w, r, validation.Validator().Struct(req)) {
+			return
+		}
+
+		enrollment, err := storage.EnrollStudent(r.Context(), studentId, req.CourseId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Student Enrolled Successfully!", slog.Int64("student_id", studentId), slog.Int64("course_id", req.CourseId))
+
+		response.WriteJSON(w, http.StatusCreated, enrollment)
+	}
+}
+
+// Enrollments returns an HTTP handler function for listing the enrollments of a student
+// This function handles the HTTP request to get the courses a student is enrolled in, oldest enrollment first
+func Enrollments(storage storage.CourseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		enrollments, err := storage.GetStudentEnrollments(r.Context(), studentId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": enrollments})
+	}
+}
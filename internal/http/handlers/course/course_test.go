@@ -0,0 +1,235 @@
+package course_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/handlertest"
+	"github.com/Priyang1310/Students-API-GO/internal/http/routes"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// catalog is a storage.CourseStorage of students 1 to 3, keeping the courses and enrollments in memory
+type catalog struct {
+	courses     []types.Course
+	enrollments []types.Enrollment
+}
+
+func (c *catalog) CreateCourse(ctx context.Context, code string, title string, credits int, capacity int) (int64, error) {
+	for _, existing := range c.courses {
+		if existing.Code == code {
+			return 0, storage.CourseCodeTaken(code)
+		}
+	}
+	id := int64(len(c.courses) + 1)
+	c.courses = append(c.courses, types.Course{Id: id, Code: code, Title: title, Credits: credits, Capacity: capacity})
+	return id, nil
+}
+
+func (c *catalog) GetCourseById(ctx context.Context, id int64) (types.Course, error) {
+	if id < 1 || id > int64(len(c.courses)) {
+		return types.Course{}, storage.CourseNotFound(id)
+	}
+	return c.courses[id-1], nil
+}
+
+func (c *catalog) GetAllCourses(ctx context.Context) ([]types.Course, error) {
+	return c.courses, nil
+}
+
+func (c *catalog) EnrollStudent(ctx context.Context, studentId int64, courseId int64) (types.Enrollment, error) {
+	if studentId > 3 {
+		return types.Enrollment{}, storage.StudentNotFound(studentId)
+	}
+	course, err := c.GetCourseById(ctx, courseId)
+	if err != nil {
+		return types.Enrollment{}, err
+	}
+	for _, enrollment := range c.enrollments {
+		if enrollment.StudentId == studentId && enrollment.CourseId == courseId {
+			return types.Enrollment{}, storage.AlreadyEnrolled(studentId, courseId)
+		}
+	}
+	if course.Enrolled >= course.Capacity {
+		return types.Enrollment{}, storage.CourseFull(courseId, course.Capacity)
+	}
+
+	c.courses[courseId-1].Enrolled++
+	enrollment := types.Enrollment{Id: int64(len(c.enrollments) + 1), StudentId: studentId, CourseId: courseId, EnrolledAt: time.Now().UTC()}
+	c.enrollments = append(c.enrollments, enrollment)
+	return enrollment, nil
+}
+
+func (c *catalog) GetStudentEnrollments(ctx context.Context, studentId int64) ([]types.Enrollment, error) {
+	if studentId > 3 {
+		return nil, storage.StudentNotFound(studentId)
+	}
+	enrollments := []types.Enrollment{}
+	for _, enrollment := range c.enrollments {
+		if enrollment.StudentId == studentId {
+			enrollments = append(enrollments, enrollment)
+		}
+	}
+	return enrollments, nil
+}
+
+func (c *catalog) GetCourseStudents(ctx context.Context, courseId int64) ([]types.Student, error) {
+	if _, err := c.GetCourseById(ctx, courseId); err != nil {
+		return nil, err
+	}
+	students := []types.Student{}
+	for _, enrollment := range c.enrollments {
+		if enrollment.CourseId == courseId {
+			students = append(students, types.Student{Id: enrollment.StudentId})
+		}
+	}
+	return students, nil
+}
+
+// newRouter registers the course routes the way main does
+func newRouter(c *catalog) *http.ServeMux {
+	router := http.NewServeMux()
+	routes.Courses(router, c)
+	return router
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		field  string // field is the invalid field of a validation problem
+	}{
+		{"valid", `{"code":"cs101","title":"Computing","credits":3,"capacity":30}`, http.StatusCreated, ""},
+		{"empty body", "", http.StatusBadRequest, ""},
+		{"malformed body", `{"code":`, http.StatusBadRequest, ""},
+		{"wrong type", `{"code":"CS101","title":"Computing","credits":"three","capacity":30}`, http.StatusBadRequest, ""},
+		{"missing title", `{"code":"CS101","capacity":30}`, http.StatusBadRequest, "title"},
+		{"code too short", `{"code":"C","title":"Computing","capacity":30}`, http.StatusBadRequest, "code"},
+		{"no capacity", `{"code":"CS101","title":"Computing"}`, http.StatusBadRequest, "capacity"},
+		{"too many credits", `{"code":"CS101","title":"Computing","credits":31,"capacity":30}`, http.StatusBadRequest, "credits"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := handlertest.Serve(newRouter(&catalog{}), http.MethodPost, "/api/courses", test.body)
+			if res.Code != test.status {
+				t.Fatalf("got %d %s, want %d", res.Code, res.Body, test.status)
+			}
+			if test.status == http.StatusCreated {
+				if body := strings.TrimSpace(res.Body.String()); body != `{"id":1}` {
+					t.Fatalf("got %s, want the id of the course", body)
+				}
+				return
+			}
+
+			p := handlertest.Problem(t, res)
+			if test.field == "" {
+				if p.Type != response.TypeBlank || p.Detail == "" {
+					t.Fatalf("got %+v, want a problem explaining the decoding error", p)
+				}
+				return
+			}
+			if p.Type != response.TypeValidation || len(p.Errors) != 1 || p.Errors[0].Field != test.field {
+				t.Fatalf("got %+v, want a validation problem on %s", p, test.field)
+			}
+		})
+	}
+}
+
+func TestNewDuplicateCode(t *testing.T) {
+	c := &catalog{}
+	router := newRouter(c)
+
+	handlertest.Serve(router, http.MethodPost, "/api/courses", `{"code":"CS101","title":"Computing","capacity":30}`)
+
+	// Codes are normalized before they are compared
+	res := handlertest.Serve(router, http.MethodPost, "/api/courses", `{"code":" cs101 ","title":"Computing again","capacity":30}`)
+	if res.Code != http.StatusConflict {
+		t.Fatalf("got %d %s, want 409", res.Code, res.Body)
+	}
+	if len(c.courses) != 1 || c.courses[0].Code != "CS101" {
+		t.Fatalf("got courses %+v, want CS101 alone", c.courses)
+	}
+}
+
+func TestEnroll(t *testing.T) {
+	c := &catalog{courses: []types.Course{{Id: 1, Code: "CS101", Title: "Computing", Capacity: 1}}}
+	router := newRouter(c)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"invalid student id", "/api/students/x/enrollments", `{"course_id":1}`, http.StatusBadRequest},
+		{"empty body", "/api/students/1/enrollments", "", http.StatusBadRequest},
+		{"missing course", "/api/students/1/enrollments", `{}`, http.StatusBadRequest},
+		{"negative course", "/api/students/1/enrollments", `{"course_id":-1}`, http.StatusBadRequest},
+		{"unknown course", "/api/students/1/enrollments", `{"course_id":9}`, http.StatusNotFound},
+		{"unknown student", "/api/students/9/enrollments", `{"course_id":1}`, http.StatusNotFound},
+		{"enrolled", "/api/students/1/enrollments", `{"course_id":1}`, http.StatusCreated},
+		{"enrolled twice", "/api/students/1/enrollments", `{"course_id":1}`, http.StatusConflict},
+		{"course full", "/api/students/2/enrollments", `{"course_id":1}`, http.StatusConflict},
+	}
+
+	for _, test := range tests {
+		res := handlertest.Serve(router, http.MethodPost, test.path, test.body)
+		if res.Code != test.status {
+			t.Errorf("%s: got %d %s, want %d", test.name, res.Code, res.Body, test.status)
+			continue
+		}
+		if test.status >= http.StatusBadRequest {
+			if p := handlertest.Problem(t, res); p.Status != test.status {
+				t.Errorf("%s: got problem %+v, want status %d", test.name, p, test.status)
+			}
+		}
+	}
+
+	res := handlertest.Serve(router, http.MethodGet, "/api/courses/1/students", "")
+	var students struct {
+		Data []types.Student `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &students); err != nil {
+		t.Fatal(err)
+	}
+	if res.Code != http.StatusOK || len(students.Data) != 1 || students.Data[0].Id != 1 {
+		t.Fatalf("class list: got %d %s, want student 1 alone", res.Code, res.Body)
+	}
+}
+
+func TestGet(t *testing.T) {
+	router := newRouter(&catalog{courses: []types.Course{{Id: 1, Code: "CS101", Title: "Computing", Capacity: 30}}})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/api/courses", http.StatusOK, `{"data":[{"id":1,"code":"CS101","title":"Computing","credits":0,"capacity":30,"enrolled":0}]}`},
+		{"/api/courses/1", http.StatusOK, `{"id":1,"code":"CS101","title":"Computing","credits":0,"capacity":30,"enrolled":0}`},
+		{"/api/courses/2", http.StatusNotFound, ""},
+		{"/api/courses/0", http.StatusBadRequest, ""},
+		{"/api/courses/2/students", http.StatusNotFound, ""},
+		{"/api/students/2/enrollments", http.StatusOK, `{"data":[]}`},
+		{"/api/students/9/enrollments", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		res := handlertest.Serve(router, http.MethodGet, test.path, "")
+		if res.Code != test.status {
+			t.Errorf("GET %s: got %d %s, want %d", test.path, res.Code, res.Body, test.status)
+			continue
+		}
+		if test.body != "" && strings.TrimSpace(res.Body.String()) != test.body {
+			t.Errorf("GET %s: got %s, want %s", test.path, res.Body, test.body)
+		}
+	}
+}
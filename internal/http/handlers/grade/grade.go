@@ -0,0 +1,97 @@
+package grade
+
+import (
+	"log/slog" // Package for structured logging
+	"net/http" // Package for HTTP client and server
+
+	"github.com/Priyang1310/Students-API-GO/internal/grading"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/request"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+)
+
+// New returns an HTTP handler function for recording a grade
+// This function handles the HTTP request to record the score of the student of the URL in a course for a term
+// It validates the grade, stores it and returns it with the letter given by the grading scale of the tenant
+func New(storage storage.GradeStorage, scales tenant.Settings[grading.Scale]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Decode and validate the course, term, start of the term and score
+		var grade types.Grade
+		if !request.DecodeJSON(w, r, &grade) {
+			return
+		}
+		if !response.Validated(w, r, validation.Grade(&grade)) {
+			return
+		}
+
+		// Store the grade, a student who is not enrolled in the course or already graded for the term is reported as 409
+		recorded, err := storage.RecordGrade(r.Context(), studentId, grade.CourseId, grade.Term, grade.TermStart, *grade.Score)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Grade Recorded Successfully!", slog.Int64("student_id", studentId), slog.Int64("id", recorded.Id))
+
+		scales.For(r.Context()).Apply(&recorded)
+		response.WriteJSON(w, http.StatusCreated, recorded)
+	}
+}
+
+// GetAll returns an HTTP handler function for listing the grades of a student
+// This function handles the HTTP request to get every grade of a student with its course and letter
+func GetAll(storage storage.GradeStorage, scales tenant.Settings[grading.Scale]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		grades, err := storage.GetStudentGrades(r.Context(), studentId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		scale := scales.For(r.Context())
+		for i := range grades {
+			scale.Apply(&grades[i].Grade)
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": grades})
+	}
+}
+
+// Transcript returns an HTTP handler function for getting the transcript of a student
+// This function handles the HTTP request to get the grades of a student grouped by term,
+// with the credit weighted GPA of each term and the cumulative GPA
+func Transcript(storage storage.GradeStorage, scales tenant.Settings[grading.Scale]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		grades, err := storage.GetStudentGrades(r.Context(), studentId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, scales.For(r.Context()).Transcript(studentId, grades))
+	}
+}
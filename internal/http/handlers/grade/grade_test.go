@@ -0,0 +1,84 @@
+package grade_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/grading"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/grade"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// recorder is a storage.GradeStorage keeping the last recorded score
+type recorder struct {
+	score *float64
+}
+
+func (r *recorder) RecordGrade(ctx context.Context, studentId int64, courseId int64, term string, termStart string, score float64) (types.Grade, error) {
+	r.score = &score
+	return types.Grade{Id: 1, StudentId: studentId, CourseId: courseId, Term: term, TermStart: termStart, Score: &score}, nil
+}
+
+func (r *recorder) GetStudentGrades(ctx context.Context, studentId int64) ([]types.CourseGrade, error) {
+	return nil, nil
+}
+
+func TestNewRequiresScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		field  string
+		tag    string
+	}{
+		{"missing score", `{"course_id":1,"term":"Fall 2025","term_start":"2025-09-01"}`, http.StatusBadRequest, "score", "required"},
+		{"score out of range", `{"course_id":1,"term":"Fall 2025","term_start":"2025-09-01","score":101}`, http.StatusBadRequest, "score", "lte"},
+		{"score of 0", `{"course_id":1,"term":"Fall 2025","term_start":"2025-09-01","score":0}`, http.StatusCreated, "", ""},
+		{"missing term start", `{"course_id":1,"term":"Fall 2025","score":90}`, http.StatusBadRequest, "term_start", "required"},
+		{"invalid term start", `{"course_id":1,"term":"Fall 2025","term_start":"Sept 2025","score":90}`, http.StatusBadRequest, "term_start", "datetime"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storage := &recorder{}
+			router := http.NewServeMux()
+			router.HandleFunc("POST /api/students/{id}/grades", grade.New(storage, tenant.Settings[grading.Scale]{Default: grading.DefaultScale}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/students/7/grades", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			if res.Code != test.status {
+				t.Fatalf("got %d %s, want %d", res.Code, res.Body, test.status)
+			}
+			if test.status == http.StatusCreated {
+				var recorded types.Grade
+				if err := json.Unmarshal(res.Body.Bytes(), &recorded); err != nil {
+					t.Fatal(err)
+				}
+				if storage.score == nil || *storage.score != 0 || recorded.Letter != "F" {
+					t.Fatalf("got %+v, want a score of 0 graded F", recorded)
+				}
+				return
+			}
+
+			var problem response.Problem
+			if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range problem.Errors {
+				if e.Field == test.field && e.Tag == test.tag {
+					return
+				}
+			}
+			t.Fatalf("got %+v, want a %s error on %s", problem.Errors, test.tag, test.field)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package guardian
+
+import (
+	"log/slog" // Package for structured logging
+	"net/http" // Package for HTTP client and server
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/request"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+)
+
+// New returns an HTTP handler function for adding a new guardian to a student
+// This function handles the HTTP request to create a guardian and link them to the student of the URL
+// It validates the guardian, stores it and returns it with its ID
+func New(storage storage.GuardianStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Decode, normalize and validate the guardian
+		var guardian types.Guardian
+		if !request.DecodeJSON(w, r, &guardian) {
+			return
+		}
+		if !response.Validated(w, r, validation.Guardian(&guardian)) {
+			return
+		}
+
+		created, err := storage.CreateGuardian(r.Context(), studentId, guardian)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Guardian Created Successfully!", slog.Int64("student_id", studentId), slog.Int64("id", created.Id))
+
+		response.WriteJSON(w, http.StatusCreated, created)
+	}
+}
+
+// Link returns an HTTP handler function for linking an existing guardian to a student
+// This function handles the HTTP request to share a guardian between students, e.g. the parent of siblings
+// The body holds the relationship and priority of the guardian for this student
+func Link(storage storage.GuardianStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student and guardian IDs from the URL path
+		studentId, guardianId, ok := ids(w, r)
+		if !ok {
+			return
+		}
+
+		// Decode, normalize and validate the link
+		var link types.GuardianLink
+		if !request.DecodeJSON(w, r, &link) {
+			return
+		}
+		if !response.Validated(w, r, validation.GuardianLink(&link)) {
+			return
+		}
+
+		guardian, err := storage.LinkGuardian(r.Context(), studentId, guardianId, link)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Guardian Linked Successfully!", slog.Int64("student_id", studentId), slog.Int64("id", guardianId))
+
+		response.WriteJSON(w, http.StatusCreated, guardian)
+	}
+}
+
+// GetAll returns an HTTP handler function for listing the guardians of a student
+// This function handles the HTTP request to get the guardians of a student, the ones to contact first come first
+func GetAll(storage storage.GuardianStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		guardians, err := storage.GetStudentGuardians(r.Context(), studentId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": guardians})
+	}
+}
+
+// GetById returns an HTTP handler function for getting one guardian of a student
+func GetById(storage storage.GuardianStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student and guardian IDs from the URL path
+		studentId, guardianId, ok := ids(w, r)
+		if !ok {
+			return
+		}
+
+		guardian, err := storage.GetStudentGuardian(r.Context(), studentId, guardianId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, guardian)
+	}
+}
+
+// Update returns an HTTP handler function for updating a guardian of a student
+// This function handles the HTTP request to replace a guardian's details
+// The contact details change for every student of the guardian, the relationship and priority only for this one
+func Update(storage storage.GuardianStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student and guardian IDs from the URL path
+		studentId, guardianId, ok := ids(w, r)
+		if !ok {
+			return
+		}
+
+		// Decode, normalize and validate the guardian, the ID always comes from the URL
+		var guardian types.Guardian
+		if !request.DecodeJSON(w, r, &guardian) {
+			return
+		}
+		guardian.Id = guardianId
+		if !response.Validated(w, r, validation.Guardian(&guardian)) {
+			return
+		}
+
+		updated, err := storage.UpdateStudentGuardian(r.Context(), studentId, guardian)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Guardian Updated Successfully!", slog.Int64("student_id", studentId), slog.Int64("id", guardianId))
+
+		response.WriteJSON(w, http.StatusOK, updated)
+	}
+}
+
+// Delete returns an HTTP handler function for removing a guardian from a student
+// The guardian is deleted once no student is linked to them anymore
+func Delete(storage storage.GuardianStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student and guardian IDs from the URL path
+		studentId, guardianId, ok := ids(w, r)
+		if !ok {
+			return
+		}
+
+		if err := storage.UnlinkGuardian(r.Context(), studentId, guardianId); err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		slog.Info("Guardian Removed Successfully!", slog.Int64("student_id", studentId), slog.Int64("id", guardianId))
+
+		response.WriteJSON(w, http.StatusOK, "guardian removed successfully")
+	}
+}
+
+// Students returns an HTTP handler function for listing the students of a guardian
+// This function handles the HTTP request to look up every student a guardian is linked to
+func Students(storage storage.GuardianStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the guardian ID from the URL path
+		guardianId, err := request.PathID(r, "id", "guardian")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		students, err := storage.GetGuardianStudents(r.Context(), guardianId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": students})
+	}
+}
+
+// ids parses the student and guardian IDs of a /api/students/{id}/guardians/{guardianId} path
+// It writes a 400 problem and returns false if either is malformed
+func ids(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	studentId, err := request.PathID(r, "id", "student")
+	if err != nil {
+		response.WriteError(w, r, http.StatusBadRequest, err)
+		return 0, 0, false
+	}
+
+	guardianId, err := request.PathID(r, "guardianId", "guardian")
+	if err != nil {
+		response.WriteError(w, r, http.StatusBadRequest, err)
+		return 0, 0, false
+	}
+
+	return studentId, guardianId, true
+}
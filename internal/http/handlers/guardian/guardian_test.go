@@ -0,0 +1,252 @@
+package guardian_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/handlertest"
+	"github.com/Priyang1310/Students-API-GO/internal/http/routes"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// guardianStore is a storage.GuardianStorage of students 1 and 2, keeping the guardians and their links in memory
+type guardianStore struct {
+	guardians map[int64]types.Guardian           // guardians holds the contact details, keyed by ID
+	links     map[int64]map[int64]types.Guardian // links holds the guardians of every student, with the details of the link
+}
+
+func newGuardianStore() *guardianStore {
+	return &guardianStore{
+		guardians: map[int64]types.Guardian{},
+		links:     map[int64]map[int64]types.Guardian{1: {}, 2: {}},
+	}
+}
+
+func (s *guardianStore) CreateGuardian(ctx context.Context, studentId int64, guardian types.Guardian) (types.Guardian, error) {
+	if _, ok := s.links[studentId]; !ok {
+		return types.Guardian{}, storage.StudentNotFound(studentId)
+	}
+	guardian.Id = int64(len(s.guardians) + 1)
+	s.guardians[guardian.Id] = guardian
+	s.links[studentId][guardian.Id] = guardian
+	return guardian, nil
+}
+
+func (s *guardianStore) LinkGuardian(ctx context.Context, studentId int64, guardianId int64, link types.GuardianLink) (types.Guardian, error) {
+	links, ok := s.links[studentId]
+	if !ok {
+		return types.Guardian{}, storage.StudentNotFound(studentId)
+	}
+	guardian, ok := s.guardians[guardianId]
+	if !ok {
+		return types.Guardian{}, storage.GuardianNotFound(guardianId)
+	}
+	if _, ok := links[guardianId]; ok {
+		return types.Guardian{}, storage.GuardianAlreadyLinked(studentId, guardianId)
+	}
+	guardian.Relationship, guardian.Priority = link.Relationship, link.Priority
+	links[guardianId] = guardian
+	return guardian, nil
+}
+
+func (s *guardianStore) GetStudentGuardians(ctx context.Context, studentId int64) ([]types.Guardian, error) {
+	links, ok := s.links[studentId]
+	if !ok {
+		return nil, storage.StudentNotFound(studentId)
+	}
+	guardians := []types.Guardian{}
+	for _, guardian := range links {
+		guardians = append(guardians, guardian)
+	}
+	return guardians, nil
+}
+
+func (s *guardianStore) GetStudentGuardian(ctx context.Context, studentId int64, guardianId int64) (types.Guardian, error) {
+	guardian, ok := s.links[studentId][guardianId]
+	if !ok {
+		return types.Guardian{}, storage.GuardianNotLinked(studentId, guardianId)
+	}
+	return guardian, nil
+}
+
+func (s *guardianStore) UpdateStudentGuardian(ctx context.Context, studentId int64, guardian types.Guardian) (types.Guardian, error) {
+	if _, ok := s.links[studentId][guardian.Id]; !ok {
+		return types.Guardian{}, storage.GuardianNotLinked(studentId, guardian.Id)
+	}
+	s.guardians[guardian.Id] = guardian
+	s.links[studentId][guardian.Id] = guardian
+	return guardian, nil
+}
+
+func (s *guardianStore) UnlinkGuardian(ctx context.Context, studentId int64, guardianId int64) error {
+	if _, ok := s.links[studentId][guardianId]; !ok {
+		return storage.GuardianNotLinked(studentId, guardianId)
+	}
+	delete(s.links[studentId], guardianId)
+	return nil
+}
+
+func (s *guardianStore) GetGuardianStudents(ctx context.Context, guardianId int64) ([]types.Student, error) {
+	if _, ok := s.guardians[guardianId]; !ok {
+		return nil, storage.GuardianNotFound(guardianId)
+	}
+	students := []types.Student{}
+	for _, id := range []int64{1, 2} {
+		if _, ok := s.links[id][guardianId]; ok {
+			students = append(students, types.Student{Id: id})
+		}
+	}
+	return students, nil
+}
+
+// newRouter registers the guardian routes the way main does
+func newRouter(s *guardianStore) *http.ServeMux {
+	router := http.NewServeMux()
+	routes.Guardians(router, s)
+	return router
+}
+
+// ann is a valid guardian, her phone number is formatted the way people write it
+const ann = `{"name":"Ann  Lee","relationship":" Mother ","phone":"0044 20 7946 0000","email":"Ann@Example.com"}`
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		field  string // field is the invalid field of a validation problem
+	}{
+		{"valid", "/api/students/1/guardians", ann, http.StatusCreated, ""},
+		{"empty body", "/api/students/1/guardians", "", http.StatusBadRequest, ""},
+		{"malformed body", "/api/students/1/guardians", `{"name":`, http.StatusBadRequest, ""},
+		{"invalid student id", "/api/students/abc/guardians", ann, http.StatusBadRequest, ""},
+		{"unknown student", "/api/students/9/guardians", ann, http.StatusNotFound, ""},
+		{"missing phone", "/api/students/1/guardians", `{"name":"Ann Lee","relationship":"mother"}`, http.StatusBadRequest, "phone"},
+		{"invalid phone", "/api/students/1/guardians", `{"name":"Ann Lee","relationship":"mother","phone":"call me"}`, http.StatusBadRequest, "phone"},
+		{"priority out of range", "/api/students/1/guardians", `{"name":"Ann Lee","relationship":"mother","phone":"+442079460000","priority":11}`, http.StatusBadRequest, "priority"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := handlertest.Serve(newRouter(newGuardianStore()), http.MethodPost, test.path, test.body)
+			if res.Code != test.status {
+				t.Fatalf("got %d %s, want %d", res.Code, res.Body, test.status)
+			}
+			if test.field == "" {
+				return
+			}
+
+			problem := handlertest.Problem(t, res)
+			if problem.Type != response.TypeValidation || len(problem.Errors) != 1 || problem.Errors[0].Field != test.field {
+				t.Fatalf("got %+v, want a validation problem on %s", problem, test.field)
+			}
+		})
+	}
+}
+
+func TestNewNormalizesGuardian(t *testing.T) {
+	res := handlertest.Serve(newRouter(newGuardianStore()), http.MethodPost, "/api/students/1/guardians", ann)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("got %d %s, want 201", res.Code, res.Body)
+	}
+
+	var created types.Guardian
+	if err := json.Unmarshal(res.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	want := types.Guardian{Id: 1, Name: "Ann Lee", Relationship: "mother", Phone: "+442079460000", Email: "ann@example.com", Priority: 1}
+	if created != want {
+		t.Fatalf("got %+v, want %+v", created, want)
+	}
+}
+
+func TestLink(t *testing.T) {
+	store := newGuardianStore()
+	router := newRouter(store)
+	if res := handlertest.Serve(router, http.MethodPost, "/api/students/1/guardians", ann); res.Code != http.StatusCreated {
+		t.Fatalf("creating the guardian: got %d %s", res.Code, res.Body)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"missing relationship", "/api/students/2/guardians/1", `{"priority":2}`, http.StatusBadRequest},
+		{"invalid guardian id", "/api/students/2/guardians/0", `{"relationship":"mother"}`, http.StatusBadRequest},
+		{"unknown guardian", "/api/students/2/guardians/9", `{"relationship":"mother"}`, http.StatusNotFound},
+		{"sibling", "/api/students/2/guardians/1", `{"relationship":"Mother","priority":2}`, http.StatusCreated},
+		{"linked twice", "/api/students/2/guardians/1", `{"relationship":"mother"}`, http.StatusConflict},
+	}
+
+	for _, test := range tests {
+		if res := handlertest.Serve(router, http.MethodPost, test.path, test.body); res.Code != test.status {
+			t.Errorf("%s: got %d %s, want %d", test.name, res.Code, res.Body, test.status)
+		}
+	}
+
+	// The link keeps its own relationship and priority
+	if linked := store.links[2][1]; linked.Relationship != "mother" || linked.Priority != 2 || linked.Phone != "+442079460000" {
+		t.Fatalf("got link %+v, want the mother of priority 2 with the guardian's phone", linked)
+	}
+
+	res := handlertest.Serve(router, http.MethodGet, "/api/guardians/1/students", "")
+	var students struct {
+		Data []types.Student `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &students); err != nil {
+		t.Fatal(err)
+	}
+	if res.Code != http.StatusOK || len(students.Data) != 2 {
+		t.Fatalf("students of the guardian: got %d %s, want both students", res.Code, res.Body)
+	}
+}
+
+func TestUpdateTakesIdFromPath(t *testing.T) {
+	store := newGuardianStore()
+	router := newRouter(store)
+	handlertest.Serve(router, http.MethodPost, "/api/students/1/guardians", ann)
+
+	res := handlertest.Serve(router, http.MethodPut, "/api/students/1/guardians/1", `{"id":7,"name":"Ann Smith","relationship":"mother","phone":"+442079460001"}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", res.Code, res.Body)
+	}
+	if updated := store.guardians[1]; updated.Name != "Ann Smith" || updated.Phone != "+442079460001" {
+		t.Fatalf("got %+v, want guardian 1 renamed Ann Smith", updated)
+	}
+	if _, ok := store.guardians[7]; ok {
+		t.Fatal("the id of the body was used, want the id of the path")
+	}
+
+	// Only a guardian of the student can be updated
+	if res := handlertest.Serve(router, http.MethodPut, "/api/students/2/guardians/1", ann); res.Code != http.StatusNotFound {
+		t.Fatalf("update through another student: got %d %s, want 404", res.Code, res.Body)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	router := newRouter(newGuardianStore())
+	handlertest.Serve(router, http.MethodPost, "/api/students/1/guardians", ann)
+
+	if res := handlertest.Serve(router, http.MethodDelete, "/api/students/2/guardians/1", ""); res.Code != http.StatusNotFound {
+		t.Fatalf("removing a guardian of another student: got %d %s, want 404", res.Code, res.Body)
+	}
+	if res := handlertest.Serve(router, http.MethodDelete, "/api/students/1/guardians/1", ""); res.Code != http.StatusOK {
+		t.Fatalf("removing the guardian: got %d %s, want 200", res.Code, res.Body)
+	}
+	if res := handlertest.Serve(router, http.MethodGet, "/api/students/1/guardians/1", ""); res.Code != http.StatusNotFound {
+		t.Fatalf("getting the removed guardian: got %d %s, want 404", res.Code, res.Body)
+	}
+
+	res := handlertest.Serve(router, http.MethodGet, "/api/students/1/guardians", "")
+	if res.Code != http.StatusOK || strings.TrimSpace(res.Body.String()) != `{"data":[]}` {
+		t.Fatalf("guardians after removal: got %d %s, want an empty data list", res.Code, res.Body)
+	}
+}
@@ -0,0 +1,38 @@
+// Package handlertest holds the helpers shared by the tests of the handlers
+// The routers under test are built with the routes package, the one main registers its routes with
+package handlertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// Serve sends a request with the given JSON body to the router and returns the recorded response
+func Serve(router http.Handler, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+// Problem decodes the problem of a response, failing the test if it is not one
+func Problem(t *testing.T, res *httptest.ResponseRecorder) response.Problem {
+	t.Helper()
+
+	if contentType := res.Header().Get("Content-Type"); contentType != response.ProblemContentType {
+		t.Fatalf("got Content-Type %q, want %s", contentType, response.ProblemContentType)
+	}
+	var problem response.Problem
+	if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
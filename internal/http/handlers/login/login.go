@@ -0,0 +1,76 @@
+package login
+
+import (
+	"errors"   // Package for error handling
+	"log/slog" // Package for structured logging
+	"net/http" // Package for HTTP client and server
+	"time"     // Package for the session expiry
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// Login returns an HTTP handler function for signing a staff member in with the identity provider
+// This function handles the HTTP request to start the sign-in, it redirects the browser to the identity provider
+// The optional return_to query parameter is the local path the browser is sent back to once signed in,
+// the optional login_hint is passed on to the identity provider
+func Login(sso *auth.OIDC) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		redirect, err := sso.StartLogin(w, query.Get("return_to"), query.Get("login_hint"))
+		if err != nil {
+			response.WriteError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, redirect, http.StatusFound)
+	}
+}
+
+// Callback returns an HTTP handler function for the identity provider to send the browser back to
+// This function handles the HTTP request to finish the sign-in and set the session cookie
+// It redirects to the return_to path of the sign-in, or responds with the signed-in identity when there is none
+func Callback(sso *auth.OIDC) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		identity, returnTo, err := sso.FinishLogin(w, r)
+		if err != nil {
+			slog.Warn("Sign-in failed", slog.String("error", err.Error()))
+
+			// Map the error to its status, a provider that cannot be asked is a bad gateway
+			switch {
+			case errors.Is(err, auth.ErrLoginState):
+				response.WriteError(w, r, http.StatusBadRequest, err)
+			case errors.Is(err, auth.ErrLoginRejected):
+				response.WriteError(w, r, http.StatusUnauthorized, err)
+			default:
+				response.WriteError(w, r, http.StatusBadGateway, err)
+			}
+			return
+		}
+
+		slog.Info("Signed In Successfully!", slog.String("subject", identity.Subject))
+
+		if returnTo != "" {
+			http.Redirect(w, r, returnTo, http.StatusFound)
+			return
+		}
+
+		expires, _ := identity.Claims["exp"].(int64)
+		response.WriteJSON(w, http.StatusOK, map[string]any{
+			"subject":    identity.Subject,
+			"roles":      identity.Roles,
+			"expires_at": time.Unix(expires, 0).UTC(),
+		})
+	}
+}
+
+// Logout returns an HTTP handler function for signing a staff member out
+// This function handles the HTTP request to clear the session cookie, the staff member stays signed in at the identity provider
+func Logout(sso *auth.OIDC) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sso.Logout(w)
+
+		response.WriteJSON(w, http.StatusOK, "signed out successfully")
+	}
+}
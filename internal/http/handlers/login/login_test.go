@@ -0,0 +1,214 @@
+package login_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/login"
+	"github.com/Priyang1310/Students-API-GO/internal/http/middleware"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// newApp starts the sign-in routes in front of two routes protected by the session cookie, like main does,
+// with the given users at a mock identity provider
+func newApp(t *testing.T, users ...mockUser) *httptest.Server {
+	t.Helper()
+
+	idp := newMockIdP(t, "students-api", "client-secret", users...)
+
+	// The redirect URL must be known before the server starts, so the handler is set afterwards
+	var handler http.Handler
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(app.Close)
+
+	sso, err := auth.NewOIDC(context.Background(), config.OIDC{
+		Enabled:       true,
+		Issuer:        idp.URL,
+		ClientID:      "students-api",
+		ClientSecret:  "client-secret",
+		RedirectURL:   app.URL + "/auth/callback",
+		Scopes:        []string{"openid", "profile"},
+		RolesClaim:    "roles",
+		TenantClaim:   "tenant",
+		SessionSecret: strings.Repeat("s", 32),
+		SessionTTL:    time.Hour,
+	}, config.Auth{HMACSecret: strings.Repeat("h", 32), Leeway: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := rbac.NewPolicy(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both routes answer with the subject of the signed-in staff member
+	router := http.NewServeMux()
+	subject := func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := auth.FromContext(r.Context())
+		response.WriteJSON(w, http.StatusOK, identity.Subject)
+	}
+	router.HandleFunc("GET /api/students", subject)
+	router.HandleFunc("DELETE /api/students", subject)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", middleware.Authenticate(sso)(middleware.Authorize(policy, router)(router)))
+	mux.HandleFunc("GET /auth/login", login.Login(sso))
+	mux.HandleFunc("GET /auth/callback", login.Callback(sso))
+	mux.HandleFunc("POST /auth/logout", login.Logout(sso))
+	handler = mux
+
+	return app
+}
+
+// newClient returns a client keeping the cookies of the servers, redirects are followed
+func newClient(t *testing.T) *http.Client {
+	t.Helper()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Jar: jar}
+}
+
+// do sends a request with the given headers (name, value, ...) and returns the status and the body of the response
+func do(t *testing.T, client *http.Client, method string, target string, headers ...string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, strings.TrimSpace(string(body))
+}
+
+func TestSignInAuthorizesLaterRequests(t *testing.T) {
+	app := newApp(t, mockUser{Subject: "bob", Name: "Bob Registrar", Roles: []string{"registrar"}})
+	client := newClient(t)
+
+	// Without a session the routes are closed
+	if status, _ := do(t, client, http.MethodGet, app.URL+"/api/students"); status != http.StatusUnauthorized {
+		t.Fatalf("GET /api/students before sign-in: got %d, want 401", status)
+	}
+
+	// Login, identity provider, callback and back to the return_to path
+	status, body := do(t, client, http.MethodGet, app.URL+"/auth/login?login_hint=bob&return_to="+url.QueryEscape("/api/students"))
+	if status != http.StatusOK || body != `"bob"` {
+		t.Fatalf("sign-in: got %d %s, want 200 \"bob\"", status, body)
+	}
+
+	appURL, _ := url.Parse(app.URL)
+	var session *http.Cookie
+	for _, cookie := range client.Jar.Cookies(appURL) {
+		if cookie.Name == auth.SessionCookie {
+			session = cookie
+		}
+	}
+	if session == nil {
+		t.Fatalf("sign-in set no %s cookie", auth.SessionCookie)
+	}
+
+	// The session carries the roles of the ID token: a registrar may not empty the students table
+	if status, body := do(t, client, http.MethodDelete, app.URL+"/api/students", "Origin", app.URL); status != http.StatusForbidden || strings.Contains(body, "cross-site") {
+		t.Fatalf("DELETE /api/students as registrar: got %d %s, want 403 from the policy", status, body)
+	}
+
+	// Signing out clears the session
+	if status, _ := do(t, client, http.MethodPost, app.URL+"/auth/logout"); status != http.StatusOK {
+		t.Fatalf("sign-out: got %d, want 200", status)
+	}
+	if status, _ := do(t, client, http.MethodGet, app.URL+"/api/students"); status != http.StatusUnauthorized {
+		t.Fatalf("GET /api/students after sign-out: got %d, want 401", status)
+	}
+}
+
+func TestSignInRejectedByProvider(t *testing.T) {
+	app := newApp(t, mockUser{Subject: "bob", Roles: []string{"registrar"}})
+	client := newClient(t)
+
+	if status, _ := do(t, client, http.MethodGet, app.URL+"/auth/login?login_hint=mallory"); status != http.StatusUnauthorized {
+		t.Fatalf("sign-in of an unknown user: got %d, want 401", status)
+	}
+}
+
+func TestCallbackWithoutLogin(t *testing.T) {
+	app := newApp(t)
+	client := newClient(t)
+
+	if status, _ := do(t, client, http.MethodGet, app.URL+"/auth/callback?code=stolen&state=guessed"); status != http.StatusBadRequest {
+		t.Fatalf("callback without a sign-in in progress: got %d, want 400", status)
+	}
+}
+
+func TestSessionRefusesCrossSiteChanges(t *testing.T) {
+	app := newApp(t, mockUser{Subject: "ann", Name: "Ann Admin", Roles: []string{"admin"}})
+	client := newClient(t)
+
+	if status, body := do(t, client, http.MethodGet, app.URL+"/auth/login?login_hint=ann&return_to="+url.QueryEscape("/api/students")); status != http.StatusOK || body != `"ann"` {
+		t.Fatalf("sign-in: got %d %s, want 200 \"ann\"", status, body)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		headers []string
+		status  int
+	}{
+		{"same origin", http.MethodDelete, []string{"Origin", app.URL}, http.StatusOK},
+		{"same origin referer", http.MethodDelete, []string{"Referer", app.URL + "/students.html"}, http.StatusOK},
+		{"other origin", http.MethodDelete, []string{"Origin", "https://evil.example"}, http.StatusForbidden},
+		{"other origin, same referer", http.MethodDelete, []string{"Origin", "https://evil.example", "Referer", app.URL + "/"}, http.StatusForbidden},
+		{"opaque origin", http.MethodDelete, []string{"Origin", "null"}, http.StatusForbidden},
+		{"no origin", http.MethodDelete, nil, http.StatusForbidden},
+		// Reading is harmless, the response is not readable by the other site
+		{"read from other origin", http.MethodGet, []string{"Origin", "https://evil.example"}, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		status, body := do(t, client, test.method, app.URL+"/api/students", test.headers...)
+		if status != test.status {
+			t.Errorf("%s: got %d %s, want %d", test.name, status, body, test.status)
+		}
+		if status == http.StatusOK && body != `"ann"` {
+			t.Errorf("%s: got %s, want the route reached as ann", test.name, body)
+		}
+	}
+}
+
+func TestForgedSessionRejected(t *testing.T) {
+	app := newApp(t)
+	client := newClient(t)
+
+	appURL, _ := url.Parse(app.URL)
+	client.Jar.SetCookies(appURL, []*http.Cookie{{Name: auth.SessionCookie, Value: "eyJhbGciOiJub25lIn0.eyJzdWIiOiJhZG1pbiJ9."}})
+
+	if status, _ := do(t, client, http.MethodGet, app.URL+"/api/students"); status != http.StatusUnauthorized {
+		t.Fatalf("GET /api/students with a forged session: got %d, want 401", status)
+	}
+}
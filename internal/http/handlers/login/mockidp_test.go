@@ -0,0 +1,206 @@
+package login_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockUser is an account of the mock identity provider
+type mockUser struct {
+	Subject string
+	Name    string
+	Roles   []string
+	Tenant  string // Tenant is sent in the tenant claim when set
+}
+
+// grant is an authorization code waiting to be exchanged
+type grant struct {
+	user        mockUser
+	redirectURI string
+	nonce       string
+	challenge   string
+}
+
+// mockIdP is a minimal OpenID Connect identity provider, it signs the user of the login_hint in without a password
+// It only exists in the tests, so the sign-in flow can be exercised without network access
+type mockIdP struct {
+	URL string
+
+	clientID     string
+	clientSecret string
+	users        []mockUser
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+// newMockIdP starts a mock identity provider accepting the given client, it is stopped when the test ends
+func newMockIdP(t *testing.T, clientID string, clientSecret string, users ...mockUser) *mockIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mockIdP{clientID: clientID, clientSecret: clientSecret, users: users, key: key, codes: make(map[string]grant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("GET /jwks", p.jwks)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	p.URL = server.URL
+
+	return p
+}
+
+// discovery serves the discovery document of the provider
+func (p *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                 p.URL,
+		"authorization_endpoint": p.URL + "/authorize",
+		"token_endpoint":         p.URL + "/token",
+		"jwks_uri":               p.URL + "/jwks",
+	})
+}
+
+// authorize signs the hinted user in and redirects back to the client with an authorization code
+func (p *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	back, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("client_id") != p.clientID {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+	params := url.Values{"state": {query.Get("state")}}
+
+	user, ok := p.user(query.Get("login_hint"))
+	if ok && query.Get("code_challenge_method") == "S256" {
+		code := randomString()
+		p.mu.Lock()
+		p.codes[code] = grant{user: user, redirectURI: back.String(), nonce: query.Get("nonce"), challenge: query.Get("code_challenge")}
+		p.mu.Unlock()
+		params.Set("code", code)
+	} else {
+		params.Set("error", "access_denied")
+	}
+
+	back.RawQuery = params.Encode()
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+// user returns the user with the subject of the hint
+func (p *mockIdP) user(hint string) (mockUser, bool) {
+	for _, user := range p.users {
+		if user.Subject == hint {
+			return user, true
+		}
+	}
+	return mockUser{}, false
+}
+
+// token exchanges an authorization code for an ID token, checking the client and the PKCE verifier
+func (p *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != p.clientID || clientSecret != p.clientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.URL,
+		"sub":   g.user.Subject,
+		"aud":   clientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": g.nonce,
+		"name":  g.user.Name,
+		"roles": g.user.Roles,
+	}
+	if g.user.Tenant != "" {
+		claims["tenant"] = g.user.Tenant
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = "mockidp"
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+// jwks serves the public key of the ID tokens as a JSON Web Key Set
+func (p *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	public := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "mockidp",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}},
+	})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// randomString returns 16 random bytes, hex encoded
+func randomString() string {
+	random := make([]byte, 16)
+	rand.Read(random)
+	return fmt.Sprintf("%x", random)
+}
@@ -0,0 +1,103 @@
+package reportcard
+
+import (
+	"bytes"    // Package for in-memory buffers
+	"fmt"      // Package for formatted I/O
+	"log/slog" // Package for structured logging
+	"net/http" // Package for HTTP client and server
+	"time"     // Package for dates
+
+	"github.com/Priyang1310/Students-API-GO/internal/grading"
+	"github.com/Priyang1310/Students-API-GO/internal/report"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/request"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// Storage is the part of the storage a report card is built from
+type Storage interface {
+	storage.Storage
+	storage.CourseStorage
+	storage.GradeStorage
+}
+
+// Get returns an HTTP handler function for downloading the report card of a student
+// This function handles the HTTP request to render the student's details, enrollments and grades as a PDF
+// The layout comes from the report card template of the tenant, letters and GPAs from its grading scale
+func Get(storage Storage, scales tenant.Settings[grading.Scale], templates tenant.Settings[*report.Template]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the student ID from the URL path
+		studentId, err := request.PathID(r, "id", "student")
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		slog.Info("Rendering a report card", slog.Int64("id", studentId))
+
+		data, err := load(r, storage, scales.For(r.Context()), studentId)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		// Render into memory, so a rendering failure can still be reported as a problem
+		var buf bytes.Buffer
+		if err := templates.For(r.Context()).Render(&buf, data); err != nil {
+			slog.Error("Report card rendering failed", slog.String("error", err.Error()))
+			response.WriteError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="report-card-%d.pdf"`, studentId))
+		w.WriteHeader(http.StatusOK)
+		buf.WriteTo(w)
+	}
+}
+
+// load gathers everything printed on the report card of a student
+func load(r *http.Request, storage Storage, scale grading.Scale, studentId int64) (report.Data, error) {
+	ctx := r.Context()
+
+	student, err := storage.GetStudentById(ctx, studentId)
+	if err != nil {
+		return report.Data{}, err
+	}
+
+	enrollments, err := storage.GetStudentEnrollments(ctx, studentId)
+	if err != nil {
+		return report.Data{}, err
+	}
+
+	// The catalog is small, one query beats looking every course up on its own
+	courses, err := storage.GetAllCourses(ctx)
+	if err != nil {
+		return report.Data{}, err
+	}
+	byId := make(map[int64]types.Course, len(courses))
+	for _, course := range courses {
+		byId[course.Id] = course
+	}
+
+	grades, err := storage.GetStudentGrades(ctx, studentId)
+	if err != nil {
+		return report.Data{}, err
+	}
+
+	data := report.Data{
+		Student:     student,
+		Transcript:  scale.Transcript(studentId, grades),
+		GeneratedAt: time.Now().UTC().Format("2006-01-02"),
+	}
+	for _, enrollment := range enrollments {
+		data.Enrollments = append(data.Enrollments, report.EnrolledCourse{
+			Course:     byId[enrollment.CourseId],
+			EnrolledAt: enrollment.EnrolledAt,
+		})
+	}
+
+	return data, nil
+}
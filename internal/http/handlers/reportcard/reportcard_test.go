@@ -0,0 +1,287 @@
+package reportcard_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/grading"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/reportcard"
+	"github.com/Priyang1310/Students-API-GO/internal/report"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// school is a reportcard.Storage holding student 1, enrolled in two courses and graded in both
+// The methods a report card does not use are left to the embedded nil interfaces
+type school struct {
+	storage.Storage
+	storage.CourseStorage
+	storage.GradeStorage
+
+	grades []types.CourseGrade
+}
+
+var (
+	algebra = types.Course{Id: 1, Code: "MATH101", Title: "Algebra", Credits: 4, Capacity: 30}
+	poetry  = types.Course{Id: 2, Code: "LIT201", Title: "Poetry", Credits: 2, Capacity: 30}
+)
+
+// newSchool returns the school of student 1, with a score of 95 in algebra and 85 in poetry in the Fall 2025 term
+func newSchool() *school {
+	graded := time.Date(2025, 12, 15, 9, 0, 0, 0, time.UTC)
+	score := func(score float64) *float64 { return &score }
+
+	return &school{grades: []types.CourseGrade{
+		{Grade: types.Grade{Id: 1, StudentId: 1, CourseId: 1, Term: "Fall 2025", TermStart: "2025-09-01", Score: score(95), GradedAt: graded}, Course: algebra},
+		{Grade: types.Grade{Id: 2, StudentId: 1, CourseId: 2, Term: "Fall 2025", TermStart: "2025-09-01", Score: score(85), GradedAt: graded}, Course: poetry},
+	}}
+}
+
+func (s *school) GetStudentById(ctx context.Context, id int64) (types.Student, error) {
+	if id != 1 {
+		return types.Student{}, storage.StudentNotFound(id)
+	}
+	return types.Student{Id: 1, Name: "Ada Lovelace", Email: "ada@example.com", Age: 16, Version: 1}, nil
+}
+
+func (s *school) GetStudentEnrollments(ctx context.Context, studentId int64) ([]types.Enrollment, error) {
+	enrolled := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	return []types.Enrollment{
+		{Id: 1, StudentId: studentId, CourseId: 1, EnrolledAt: enrolled},
+		{Id: 2, StudentId: studentId, CourseId: 2, EnrolledAt: enrolled},
+	}, nil
+}
+
+func (s *school) GetAllCourses(ctx context.Context) ([]types.Course, error) {
+	return []types.Course{algebra, poetry}, nil
+}
+
+func (s *school) GetStudentGrades(ctx context.Context, studentId int64) ([]types.CourseGrade, error) {
+	return s.grades, nil
+}
+
+// reportCard is the report card section of config/local.yaml
+var reportCard = config.ReportCard{
+	School:   "Students API",
+	Title:    "Report Card - {{.Student.Name}}",
+	Footer:   "Generated on {{.GeneratedAt}}",
+	PageSize: "A4",
+	Sections: []string{report.SectionDetails, report.SectionEnrollments, report.SectionGrades},
+}
+
+// newTemplate builds a report card template from the section, failing the test if it cannot
+func newTemplate(t *testing.T, cfg config.ReportCard) *report.Template {
+	t.Helper()
+
+	template, err := report.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return template
+}
+
+// get downloads the report card of the student, registered like main does, for the tenant
+func get(storage reportcard.Storage, scales tenant.Settings[grading.Scale], templates tenant.Settings[*report.Template], school string, path string) *httptest.ResponseRecorder {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/students/{id}/report-card.pdf", reportcard.Get(storage, scales, templates))
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req = req.WithContext(tenant.WithID(req.Context(), school))
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+// pageText returns the content of the pages of a PDF document, gofpdf compresses every page stream
+func pageText(t *testing.T, pdf []byte) string {
+	t.Helper()
+
+	var text strings.Builder
+	for {
+		_, rest, ok := bytes.Cut(pdf, []byte(">>\nstream\n"))
+		if !ok {
+			return text.String()
+		}
+		stream, after, _ := bytes.Cut(rest, []byte("\nendstream"))
+		pdf = after
+
+		r, err := zlib.NewReader(bytes.NewReader(stream))
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text.Write(content)
+	}
+}
+
+func TestGetRendersGrades(t *testing.T) {
+	// South Academy grades a score of 85 an A, the default scale a B
+	lenient, err := grading.NewScale([]grading.Band{{Letter: "A", MinScore: 80, Points: 4}, {Letter: "F", MinScore: 0, Points: 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scales := tenant.Settings[grading.Scale]{Default: grading.DefaultScale, Tenants: map[string]grading.Scale{"south-academy": lenient}}
+
+	letter := reportCard
+	letter.School, letter.PageSize = "South Academy", "Letter"
+	templates := tenant.Settings[*report.Template]{
+		Default: newTemplate(t, reportCard),
+		Tenants: map[string]*report.Template{"south-academy": newTemplate(t, letter)},
+	}
+
+	tests := []struct {
+		school   string
+		want     []string
+		mediaBox string
+	}{
+		// The term GPA weighs the 4 credits of algebra (A, 4.00) and the 2 of poetry (B, 3.00)
+		{tenant.Default, []string{"(Students API)", "(Report Card - Ada Lovelace)", "(Term Fall 2025)",
+			"(MATH101)", "(Algebra)", "(95)", "(A)", "(LIT201)", "(85)", "(B)", "(3.00)", "3.67"}, "/MediaBox [0 0 595.28 841.89]"},
+		{"south-academy", []string{"(South Academy)", "(LIT201)", "(85)", "4.00"}, "/MediaBox [0 0 612.00 792.00]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.school, func(t *testing.T) {
+			res := get(newSchool(), scales, templates, test.school, "/api/students/1/report-card.pdf")
+			if res.Code != http.StatusOK {
+				t.Fatalf("got %d %s, want 200", res.Code, res.Body)
+			}
+			if contentType := res.Header().Get("Content-Type"); contentType != "application/pdf" {
+				t.Fatalf("got Content-Type %q, want application/pdf", contentType)
+			}
+			if disposition := res.Header().Get("Content-Disposition"); disposition != `inline; filename="report-card-1.pdf"` {
+				t.Fatalf("got Content-Disposition %q", disposition)
+			}
+
+			pdf := res.Body.Bytes()
+			if !bytes.Contains(pdf, []byte(test.mediaBox)) {
+				t.Errorf("page size: want %s", test.mediaBox)
+			}
+			text := pageText(t, pdf)
+			for _, want := range test.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("report card lacks %s", want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetWithoutGrades(t *testing.T) {
+	empty := newSchool()
+	empty.grades = nil
+
+	scales := tenant.Settings[grading.Scale]{Default: grading.DefaultScale}
+	templates := tenant.Settings[*report.Template]{Default: newTemplate(t, reportCard)}
+
+	res := get(empty, scales, templates, tenant.Default, "/api/students/1/report-card.pdf")
+	if res.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", res.Code, res.Body)
+	}
+
+	text := pageText(t, res.Body.Bytes())
+	if !strings.Contains(text, "(No grades recorded yet.)") || strings.Contains(text, "(Term ") {
+		t.Fatal("want the grades section to say no grade was recorded and list no term")
+	}
+	if !strings.Contains(text, "(Algebra)") {
+		t.Fatal("want the enrollments listed without grades")
+	}
+}
+
+func TestGetSections(t *testing.T) {
+	// Only the configured sections are rendered, in their order
+	gradesOnly := reportCard
+	gradesOnly.Sections = []string{report.SectionGrades}
+
+	scales := tenant.Settings[grading.Scale]{Default: grading.DefaultScale}
+	templates := tenant.Settings[*report.Template]{Default: newTemplate(t, gradesOnly)}
+
+	res := get(newSchool(), scales, templates, tenant.Default, "/api/students/1/report-card.pdf")
+	if res.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", res.Code, res.Body)
+	}
+
+	text := pageText(t, res.Body.Bytes())
+	if !strings.Contains(text, "(Grades)") || strings.Contains(text, "(Enrollments)") || strings.Contains(text, "(ada@example.com)") {
+		t.Fatal("want the grades section alone")
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	scales := tenant.Settings[grading.Scale]{Default: grading.DefaultScale}
+	valid := tenant.Settings[*report.Template]{Default: newTemplate(t, reportCard)}
+
+	// A title naming a field the report card data lacks only fails when it is rendered
+	broken := reportCard
+	broken.Title = "{{.Student.Nickname}}"
+	failing := tenant.Settings[*report.Template]{Default: newTemplate(t, broken)}
+
+	tests := []struct {
+		name      string
+		templates tenant.Settings[*report.Template]
+		path      string
+		status    int
+	}{
+		{"invalid student id", valid, "/api/students/abc/report-card.pdf", http.StatusBadRequest},
+		{"unknown student", valid, "/api/students/9/report-card.pdf", http.StatusNotFound},
+		{"broken title", failing, "/api/students/1/report-card.pdf", http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := get(newSchool(), scales, test.templates, tenant.Default, test.path)
+			if res.Code != test.status {
+				t.Fatalf("got %d %s, want %d", res.Code, res.Body, test.status)
+			}
+			if contentType := res.Header().Get("Content-Type"); contentType != response.ProblemContentType {
+				t.Fatalf("got Content-Type %q, want a problem", contentType)
+			}
+
+			var problem response.Problem
+			if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Status != test.status {
+				t.Fatalf("got problem %+v, want status %d", problem, test.status)
+			}
+		})
+	}
+}
+
+func TestTemplateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(cfg *config.ReportCard)
+	}{
+		{"unparsable title", func(cfg *config.ReportCard) { cfg.Title = "{{.Student.Name" }},
+		{"unparsable footer", func(cfg *config.ReportCard) { cfg.Footer = "{{end}}" }},
+		{"unknown page size", func(cfg *config.ReportCard) { cfg.PageSize = "A5" }},
+		{"unknown section", func(cfg *config.ReportCard) { cfg.Sections = []string{"attendance"} }},
+		{"missing logo", func(cfg *config.ReportCard) { cfg.LogoPath = "testdata/missing.png" }},
+		{"missing font", func(cfg *config.ReportCard) { cfg.FontPath = "testdata/missing.ttf" }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := reportCard
+			test.change(&cfg)
+			if _, err := report.New(cfg); err == nil {
+				t.Fatal("got a template, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package student
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// studentID parses the student ID taken from the URL path
+func studentID(id string) (int64, error) {
+	intId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || intId <= 0 {
+		return 0, fmt.Errorf("invalid student id %q", id)
+	}
+	return intId, nil
+}
+
+// writeStorageError responds with the HTTP status matching an error returned by the storage
+// A version mismatch is reported like a failed If-Match, the other errors are mapped by response.WriteStorageError
+// A missing student fails an If-Match header too, even "*", as there is no current version to match (RFC 9110 13.1.1)
+func writeStorageError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, storage.ErrVersionMismatch) || errors.Is(err, storage.ErrNotFound) && r.Header.Get("If-Match") != "" {
+		response.WriteError(w, r, http.StatusPreconditionFailed, errPreconditionFailed)
+		return
+	}
+
+	response.WriteStorageError(w, r, err)
+}
@@ -0,0 +1,78 @@
+package student
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// errPreconditionFailed is reported when an If-Match header does not match the student
+var errPreconditionFailed = errors.New("precondition failed: the student has been modified or deleted")
+
+// etag returns the entity tag of a student, a strong tag built from its version
+func etag(student types.Student) string {
+	return fmt.Sprintf(`"%d"`, student.Version)
+}
+
+// matchesETag reports whether an If-Match or If-None-Match header value matches the student
+// Weak tags (W/"...") only match when weak comparison is allowed, as for If-None-Match
+func matchesETag(header string, student types.Student, weak bool) bool {
+	current := etag(student)
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == current {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifMatch evaluates the If-Match header of a write against the current student
+// It returns the version the write must be conditioned on, 0 when there is no If-Match header,
+// and false when the precondition fails
+func ifMatch(r *http.Request, current types.Student) (int64, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return 0, true
+	}
+
+	if !matchesETag(header, current, false) {
+		return 0, false
+	}
+
+	// Condition the write on the version that was checked, so a concurrent change in between is detected too
+	return current.Version, true
+}
+
+// precondition loads the student only when the request carries an If-Match header and evaluates it
+// It returns the version the write must be conditioned on and false when the precondition fails,
+// an unknown student is returned as the storage error, which writeStorageError reports as a failed precondition
+func precondition(r *http.Request, store storage.Storage, id int64) (int64, bool, error) {
+	if r.Header.Get("If-Match") == "" {
+		return 0, true, nil
+	}
+
+	current, err := store.GetStudentById(r.Context(), id)
+	if err != nil {
+		return 0, false, err
+	}
+
+	version, ok := ifMatch(r, current)
+	return version, ok, nil
+}
@@ -0,0 +1,64 @@
+package student
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// History returns an HTTP handler function for listing the changes of a student
+// This function handles the HTTP request to get the history of a student
+// It returns every create, update, delete and restore oldest first, with who made it and the changed fields
+func History(storage storage.AuditStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the ID from the URL path
+		id := r.PathValue("id")
+		slog.Info("Getting the history of a student", slog.String("id", id))
+
+		// Convert the ID to an integer, a malformed ID is the client's mistake
+		intId, err := studentID(id)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Retrieve the history from the storage
+		entries, err := storage.GetStudentHistory(r.Context(), intId)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": entries})
+	}
+}
+
+// getAsOf responds with the student as they were at the time given by the as_of query parameter
+// Only storage backends keeping a history can answer it, the others respond with 501 Not Implemented
+func getAsOf(w http.ResponseWriter, r *http.Request, store storage.Storage, id int64, value string) {
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("as_of must be an RFC 3339 time, e.g. 2024-01-31T12:00:00Z"))
+		return
+	}
+
+	history, ok := store.(storage.AuditStorage)
+	if !ok {
+		response.WriteError(w, r, http.StatusNotImplemented, fmt.Errorf("point-in-time views are not supported by this storage backend"))
+		return
+	}
+
+	// Reconstruct the student from their history
+	student, err := history.GetStudentAsOf(r.Context(), id, at)
+	if err != nil {
+		// Map the storage error to its status, e.g. 404 if the student did not exist at that time
+		writeStorageError(w, r, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, student)
+}
@@ -0,0 +1,91 @@
+package student
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// studentList is the response body of GetAll
+type studentList struct {
+	Data       []types.Student `json:"data"`
+	Pagination pagination      `json:"pagination"`
+}
+
+// pagination describes where a page sits in the whole listing
+type pagination struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int64  `json:"total"`
+}
+
+// listOptions parses the query string of a GetAll request into storage.ListOptions
+// It returns an error describing the first invalid parameter
+func listOptions(r *http.Request) (storage.ListOptions, error) {
+	query := r.URL.Query()
+
+	opts := storage.ListOptions{
+		Cursor:       query.Get("cursor"),
+		EmailDomain:  query.Get("email_domain"),
+		NameContains: query.Get("name_contains"),
+	}
+
+	// Parse the integer parameters, an absent parameter keeps its zero value
+	for name, target := range map[string]*int{"limit": &opts.Limit, "offset": &opts.Offset} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return storage.ListOptions{}, fmt.Errorf("%s must be an integer", name)
+			}
+			*target = n
+		}
+	}
+
+	for name, target := range map[string]**int{"age_min": &opts.AgeMin, "age_max": &opts.AgeMax} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return storage.ListOptions{}, fmt.Errorf("%s must be an integer", name)
+			}
+			*target = &n
+		}
+	}
+
+	sort, err := storage.ParseSort(query.Get("sort"))
+	if err != nil {
+		return storage.ListOptions{}, err
+	}
+	opts.Sort = sort
+
+	// Validate the options now so that mistakes are reported as bad requests
+	if err := opts.Normalize(); err != nil {
+		return storage.ListOptions{}, err
+	}
+
+	if opts.Cursor != "" {
+		if _, err := storage.DecodeCursor(opts.Cursor, opts.Sort); err != nil {
+			return storage.ListOptions{}, err
+		}
+	}
+
+	return opts, nil
+}
+
+// searchLimit parses the limit query parameter of a Search request
+func searchLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return storage.DefaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > storage.MaxLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", storage.MaxLimit)
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,81 @@
+package student
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/mergepatch"
+)
+
+// isMergePatch reports whether the request body is declared as a JSON Merge Patch
+// application/json is accepted as well, since most clients send it by default
+func isMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/merge-patch+json" || mediaType == "application/json"
+}
+
+// mergeStudent applies a merge patch to a student and returns the resulting request
+// It rejects unknown fields and attempts to change the id, the version or the deletion time
+// A field removed by the patch, e.g. {"age":null}, is missing from the request and reported as required
+func mergeStudent(current types.Student, patch []byte) (types.StudentRequest, error) {
+	original, err := json.Marshal(current)
+	if err != nil {
+		return types.StudentRequest{}, err
+	}
+
+	merged, err := mergepatch.Apply(original, patch)
+	if err != nil {
+		return types.StudentRequest{}, err
+	}
+
+	// Decode strictly so that a misspelled field is reported instead of silently ignored
+	// The read-only fields are decoded next to the request, only to check that they are unchanged
+	var student struct {
+		types.StudentRequest
+		Id        int64      `json:"id"`
+		Version   int64      `json:"version"`
+		DeletedAt *time.Time `json:"deleted_at"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&student); err != nil {
+		return types.StudentRequest{}, err
+	}
+
+	if student.Id != current.Id {
+		return types.StudentRequest{}, fmt.Errorf("field id cannot be changed")
+	}
+	if student.Version != current.Version {
+		return types.StudentRequest{}, fmt.Errorf("field version cannot be changed, use If-Match instead")
+	}
+	if student.DeletedAt != nil {
+		return types.StudentRequest{}, fmt.Errorf("field deleted_at cannot be changed, use DELETE instead")
+	}
+
+	return student.StudentRequest, nil
+}
+
+// changedFields returns the patch holding the fields of the validated request that differ from the student
+func changedFields(before types.Student, after types.StudentRequest) types.StudentPatch {
+	var patch types.StudentPatch
+
+	if after.Name != before.Name {
+		patch.Name = &after.Name
+	}
+	if after.Email != before.Email {
+		patch.Email = &after.Email
+	}
+	if *after.Age != before.Age {
+		patch.Age = after.Age
+	}
+
+	return patch
+}
@@ -1,19 +1,18 @@
 package student
 
 import (
+	"bytes"
 	"encoding/json" // Package for JSON encoding and decoding
 	"errors"        // Package for error handling
 	"fmt"           // Package for formatted I/O
 	"io"            // Package for I/O primitives
-	"log"
 	"log/slog" // Package for structured logging
 	"net/http" // Package for HTTP client and server
-	"strconv"
+	"strings"
 
 	"github.com/Priyang1310/Students-API-GO/internal/storage"
 	"github.com/Priyang1310/Students-API-GO/internal/types"          // Importing custom types
 	"github.com/Priyang1310/Students-API-GO/internal/utils/response" // Importing response utility functions
-	"github.com/go-playground/validator/v10"                         // Importing the validator package for struct validation
 )
 
 // New returns an HTTP handler function for creating a new student
@@ -23,40 +22,38 @@ func New(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		// Declare a variable to hold the student data
-		var student types.Student
+		var student types.StudentRequest
 
 		// Decode the JSON request body into the student variable
 		err := json.NewDecoder(r.Body).Decode(&student)
 		if errors.Is(err, io.EOF) { // Check if the request body is empty
 			// Return a bad request error if the request body is empty
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(fmt.Errorf("empty body")))
+			response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("empty body"))
 			return
 		}
 
 		if err != nil { // Check for other decoding errors
 			// Return a bad request error if there's a decoding error
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(err))
+			response.WriteError(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		// Validate the student struct using the validator package
-		if err := validator.New().Struct(student); err != nil {
-			// If validation fails, cast the error to ValidationErrors and respond with a validation error
-			validateErr := err.(validator.ValidationErrors)
-			response.WriteJSON(w, http.StatusBadRequest, response.ValidationError(validateErr))
+		// Normalize and validate the student, responding with the invalid fields if it fails
+		if !validStudent(w, r, &student) {
 			return // Ensure to return after sending the response
 		}
 
 		// Create a new student in the storage
 		lastID, err := storage.CreateStudent(
+			r.Context(),
 			student.Name,
 			student.Email,
-			student.Age,
+			*student.Age,
 		)
 
 		if err != nil {
-			// Return an internal server error if there's an error creating the student
-			response.WriteJSON(w, http.StatusInternalServerError, err)
+			// Map the storage error to its status, e.g. 409 for an email that is already in use
+			writeStorageError(w, r, err)
 			return
 		}
 
@@ -71,25 +68,38 @@ func New(storage storage.Storage) http.HandlerFunc {
 // GetById returns an HTTP handler function for getting a student by ID
 // This function handles the HTTP request to get a student by ID
 // It retrieves the student from the storage and returns the student data
+// With the as_of query parameter it returns the student as they were at that time instead
 func GetById(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get the ID from the URL path
 		id := r.PathValue("id")
 		slog.Info("Getting a student!", slog.String("id", id))
 
-		// Convert the ID to an integer
-		intId, err := strconv.ParseInt(id, 10, 64)
+		// Convert the ID to an integer, a malformed ID is the client's mistake
+		intId, err := studentID(id)
 		if err != nil {
-			// Log a fatal error if there's an error converting the ID
-			log.Fatal("Error during conversion from tring to int64")
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// A point-in-time view is answered from the student's history instead
+		if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+			getAsOf(w, r, storage, intId, asOf)
 			return
 		}
 
 		// Retrieve the student from the storage
-		student, e := storage.GetStudentById(intId)
-		if e != nil {
-			// Return an internal server error if there's an error retrieving the student
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(e))
+		student, err := storage.GetStudentById(r.Context(), intId)
+		if err != nil {
+			// Map the storage error to its status, e.g. 404 for an unknown student
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Expose the version as the ETag and answer conditional GETs without a body
+		w.Header().Set("ETag", etag(student))
+		if header := r.Header.Get("If-None-Match"); header != "" && matchesETag(header, student, true) {
+			w.WriteHeader(http.StatusNotModified)
 			return
 		}
 
@@ -98,24 +108,81 @@ func GetById(storage storage.Storage) http.HandlerFunc {
 	}
 }
 
-// GetAll returns an HTTP handler function for getting all students
-// This function handles the HTTP request to get all students
-// It retrieves all students from the storage and returns the student data
+// GetAll returns an HTTP handler function for listing students
+// This function handles the HTTP request to get a page of students
+// It supports the limit, cursor, offset and sort query parameters and the age_min, age_max,
+// email_domain and name_contains filters, and returns the students with pagination metadata
 func GetAll(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Log a message
 		slog.Info("Getting all students")
 
-		// Retrieve all students from the storage
-		students, err := storage.GetAllStudents()
+		// Parse and validate the listing options from the query string
+		opts, err := listOptions(r)
 		if err != nil {
-			// Return an internal server error if there's an error retrieving the students
-			response.WriteJSON(w, http.StatusInternalServerError, err)
+			response.WriteError(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		// Respond with the student data
-		response.WriteJSON(w, http.StatusOK, students)
+		// Retrieve the requested page of students from the storage
+		page, err := storage.GetAllStudents(r.Context(), opts)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Respond with the student data, an empty page is an empty array rather than null
+		students := page.Students
+		if students == nil {
+			students = []types.Student{}
+		}
+
+		response.WriteJSON(w, http.StatusOK, studentList{
+			Data: students,
+			Pagination: pagination{
+				Limit:      opts.Limit,
+				Offset:     opts.Offset,
+				NextCursor: page.NextCursor,
+				Total:      page.Total,
+			},
+		})
+	}
+}
+
+// Search returns an HTTP handler function for searching students
+// This function handles the HTTP request to search students by name and email
+// It reads the search text from the q query parameter and returns the best matches first
+func Search(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the search text from the query string
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		slog.Info("Searching students", slog.String("q", query))
+
+		if query == "" {
+			response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("query parameter q is required"))
+			return
+		}
+
+		// Parse the optional limit, it follows the same bounds as listing
+		limit, err := searchLimit(r)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Search the students in the storage
+		students, err := storage.SearchStudents(r.Context(), query, limit)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Respond with the matches, no match is an empty array rather than null
+		if students == nil {
+			students = []types.Student{}
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string][]types.Student{"data": students})
 	}
 }
 
@@ -128,81 +195,173 @@ func Update(storage storage.Storage) http.HandlerFunc {
 		id := r.PathValue("id")
 		slog.Info("Updating a student with", slog.String("id", id))
 
-		// Convert the ID to an integer
-		intId, err := strconv.ParseInt(id, 10, 64)
-
+		// Convert the ID to an integer, a malformed ID is the client's mistake
+		intId, err := studentID(id)
 		if err != nil {
-			// Log a fatal error if there's an error converting the ID
-			log.Fatal("error converting string to int")
+			response.WriteError(w, r, http.StatusBadRequest, err)
 			return
 		}
 
 		// Declare a variable to hold the student data
-		var student types.Student
+		var student types.StudentRequest
 
 		// Decode the JSON request body into the student variable
 		err = json.NewDecoder(r.Body).Decode(&student)
 
-		if err == io.EOF {
-			// Return an internal server error if the request body is empty
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+		if errors.Is(err, io.EOF) {
+			// Return a bad request error if the request body is empty
+			response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("empty body"))
 			return
 		}
 
 		if err != nil {
-			// Return an internal server error if there's a decoding error
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			// Return a bad request error if there's a decoding error
+			response.WriteError(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		// Validate the student struct using the validator package
-		if err := validator.New().Struct(student); err != nil {
-			// If validation fails, cast the error to ValidationErrors and respond with a validation error
-			validateErr := err.(validator.ValidationErrors)
-			response.WriteJSON(w, http.StatusBadRequest, response.ValidationError(validateErr))
+		// Normalize and validate the student, responding with the invalid fields if it fails
+		if !validStudent(w, r, &student) {
 			return // Ensure to return after sending the response
 		}
 
+		// Honour If-Match, so that two clients editing the same student cannot overwrite each other
+		version, ok, err := precondition(r, storage, intId)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+		if !ok {
+			response.WriteError(w, r, http.StatusPreconditionFailed, errPreconditionFailed)
+			return
+		}
+
 		// Update the student in the storage
-		updatedStudent, err := storage.UpdateStudent(intId, student.Name, student.Email, student.Age)
+		updatedStudent, err := storage.UpdateStudent(r.Context(), intId, student.Name, student.Email, *student.Age, version)
 
 		if err != nil {
-			// Return an internal server error if there's an error updating the student
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			// Map the storage error to its status, e.g. 412 if the student changed since the precondition check
+			writeStorageError(w, r, err)
 			return
 		}
 
 		// Log a success message
 		slog.Info("Student Updated Successfully!")
 
-		// Respond with the updated student data
+		// Respond with the updated student data and its new ETag
+		w.Header().Set("ETag", etag(updatedStudent))
+		response.WriteJSON(w, http.StatusOK, updatedStudent)
+	}
+}
+
+// Patch returns an HTTP handler function for partially updating a student
+// This function handles the HTTP request to apply an RFC 7396 JSON Merge Patch to a student
+// It merges the patch into the stored student, validates the result and saves only the changed fields
+func Patch(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the ID from the URL path
+		id := r.PathValue("id")
+		slog.Info("Patching a student with", slog.String("id", id))
+
+		// Convert the ID to an integer, a malformed ID is the client's mistake
+		intId, err := studentID(id)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Only merge patches are supported, a plain JSON body is treated as one
+		if !isMergePatch(r) {
+			response.WriteError(w, r, http.StatusUnsupportedMediaType, fmt.Errorf("content type must be application/merge-patch+json"))
+			return
+		}
+
+		// Read the whole patch, it is merged as a document rather than decoded into a struct
+		patch, err := io.ReadAll(r.Body)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		if len(bytes.TrimSpace(patch)) == 0 {
+			response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("empty body"))
+			return
+		}
+
+		// Retrieve the student the patch applies to
+		current, err := storage.GetStudentById(r.Context(), intId)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Honour If-Match, so that two clients editing the same student cannot overwrite each other
+		version, ok := ifMatch(r, current)
+		if !ok {
+			response.WriteError(w, r, http.StatusPreconditionFailed, errPreconditionFailed)
+			return
+		}
+
+		// Merge the patch into the current student and decode the result
+		student, err := mergeStudent(current, patch)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Normalize and validate the merged student, exactly like a full update
+		if !validStudent(w, r, &student) {
+			return
+		}
+
+		// Save only the fields that actually changed
+		updatedStudent, err := storage.PatchStudent(r.Context(), intId, changedFields(current, student), version)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Log a success message
+		slog.Info("Student Patched Successfully!")
+
+		// Respond with the updated student data and its new ETag
+		w.Header().Set("ETag", etag(updatedStudent))
 		response.WriteJSON(w, http.StatusOK, updatedStudent)
 	}
 }
 
 // DeleteById returns an HTTP handler function for deleting a student by ID
 // This function handles the HTTP request to delete a student by ID
-// It deletes the student from the storage and returns a success message
+// It moves the student to the trash, from where it can be restored until it is purged, and returns a success message
 func DeleteById(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get the ID from the URL path
 		id := r.PathValue("id")
 
-		// Convert the ID to an integer
-		intId, err := strconv.ParseInt(id, 10, 64)
+		// Convert the ID to an integer, a malformed ID is the client's mistake
+		intId, err := studentID(id)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
 
+		// Honour If-Match, so that a student edited in the meantime is not deleted by mistake
+		version, ok, err := precondition(r, storage, intId)
 		if err != nil {
-			// Return an internal server error if there's an error converting the ID
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			writeStorageError(w, r, err)
+			return
+		}
+		if !ok {
+			response.WriteError(w, r, http.StatusPreconditionFailed, errPreconditionFailed)
 			return
 		}
 
 		// Delete the student from the storage
-		err = storage.DeleteStudentById(intId)
+		err = storage.DeleteStudentById(r.Context(), intId, version)
 
 		if err != nil {
-			// Return an internal server error if there's an error deleting the student
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			// Map the storage error to its status, e.g. 404 for an unknown student
+			writeStorageError(w, r, err)
+			return
 		}
 
 		// Respond with a success message
@@ -212,17 +371,16 @@ func DeleteById(storage storage.Storage) http.HandlerFunc {
 
 // DeleteAll returns an HTTP handler function for deleting all students
 // This function handles the HTTP request to delete all students
-// It deletes all students from the storage and returns a success message
+// It moves all students to the trash and returns a success message
 func DeleteAll(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Log a message
 		slog.Info("Deleting All Students!")
 
 		// Delete all students from the storage
-		err := storage.DeleteAllStudents()
+		err := storage.DeleteAllStudents(r.Context())
 		if err != nil {
-			// Return an internal server error if there's an error deleting the students
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			writeStorageError(w, r, err)
 			return
 		}
 
@@ -0,0 +1,518 @@
+package student_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/student"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/memory"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+)
+
+// newServer starts the student routes, registered like main does, over an empty in-memory storage
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	storage := memory.New()
+
+	router := http.NewServeMux()
+	router.HandleFunc("POST /api/students", student.New(storage))
+	router.HandleFunc("GET /api/students/search", student.Search(storage))
+	router.HandleFunc("GET /api/students/trash", student.Trash(storage))
+	router.HandleFunc("GET /api/students/{id}", student.GetById(storage))
+	router.HandleFunc("GET /api/students", student.GetAll(storage))
+	router.HandleFunc("PUT /api/students/{id}", student.Update(storage))
+	router.HandleFunc("PATCH /api/students/{id}", student.Patch(storage))
+	router.HandleFunc("DELETE /api/students/{id}", student.DeleteById(storage))
+	router.HandleFunc("DELETE /api/students", student.DeleteAll(storage))
+	router.HandleFunc("POST /api/students/{id}/restore", student.Restore(storage))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do sends a request with the given body and headers (name, value, ...) and returns the response and its body
+func do(t *testing.T, method string, target string, body string, headers ...string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, target, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, strings.TrimSpace(string(data))
+}
+
+// decode unmarshals the body of a response, failing the test if it is not the expected JSON
+func decode(t *testing.T, body string, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+}
+
+// create adds a student and returns the path of its resource
+func create(t *testing.T, server *httptest.Server, body string) string {
+	t.Helper()
+
+	res, data := do(t, http.MethodPost, server.URL+"/api/students", body)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /api/students %s: got %d %s, want 201", body, res.StatusCode, data)
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	decode(t, data, &created)
+	if created.ID == 0 {
+		t.Fatalf("POST /api/students: got no id in %s", data)
+	}
+	return server.URL + "/api/students/" + strconv.FormatInt(created.ID, 10)
+}
+
+func TestCreateAndGet(t *testing.T) {
+	server := newServer(t)
+	path := create(t, server, `{"name":"  Ada   Lovelace ","email":"Ada@Example.COM","age":20}`)
+
+	res, body := do(t, http.MethodGet, path, "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: got %d %s, want 200", path, res.StatusCode, body)
+	}
+	var got types.Student
+	decode(t, body, &got)
+	if got.Name != "Ada Lovelace" || got.Email != "ada@example.com" || got.Age != 20 {
+		t.Fatalf("GET %s: got %+v, want the normalized student", path, got)
+	}
+
+	// The ETag lets a client revalidate its copy without downloading it again
+	tag := res.Header.Get("ETag")
+	if tag == "" {
+		t.Fatalf("GET %s: no ETag", path)
+	}
+	if res, _ := do(t, http.MethodGet, path, "", "If-None-Match", tag); res.StatusCode != http.StatusNotModified {
+		t.Fatalf("GET %s with If-None-Match %s: got %d, want 304", path, tag, res.StatusCode)
+	}
+
+	if res, _ := do(t, http.MethodGet, server.URL+"/api/students/999", ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET of an unknown student: got %d, want 404", res.StatusCode)
+	}
+	if res, _ := do(t, http.MethodGet, server.URL+"/api/students/abc", ""); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("GET with a non-numeric id: got %d, want 400", res.StatusCode)
+	}
+}
+
+func TestCreateRejectsInvalidStudents(t *testing.T) {
+	server := newServer(t)
+
+	tests := []struct {
+		name  string
+		body  string
+		field string
+		tag   string
+	}{
+		{"missing email", `{"name":"Ada Lovelace","age":20}`, "email", "required"},
+		{"missing age", `{"name":"Ada Lovelace","email":"ada@example.com"}`, "age", "required"},
+		{"age out of range", `{"name":"Ada Lovelace","email":"ada@example.com","age":300}`, "age", "student_age"},
+		{"invalid email", `{"name":"Ada Lovelace","email":"ada","age":20}`, "email", "email"},
+		{"invalid name", `{"name":"4da","email":"ada@example.com","age":20}`, "name", "person_name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, body := do(t, http.MethodPost, server.URL+"/api/students", tt.body)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("got %d %s, want 400", res.StatusCode, body)
+			}
+			if contentType := res.Header.Get("Content-Type"); contentType != "application/problem+json" {
+				t.Fatalf("got Content-Type %q, want application/problem+json", contentType)
+			}
+			var problem response.Problem
+			decode(t, body, &problem)
+			for _, e := range problem.Errors {
+				if e.Field == tt.field && e.Tag == tt.tag {
+					return
+				}
+			}
+			t.Fatalf("got errors %+v, want %s failing %s", problem.Errors, tt.field, tt.tag)
+		})
+	}
+
+	if res, _ := do(t, http.MethodPost, server.URL+"/api/students", ""); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("POST with an empty body: got %d, want 400", res.StatusCode)
+	}
+
+	// Emails are unique, whatever their case
+	create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+	if res, body := do(t, http.MethodPost, server.URL+"/api/students", `{"name":"Ada King","email":"ADA@example.com","age":21}`); res.StatusCode != http.StatusConflict {
+		t.Fatalf("POST with a taken email: got %d %s, want 409", res.StatusCode, body)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	server := newServer(t)
+	create(t, server, `{"name":"Carol Shaw","email":"carol@example.com","age":22}`)
+	create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+	create(t, server, `{"name":"Bob Kahn","email":"bob@example.org","age":21}`)
+
+	type page struct {
+		Data       []types.Student `json:"data"`
+		Pagination struct {
+			Limit      int    `json:"limit"`
+			NextCursor string `json:"next_cursor"`
+			Total      int64  `json:"total"`
+		} `json:"pagination"`
+	}
+
+	res, body := do(t, http.MethodGet, server.URL+"/api/students?limit=2&sort=name", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET /api/students: got %d %s, want 200", res.StatusCode, body)
+	}
+	var first page
+	decode(t, body, &first)
+	if len(first.Data) != 2 || first.Data[0].Name != "Ada Lovelace" || first.Data[1].Name != "Bob Kahn" {
+		t.Fatalf("first page: got %+v, want Ada and Bob", first.Data)
+	}
+	if first.Pagination.Limit != 2 || first.Pagination.Total != 3 || first.Pagination.NextCursor == "" {
+		t.Fatalf("first page: got pagination %+v, want limit 2, total 3 and a cursor", first.Pagination)
+	}
+
+	_, body = do(t, http.MethodGet, server.URL+"/api/students?limit=2&sort=name&cursor="+first.Pagination.NextCursor, "")
+	var second page
+	decode(t, body, &second)
+	if len(second.Data) != 1 || second.Data[0].Name != "Carol Shaw" || second.Pagination.NextCursor != "" {
+		t.Fatalf("second page: got %+v, want only Carol and no cursor", second)
+	}
+
+	// Filters narrow the listing and its total
+	_, body = do(t, http.MethodGet, server.URL+"/api/students?email_domain=example.org", "")
+	var filtered page
+	decode(t, body, &filtered)
+	if len(filtered.Data) != 1 || filtered.Pagination.Total != 1 {
+		t.Fatalf("email_domain=example.org: got %+v, want only Bob", filtered)
+	}
+
+	for _, query := range []string{"limit=-1", "limit=abc", "offset=-1", "sort=shoe_size"} {
+		if res, _ := do(t, http.MethodGet, server.URL+"/api/students?"+query, ""); res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("GET /api/students?%s: got %d, want 400", query, res.StatusCode)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	server := newServer(t)
+	create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+	create(t, server, `{"name":"Bob Kahn","email":"bob@example.org","age":21}`)
+
+	res, body := do(t, http.MethodGet, server.URL+"/api/students/search?q=love", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("search: got %d %s, want 200", res.StatusCode, body)
+	}
+	var found struct {
+		Data []types.Student `json:"data"`
+	}
+	decode(t, body, &found)
+	if len(found.Data) != 1 || found.Data[0].Name != "Ada Lovelace" {
+		t.Fatalf("search q=love: got %+v, want Ada", found.Data)
+	}
+
+	if res, _ := do(t, http.MethodGet, server.URL+"/api/students/search", ""); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("search without q: got %d, want 400", res.StatusCode)
+	}
+}
+
+func TestUpdateRequiresCurrentVersion(t *testing.T) {
+	server := newServer(t)
+	path := create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+
+	res, _ := do(t, http.MethodGet, path, "")
+	tag := res.Header.Get("ETag")
+
+	res, body := do(t, http.MethodPut, path, `{"name":"Ada King","email":"ada@example.com","age":21}`, "If-Match", tag)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("PUT with the current ETag: got %d %s, want 200", res.StatusCode, body)
+	}
+	if res.Header.Get("ETag") == tag {
+		t.Fatalf("PUT kept the ETag %s, want a new version", tag)
+	}
+
+	// The copy read before the first update is stale now
+	res, body = do(t, http.MethodPut, path, `{"name":"Ada Byron","email":"ada@example.com","age":22}`, "If-Match", tag)
+	if res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("PUT with a stale ETag: got %d %s, want 412", res.StatusCode, body)
+	}
+
+	_, body = do(t, http.MethodGet, path, "")
+	var got types.Student
+	decode(t, body, &got)
+	if got.Name != "Ada King" || got.Age != 21 {
+		t.Fatalf("after the rejected PUT: got %+v, want the first update", got)
+	}
+}
+
+func TestIfMatchMissingStudent(t *testing.T) {
+	server := newServer(t)
+	path := create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+
+	res, _ := do(t, http.MethodGet, path, "")
+	tag := res.Header.Get("ETag")
+	if res, body := do(t, http.MethodDelete, path, ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE: got %d %s, want 200", res.StatusCode, body)
+	}
+
+	// Without a current student no If-Match can hold, not even "*"
+	missing := server.URL + "/api/students/999"
+	for _, ifMatch := range []string{tag, "*"} {
+		for _, target := range []string{path, missing} {
+			writes := []struct {
+				method string
+				body   string
+				header []string
+			}{
+				{http.MethodPut, `{"name":"Ada King","email":"ada@example.com","age":21}`, []string{"If-Match", ifMatch}},
+				{http.MethodPatch, `{"age":21}`, []string{"If-Match", ifMatch, "Content-Type", "application/merge-patch+json"}},
+				{http.MethodDelete, "", []string{"If-Match", ifMatch}},
+			}
+			for _, write := range writes {
+				res, body := do(t, write.method, target, write.body, write.header...)
+				if res.StatusCode != http.StatusPreconditionFailed {
+					t.Errorf("%s %s with If-Match %s: got %d %s, want 412", write.method, target, ifMatch, res.StatusCode, body)
+				}
+			}
+		}
+	}
+
+	// Without If-Match a missing student is still not found
+	if res, body := do(t, http.MethodPut, missing, `{"name":"Ada King","email":"ada@example.com","age":21}`); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("PUT of a missing student without If-Match: got %d %s, want 404", res.StatusCode, body)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	server := newServer(t)
+	path := create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+
+	res, body := do(t, http.MethodPatch, path, `{"age":21}`, "Content-Type", "application/merge-patch+json")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("PATCH: got %d %s, want 200", res.StatusCode, body)
+	}
+	var got types.Student
+	decode(t, body, &got)
+	if got.Name != "Ada Lovelace" || got.Email != "ada@example.com" || got.Age != 21 {
+		t.Fatalf("PATCH {\"age\":21}: got %+v, want only the age changed", got)
+	}
+
+	if res, _ := do(t, http.MethodPatch, path, `{"age":22}`, "Content-Type", "text/plain"); res.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("PATCH as text/plain: got %d, want 415", res.StatusCode)
+	}
+	if res, _ := do(t, http.MethodPatch, path, `{"age":0}`); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("PATCH to an invalid age: got %d, want 400", res.StatusCode)
+	}
+}
+
+// ageWrite sends a student body, or a patch of it, through one of the handlers writing students
+type ageWrite struct {
+	name   string
+	method string
+	body   func(age string) string // body returns the request body holding the age, "" leaves it out
+}
+
+// ageWrites are the create, update and patch requests, which must all judge an age the same way
+var ageWrites = []ageWrite{
+	{"create", http.MethodPost, func(age string) string {
+		if age == "" {
+			return `{"name":"Bob Kahn","email":"bob@example.com"}`
+		}
+		return `{"name":"Bob Kahn","email":"bob@example.com","age":` + age + `}`
+	}},
+	{"update", http.MethodPut, func(age string) string {
+		if age == "" {
+			return `{"name":"Ada Lovelace","email":"ada@example.com"}`
+		}
+		return `{"name":"Ada Lovelace","email":"ada@example.com","age":` + age + `}`
+	}},
+	{"patch", http.MethodPatch, func(age string) string {
+		if age == "" {
+			return `{"age":null}`
+		}
+		return `{"age":` + age + `}`
+	}},
+}
+
+// target returns the URL the write is sent to, a new student is created first for the update and the patch
+func (w ageWrite) target(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	if w.method == http.MethodPost {
+		return server.URL + "/api/students"
+	}
+	return create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+}
+
+// configureAges sets the accepted age range for the rest of the test
+func configureAges(t *testing.T, min int, max int) {
+	t.Helper()
+
+	if err := validation.Configure(validation.Rules{AgeMin: min, AgeMax: max, NameMinLength: 2, NameMaxLength: 100}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := validation.Configure(validation.Rules{AgeMin: 1, AgeMax: 120, NameMinLength: 2, NameMaxLength: 100}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestAgeValidation(t *testing.T) {
+	configureAges(t, 1, 120)
+
+	tests := []struct {
+		name     string
+		age      string
+		tag      string
+		messages map[string]string // messages holds the expected message of the age error by language
+	}{
+		{"missing age", "", "required", map[string]string{
+			"en": "age is a required field",
+			"es": "age es un campo requerido",
+			"fr": "age est un champ obligatoire",
+			"hi": "age एक आवश्यक फ़ील्ड है",
+		}},
+		{"age 0 below the range", "0", validation.TagStudentAge, map[string]string{
+			"en": "age must be between 1 and 120",
+			"es": "age debe estar entre 1 y 120",
+			"fr": "age doit être compris entre 1 et 120",
+			"hi": "age 1 और 120 के बीच होना चाहिए",
+		}},
+		{"age above the range", "300", validation.TagStudentAge, map[string]string{
+			"en": "age must be between 1 and 120",
+			"es": "age debe estar entre 1 y 120",
+			"fr": "age doit être compris entre 1 et 120",
+			"hi": "age 1 और 120 के बीच होना चाहिए",
+		}},
+	}
+	for _, write := range ageWrites {
+		for _, tt := range tests {
+			for _, language := range []string{"en", "es", "fr", "hi"} {
+				t.Run(write.name+"/"+tt.name+"/"+language, func(t *testing.T) {
+					server := newServer(t)
+					res, body := do(t, write.method, write.target(t, server), write.body(tt.age), "Accept-Language", language)
+					if res.StatusCode != http.StatusBadRequest {
+						t.Fatalf("got %d %s, want 400", res.StatusCode, body)
+					}
+
+					var problem response.Problem
+					decode(t, body, &problem)
+					if len(problem.Errors) != 1 || problem.Errors[0].Field != "age" || problem.Errors[0].Tag != tt.tag {
+						t.Fatalf("got errors %+v, want only age failing %s", problem.Errors, tt.tag)
+					}
+					if message := problem.Errors[0].Message; message != tt.messages[language] {
+						t.Fatalf("got message %q, want %q", message, tt.messages[language])
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestAgeZeroWhenAllowed(t *testing.T) {
+	configureAges(t, 0, 120)
+
+	for _, write := range ageWrites {
+		t.Run(write.name, func(t *testing.T) {
+			server := newServer(t)
+			target := write.target(t, server)
+
+			// 0 is an age like any other once the range starts there
+			res, body := do(t, write.method, target, write.body("0"))
+			if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+				t.Fatalf("age 0: got %d %s, want it accepted", res.StatusCode, body)
+			}
+
+			// A missing age is still required
+			res, body = do(t, write.method, target, write.body(""))
+			if res.StatusCode != http.StatusBadRequest || !strings.Contains(body, `"tag":"required"`) {
+				t.Fatalf("missing age: got %d %s, want age reported as required", res.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestDeleteAndRestore(t *testing.T) {
+	server := newServer(t)
+	path := create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+
+	if res, body := do(t, http.MethodDelete, path, ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE: got %d %s, want 200", res.StatusCode, body)
+	}
+	if res, _ := do(t, http.MethodGet, path, ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET of a deleted student: got %d, want 404", res.StatusCode)
+	}
+
+	_, body := do(t, http.MethodGet, server.URL+"/api/students/trash", "")
+	var trash struct {
+		Data []types.Student `json:"data"`
+	}
+	decode(t, body, &trash)
+	if len(trash.Data) != 1 || trash.Data[0].Name != "Ada Lovelace" {
+		t.Fatalf("trash: got %+v, want Ada", trash.Data)
+	}
+
+	if res, body := do(t, http.MethodPost, path+"/restore", ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("restore: got %d %s, want 200", res.StatusCode, body)
+	}
+	if res, _ := do(t, http.MethodGet, path, ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("GET of a restored student: got %d, want 200", res.StatusCode)
+	}
+	if res, _ := do(t, http.MethodPost, path+"/restore", ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("restore of a student not in the trash: got %d, want 404", res.StatusCode)
+	}
+}
+
+func TestDeleteAll(t *testing.T) {
+	server := newServer(t)
+	create(t, server, `{"name":"Ada Lovelace","email":"ada@example.com","age":20}`)
+	create(t, server, `{"name":"Bob Kahn","email":"bob@example.org","age":21}`)
+
+	if res, body := do(t, http.MethodDelete, server.URL+"/api/students", ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE /api/students: got %d %s, want 200", res.StatusCode, body)
+	}
+
+	var list struct {
+		Data []types.Student `json:"data"`
+	}
+	_, body := do(t, http.MethodGet, server.URL+"/api/students", "")
+	decode(t, body, &list)
+	if len(list.Data) != 0 {
+		t.Fatalf("GET /api/students after deleting them all: got %+v, want none", list.Data)
+	}
+
+	// Every student went to the trash
+	_, body = do(t, http.MethodGet, server.URL+"/api/students/trash", "")
+	decode(t, body, &list)
+	if len(list.Data) != 2 {
+		t.Fatalf("trash after deleting them all: got %+v, want both students", list.Data)
+	}
+}
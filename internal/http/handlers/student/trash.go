@@ -0,0 +1,61 @@
+package student
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// Trash returns an HTTP handler function for listing deleted students
+// This function handles the HTTP request to get the students in the trash
+// It returns them most recently deleted first, together with their deletion time
+func Trash(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Log a message
+		slog.Info("Getting deleted students")
+
+		// Retrieve the trashed students from the storage
+		students, err := storage.GetDeletedStudents(r.Context())
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, map[string]any{"data": students})
+	}
+}
+
+// Restore returns an HTTP handler function for restoring a deleted student
+// This function handles the HTTP request to take a student out of the trash
+// It returns the restored student, or 409 Conflict if their email or their seat in a course was given to another student meanwhile
+func Restore(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the ID from the URL path
+		id := r.PathValue("id")
+		slog.Info("Restoring a student with", slog.String("id", id))
+
+		// Convert the ID to an integer, a malformed ID is the client's mistake
+		intId, err := studentID(id)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Take the student out of the trash
+		student, err := storage.RestoreStudent(r.Context(), intId)
+		if err != nil {
+			// Map the storage error to its status, e.g. 404 for a student that is not in the trash
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Log a success message
+		slog.Info("Student Restored Successfully!")
+
+		// Respond with the restored student data and its new ETag
+		w.Header().Set("ETag", etag(student))
+		response.WriteJSON(w, http.StatusOK, student)
+	}
+}
@@ -0,0 +1,16 @@
+package student
+
+import (
+	"net/http"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+)
+
+// validStudent normalizes and validates a student decoded from a request
+// It writes the validation problem, in the client's language, and returns false if the student is invalid
+// The create, update and patch handlers all use it, so they share the same rules
+func validStudent(w http.ResponseWriter, r *http.Request, student *types.StudentRequest) bool {
+	return response.Validated(w, r, validation.Student(student))
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+)
+
+// ActorHeader is the header naming who makes a request, as set by a proxy that authenticates the clients
+const ActorHeader = "X-Actor"
+
+// maxActorLength bounds the length of an actor name
+const maxActorLength = 128
+
+// Actor returns a middleware that puts the actor named by the X-Actor header into the request context,
+// where the audit log picks it up
+// Without authentication enabled the API does not identify its clients itself, so the header must be set by
+// a trusted proxy in front of it; the authentication middleware replaces it with the identity of the client
+// Requests without a well-formed header are recorded as audit.Anonymous
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(ActorHeader); validActor(actor) {
+			r = r.WithContext(audit.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validActor reports whether an actor name is short, valid UTF-8 and free of control characters
+func validActor(actor string) bool {
+	if actor == "" || len(actor) > maxActorLength || !utf8.ValidString(actor) {
+		return false
+	}
+	for _, c := range actor {
+		if unicode.IsControl(c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// realm is announced in the WWW-Authenticate challenges of rejected requests
+const realm = "students-api"
+
+// Authenticate returns a middleware that requires every request to prove the identity of its client
+// The authenticators are tried in order, the first one finding credentials of its kind decides
+// An authenticated request carries its auth.Identity in the context, any other one is rejected
+// with a 401 Unauthorized problem, or 403 Forbidden for a cross-site request carrying a session cookie
+func Authenticate(authenticators ...auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, authenticator := range authenticators {
+				identity, err := authenticator.Authenticate(r)
+				if errors.Is(err, auth.ErrNoCredentials) {
+					continue
+				}
+				// The credentials are valid for another page, signing in again would not help
+				if errors.Is(err, auth.ErrCrossSite) {
+					response.WriteError(w, r, http.StatusForbidden, err)
+					return
+				}
+				if err != nil {
+					unauthorized(w, r, authenticators, authenticator, err)
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+				return
+			}
+
+			unauthorized(w, r, authenticators, nil, fmt.Errorf("authentication required"))
+		})
+	}
+}
+
+// unauthorized responds with a 401 Unauthorized problem, challenging the client with every accepted scheme
+// The challenge of the authenticator that rejected the credentials, if any, tells that they are invalid
+// Authenticators without scheme, e.g. session cookies, have no challenge
+func unauthorized(w http.ResponseWriter, r *http.Request, authenticators []auth.Authenticator, failed auth.Authenticator, err error) {
+	for _, authenticator := range authenticators {
+		if authenticator.Scheme() == "" {
+			continue
+		}
+		challenge := []string{fmt.Sprintf("realm=%q", realm)}
+		if authenticator == failed {
+			challenge = append(challenge, `error="invalid_token"`)
+		}
+		w.Header().Add("WWW-Authenticate", authenticator.Scheme()+" "+strings.Join(challenge, ", "))
+	}
+
+	response.WriteError(w, r, http.StatusUnauthorized, err)
+}
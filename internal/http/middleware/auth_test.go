@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/http/middleware"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+// bearer returns the Authorization header of an HS256 token for the subject, expiring after ttl
+func bearer(t *testing.T, subject string, ttl time.Duration) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": subject,
+		"exp": time.Now().Add(ttl).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+// cookieAuth is an authenticator without scheme, like the session cookies of the staff sign-in
+type cookieAuth struct{}
+
+func (cookieAuth) Scheme() string { return "" }
+
+func (cookieAuth) Authenticate(r *http.Request) (auth.Identity, error) {
+	return auth.Identity{}, auth.ErrNoCredentials
+}
+
+func TestAuthenticate(t *testing.T) {
+	tokens, err := auth.NewJWT(config.Auth{HMACSecret: secret, TenantClaim: "tenant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		challenge     string
+	}{
+		{"valid token", bearer(t, "registrar@school.edu", time.Hour), http.StatusOK, ""},
+		{"no credentials", "", http.StatusUnauthorized, `Bearer realm="students-api"`},
+		{"another scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, `Bearer realm="students-api"`},
+		{"expired token", bearer(t, "registrar@school.edu", -time.Hour), http.StatusUnauthorized, `Bearer realm="students-api", error="invalid_token"`},
+		{"malformed token", "Bearer not-a-token", http.StatusUnauthorized, `Bearer realm="students-api", error="invalid_token"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var identity auth.Identity
+			var actor string
+			handler := middleware.Authenticate(tokens, cookieAuth{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				identity, _ = auth.FromContext(r.Context())
+				actor = audit.Actor(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != test.status {
+				t.Fatalf("got %d %s, want %d", res.Code, res.Body, test.status)
+			}
+			// The cookie authenticator has no scheme, so it adds no challenge
+			if got := res.Header().Values("WWW-Authenticate"); test.challenge != "" && (len(got) != 1 || got[0] != test.challenge) {
+				t.Fatalf("got WWW-Authenticate %q, want %q", got, test.challenge)
+			}
+
+			if test.status == http.StatusOK {
+				if identity.Subject != "registrar@school.edu" || actor != "registrar@school.edu" {
+					t.Fatalf("got identity %+v and actor %q, want the subject of the token", identity, actor)
+				}
+				return
+			}
+			var problem response.Problem
+			if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil || problem.Status != http.StatusUnauthorized {
+				t.Fatalf("got %s, want a 401 problem", res.Body)
+			}
+		})
+	}
+}
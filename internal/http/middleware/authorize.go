@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// Router is implemented by http.ServeMux, it tells which registered pattern a request matches
+type Router interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// Authorize returns a middleware that lets a request through only if the roles or the scopes of its client
+// grant the permission the policy requires for the route the router matches
+// It must run after Authenticate, a request without identity is rejected with 401 Unauthorized
+// and one lacking the permission with 403 Forbidden
+// Requests matching no route are passed on, so the router answers them with 404 or 405
+func Authorize(policy *rbac.Policy, router Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pattern := router.Handler(r)
+			if pattern == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity, ok := auth.FromContext(r.Context())
+			if !ok {
+				response.WriteError(w, r, http.StatusUnauthorized, fmt.Errorf("authentication required"))
+				return
+			}
+
+			permission := policy.Permission(pattern)
+			if !policy.Holds(identity.Roles, identity.Scopes, permission) {
+				slog.Warn("Access denied", slog.String("subject", identity.Subject), slog.String("route", pattern), slog.String("permission", permission))
+				response.WriteError(w, r, http.StatusForbidden, fmt.Errorf("permission %q is required", permission))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
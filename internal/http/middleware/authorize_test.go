@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/http/middleware"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+)
+
+func TestAuthorize(t *testing.T) {
+	policy, err := rbac.NewPolicy(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	router := http.NewServeMux()
+	router.Handle("GET /api/students/{id}", ok)
+	router.Handle("DELETE /api/students", ok)
+	router.Handle("GET /api/undeclared", ok)
+	handler := middleware.Authorize(policy, router)(router)
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		identity *auth.Identity // identity is nil for an unauthenticated request
+		status   int
+	}{
+		{"role granting the permission", http.MethodGet, "/api/students/7", &auth.Identity{Roles: []string{rbac.RoleTeacher}}, http.StatusOK},
+		{"scope granting the permission", http.MethodGet, "/api/students/7", &auth.Identity{Scopes: []string{"students:read"}}, http.StatusOK},
+		{"area wildcard scope", http.MethodGet, "/api/students/7", &auth.Identity{Scopes: []string{"students:*"}}, http.StatusOK},
+		{"role lacking the permission", http.MethodDelete, "/api/students", &auth.Identity{Roles: []string{rbac.RoleRegistrar}}, http.StatusForbidden},
+		{"no roles", http.MethodGet, "/api/students/7", &auth.Identity{}, http.StatusForbidden},
+		{"not authenticated", http.MethodGet, "/api/students/7", nil, http.StatusUnauthorized},
+		{"undeclared route without \"*\"", http.MethodGet, "/api/undeclared", &auth.Identity{Roles: []string{rbac.RoleRegistrar}}, http.StatusForbidden},
+		{"undeclared route with \"*\"", http.MethodGet, "/api/undeclared", &auth.Identity{Roles: []string{rbac.RoleAdmin}}, http.StatusOK},
+		// Requests matching no route are left to the router
+		{"unknown path", http.MethodGet, "/api/nowhere", nil, http.StatusNotFound},
+		{"unknown method", http.MethodPut, "/api/students", nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.target, nil)
+			if test.identity != nil {
+				req = req.WithContext(auth.WithIdentity(context.Background(), *test.identity))
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != test.status {
+				t.Fatalf("got %d %s, want %d", res.Code, res.Body, test.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+	"github.com/Priyang1310/Students-API-GO/internal/http/middleware"
+)
+
+// serve runs the middleware in front of a handler recording the actor and request ID it was given
+func serve(middleware func(http.Handler) http.Handler, req *http.Request) (actor string, requestID string, res *httptest.ResponseRecorder) {
+	res = httptest.NewRecorder()
+	middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor, requestID = audit.Actor(r.Context()), audit.RequestID(r.Context())
+	})).ServeHTTP(res, req)
+	return actor, requestID, res
+}
+
+func TestActor(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", audit.Anonymous},
+		{"registrar@school.edu", "registrar@school.edu"},
+		{"María José", "María José"},
+		{"evil\nactor", audit.Anonymous},
+		{strings.Repeat("a", 129), audit.Anonymous},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+		if tt.header != "" {
+			req.Header.Set(middleware.ActorHeader, tt.header)
+		}
+		if actor, _, _ := serve(middleware.Actor, req); actor != tt.want {
+			t.Errorf("X-Actor %q: got actor %q, want %q", tt.header, actor, tt.want)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	// A well-formed ID of the client is kept and echoed
+	req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+	req.Header.Set(middleware.RequestIDHeader, "proxy-42")
+	if _, id, res := serve(middleware.RequestID, req); id != "proxy-42" || res.Header().Get(middleware.RequestIDHeader) != "proxy-42" {
+		t.Fatalf("X-Request-ID proxy-42: got %q, echoed %q", id, res.Header().Get(middleware.RequestIDHeader))
+	}
+
+	// A malformed one is replaced by a random ID
+	req = httptest.NewRequest(http.MethodGet, "/api/students", nil)
+	req.Header.Set(middleware.RequestIDHeader, "has spaces")
+	_, id, res := serve(middleware.RequestID, req)
+	if id == "" || id == "has spaces" || res.Header().Get(middleware.RequestIDHeader) != id {
+		t.Fatalf("malformed X-Request-ID: got %q, echoed %q, want a new ID", id, res.Header().Get(middleware.RequestIDHeader))
+	}
+
+	_, other, _ := serve(middleware.RequestID, httptest.NewRequest(http.MethodGet, "/api/students", nil))
+	if other == "" || other == id {
+		t.Fatalf("generated request IDs %q and %q, want two different IDs", id, other)
+	}
+}
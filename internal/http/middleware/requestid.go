@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+)
+
+// RequestIDHeader is the header carrying the ID of a request, in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the length of a request ID chosen by the client
+const maxRequestIDLength = 128
+
+// RequestID returns a middleware that gives every request an ID
+// A well-formed X-Request-ID sent by the client (e.g. by a proxy) is kept, otherwise a random one is generated
+// The ID is echoed in the response and put into the request context, where the audit log picks it up
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(audit.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID reports whether a client supplied request ID is short and made of printable ASCII only
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128 bit request ID in hex
+func newRequestID() string {
+	var id [16]byte
+	// crypto/rand never fails on the supported platforms
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+)
+
+// Tenant returns a middleware that resolves the tenant of every request and carries it in the context,
+// where the storage backends read it to scope the students
+// It must run after Authenticate, so a client bound to a tenant cannot reach another one
+// and a client bound to none only reaches the default tenant, unless the policy grants it rbac.CrossTenant
+// Without authentication there is no client, every request may name its tenant
+// A request naming no tenant is rejected with 400 Bad Request, an unknown tenant with 404 Not Found
+// and a tenant the client does not belong to with 403 Forbidden
+func Tenant(resolver *tenant.Resolver, policy *rbac.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, ok := auth.FromContext(r.Context())
+			roaming := !ok || policy.Holds(identity.Roles, identity.Scopes, rbac.CrossTenant)
+
+			id, err := resolver.Resolve(r, identity.Tenant, roaming)
+			if err != nil {
+				switch {
+				case errors.Is(err, tenant.ErrTenantMismatch):
+					response.WriteError(w, r, http.StatusForbidden, err)
+				case errors.Is(err, tenant.ErrUnknownTenant):
+					response.WriteError(w, r, http.StatusNotFound, err)
+				default:
+					response.WriteError(w, r, http.StatusBadRequest, err)
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), id)))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/http/middleware"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+)
+
+func TestTenant(t *testing.T) {
+	policy, err := rbac.NewPolicy(map[string][]string{"operator": {"students:read", rbac.CrossTenant}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := tenant.NewResolver(config.Tenancy{
+		Header:  "X-Tenant-ID",
+		Domain:  "schools.example.edu",
+		Default: "default",
+		Tenants: map[string]config.Tenant{"default": {}, "north-high": {}, "south-academy": {}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		identity *auth.Identity // identity is nil when authentication is disabled
+		host     string
+		header   string
+		status   int
+		want     string
+	}{
+		{"claim", &auth.Identity{Tenant: "north-high"}, "api.example.edu", "", http.StatusOK, "north-high"},
+		{"claim naming its own tenant", &auth.Identity{Tenant: "north-high"}, "north-high.schools.example.edu", "", http.StatusOK, "north-high"},
+		{"claim asking for another tenant", &auth.Identity{Tenant: "north-high"}, "api.example.edu", "south-academy", http.StatusForbidden, ""},
+		{"claim asking for another subdomain", &auth.Identity{Tenant: "north-high"}, "south-academy.schools.example.edu", "", http.StatusForbidden, ""},
+		{"API key of a tenant", &auth.Identity{Tenant: "south-academy", Scopes: []string{"students:read"}}, "api.example.edu", "", http.StatusOK, "south-academy"},
+		{"no claim", &auth.Identity{Roles: []string{rbac.RoleRegistrar}}, "api.example.edu", "", http.StatusOK, "default"},
+		{"no claim asking for another tenant", &auth.Identity{Roles: []string{rbac.RoleRegistrar}}, "api.example.edu", "north-high", http.StatusForbidden, ""},
+		{"tenants:cross granted by a role", &auth.Identity{Roles: []string{"operator"}}, "api.example.edu", "north-high", http.StatusOK, "north-high"},
+		{"tenants:cross through \"*\"", &auth.Identity{Roles: []string{rbac.RoleAdmin}}, "south-academy.schools.example.edu", "", http.StatusOK, "south-academy"},
+		{"tenants:cross granted by a scope", &auth.Identity{Scopes: []string{rbac.CrossTenant}}, "api.example.edu", "north-high", http.StatusOK, "north-high"},
+		{"tenants:cross does not lift a claim", &auth.Identity{Tenant: "north-high", Roles: []string{rbac.RoleAdmin}}, "api.example.edu", "south-academy", http.StatusForbidden, ""},
+		{"unknown tenant", &auth.Identity{Roles: []string{rbac.RoleAdmin}}, "api.example.edu", "east-high", http.StatusNotFound, ""},
+		{"header contradicting the subdomain", &auth.Identity{Roles: []string{rbac.RoleAdmin}}, "north-high.schools.example.edu", "south-academy", http.StatusForbidden, ""},
+
+		// Without authentication there is no client to hold to a tenant, the request reaches the one it names
+		{"authentication disabled", nil, "api.example.edu", "south-academy", http.StatusOK, "south-academy"},
+		{"authentication disabled naming none", nil, "api.example.edu", "", http.StatusOK, "default"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+			handler := middleware.Tenant(resolver, policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = tenant.ID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+			req.Host = test.host
+			if test.header != "" {
+				req.Header.Set("X-Tenant-ID", test.header)
+			}
+			if test.identity != nil {
+				req = req.WithContext(auth.WithIdentity(context.Background(), *test.identity))
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != test.status || got != test.want {
+				t.Fatalf("got %d %s for tenant %q, want %d for %q", res.Code, res.Body, got, test.status, test.want)
+			}
+		})
+	}
+}
+
+func TestTenantRequired(t *testing.T) {
+	policy, err := rbac.NewPolicy(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := tenant.NewResolver(config.Tenancy{Header: "X-Tenant-ID", Tenants: map[string]config.Tenant{"north-high": {}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+	req = req.WithContext(auth.WithIdentity(context.Background(), auth.Identity{Roles: []string{rbac.RoleAdmin}}))
+	middleware.Tenant(resolver, policy)(http.NotFoundHandler()).ServeHTTP(res, req)
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("request naming no tenant without default: got %d %s, want 400", res.Code, res.Body)
+	}
+}
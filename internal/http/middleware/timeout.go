@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// QueryTimeout returns a middleware that puts a deadline of d on the request context
+// The handlers pass r.Context() to the storage, so every query of the request is
+// cancelled once the deadline passes or the client disconnects
+// A zero or negative duration disables the deadline
+// The routes the router matches with one of the exempt patterns get no deadline, e.g. file uploads and downloads,
+// which take as long as the client needs to send or read the file; they are still cancelled if the client disconnects
+func QueryTimeout(d time.Duration, router Router, exempt ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, pattern := router.Handler(r); slices.Contains(exempt, pattern) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Derive the deadline from the request context so a client disconnect still cancels early
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
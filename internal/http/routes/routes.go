@@ -0,0 +1,112 @@
+// Package routes registers the routes of the API, one function per subsystem
+// main registers them over the storage backend and the handler tests over their fakes,
+// so the tests exercise the very patterns the server serves
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Priyang1310/Students-API-GO/internal/grading"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/apikey"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/attachment"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/attendance"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/course"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/grade"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/guardian"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/reportcard"
+	"github.com/Priyang1310/Students-API-GO/internal/http/handlers/student"
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+	"github.com/Priyang1310/Students-API-GO/internal/report"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+)
+
+// Router is where the routes are registered, an *http.ServeMux in the server
+// Every pattern needs an entry in rbac.DefaultRoutes, a route missing there is reserved to admins
+type Router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// Students registers the student routes, served by every storage backend
+func Students(router Router, db storage.Storage) {
+	router.HandleFunc("POST /api/students", student.New(db))                  // Create a new student
+	router.HandleFunc("GET /api/students/search", student.Search(db))         // Search students by name and email
+	router.HandleFunc("GET /api/students/trash", student.Trash(db))           // List the deleted students that can still be restored
+	router.HandleFunc("GET /api/students/{id}", student.GetById(db))          // Get a student by ID
+	router.HandleFunc("GET /api/students", student.GetAll(db))                // Get all students
+	router.HandleFunc("PUT /api/students/{id}", student.Update(db))           // Update a student
+	router.HandleFunc("PATCH /api/students/{id}", student.Patch(db))          // Partially update a student (JSON Merge Patch)
+	router.HandleFunc("DELETE /api/students/{id}", student.DeleteById(db))    // Move a student to the trash
+	router.HandleFunc("DELETE /api/students", student.DeleteAll(db))          // Move all students to the trash
+	router.HandleFunc("POST /api/students/{id}/restore", student.Restore(db)) // Restore a deleted student from the trash
+}
+
+// History registers the student history route
+func History(router Router, history storage.AuditStorage) {
+	router.HandleFunc("GET /api/students/{id}/history", student.History(history)) // List the changes of a student
+}
+
+// APIKeys registers the routes issuing and revoking API keys
+// Only the scopes the policy grants the caller can be put on a new key
+func APIKeys(router Router, keys storage.APIKeyStorage, policy *rbac.Policy) {
+	router.HandleFunc("POST /api/api-keys", apikey.New(keys, policy))   // Issue an API key, the key is only returned once
+	router.HandleFunc("GET /api/api-keys", apikey.GetAll(keys))         // List the API keys
+	router.HandleFunc("DELETE /api/api-keys/{id}", apikey.Revoke(keys)) // Revoke an API key
+}
+
+// Courses registers the course catalog and enrollment routes
+func Courses(router Router, courses storage.CourseStorage) {
+	router.HandleFunc("POST /api/courses", course.New(courses))                          // Add a course to the catalog
+	router.HandleFunc("GET /api/courses", course.GetAll(courses))                        // List the course catalog
+	router.HandleFunc("GET /api/courses/{id}", course.GetById(courses))                  // Get a course by ID
+	router.HandleFunc("GET /api/courses/{id}/students", course.Students(courses))        // List the students enrolled in a course
+	router.HandleFunc("POST /api/students/{id}/enrollments", course.Enroll(courses))     // Enroll a student in a course
+	router.HandleFunc("GET /api/students/{id}/enrollments", course.Enrollments(courses)) // List the enrollments of a student
+}
+
+// Grades registers the grade and transcript routes
+// Letters and GPAs are computed with the grading scale of the tenant of the request
+func Grades(router Router, grades storage.GradeStorage, scales tenant.Settings[grading.Scale]) {
+	router.HandleFunc("POST /api/students/{id}/grades", grade.New(grades, scales))           // Record a grade of a student
+	router.HandleFunc("GET /api/students/{id}/grades", grade.GetAll(grades, scales))         // List the grades of a student
+	router.HandleFunc("GET /api/students/{id}/transcript", grade.Transcript(grades, scales)) // Get the transcript and GPA of a student
+}
+
+// Attendance registers the attendance routes
+func Attendance(router Router, records storage.AttendanceStorage) {
+	router.HandleFunc("POST /api/attendance", attendance.New(records))                                 // Submit the attendance sheet of a day
+	router.HandleFunc("GET /api/attendance/summary", attendance.Summary(records))                      // Attendance rates of every student
+	router.HandleFunc("GET /api/students/{id}/attendance", attendance.GetByStudent(records))           // Attendance records of a student
+	router.HandleFunc("GET /api/students/{id}/attendance/summary", attendance.StudentSummary(records)) // Attendance rate of a student
+}
+
+// Guardians registers the guardian and emergency contact routes
+func Guardians(router Router, guardians storage.GuardianStorage) {
+	router.HandleFunc("POST /api/students/{id}/guardians", guardian.New(guardians))                   // Add a new guardian to a student
+	router.HandleFunc("GET /api/students/{id}/guardians", guardian.GetAll(guardians))                 // List the guardians of a student
+	router.HandleFunc("POST /api/students/{id}/guardians/{guardianId}", guardian.Link(guardians))     // Link an existing guardian to a student
+	router.HandleFunc("GET /api/students/{id}/guardians/{guardianId}", guardian.GetById(guardians))   // Get a guardian of a student
+	router.HandleFunc("PUT /api/students/{id}/guardians/{guardianId}", guardian.Update(guardians))    // Update a guardian of a student
+	router.HandleFunc("DELETE /api/students/{id}/guardians/{guardianId}", guardian.Delete(guardians)) // Remove a guardian from a student
+	router.HandleFunc("GET /api/guardians/{id}/students", guardian.Students(guardians))               // List the students of a guardian
+}
+
+// ReportCards registers the report card route
+func ReportCards(router Router, source reportcard.Storage, scales tenant.Settings[grading.Scale], templates tenant.Settings[*report.Template]) {
+	router.HandleFunc("GET /api/students/{id}/report-card.pdf", reportcard.Get(source, scales, templates)) // Download the report card of a student
+}
+
+// AttachmentTransfers are the routes sending or receiving the content of an attachment,
+// they are exempt from the storage query timeout since they last as long as the client needs to transfer the file
+var AttachmentTransfers = []string{
+	"POST /api/students/{id}/attachments",
+	"GET /api/students/{id}/attachments/{attachmentId}",
+}
+
+// Attachments registers the attachment routes
+func Attachments(router Router, attachments storage.AttachmentStorage, limits tenant.Settings[attachment.Limits]) {
+	router.HandleFunc("POST /api/students/{id}/attachments", attachment.New(attachments, limits))             // Upload a file for a student
+	router.HandleFunc("GET /api/students/{id}/attachments", attachment.GetAll(attachments))                   // List the attachments of a student
+	router.HandleFunc("GET /api/students/{id}/attachments/{attachmentId}", attachment.Download(attachments))  // Download an attachment, Range requests supported
+	router.HandleFunc("DELETE /api/students/{id}/attachments/{attachmentId}", attachment.Delete(attachments)) // Delete an attachment
+}
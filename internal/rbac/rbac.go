@@ -0,0 +1,212 @@
+package rbac
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+// The roles of the default policy
+const (
+	RoleAdmin     = "admin"
+	RoleRegistrar = "registrar"
+	RoleTeacher   = "teacher"
+	RoleReadOnly  = "read-only"
+)
+
+// All is the permission granting every other one
+const All = "*"
+
+// CrossTenant is the permission letting a client bound to no tenant reach any tenant, not only the default one
+// It is not required by a route, the tenant middleware checks it
+const CrossTenant = "tenants:cross"
+
+// DefaultRoles are the permissions of the built-in roles, the roles of the config override them one by one
+// A permission ending in ":*" grants every permission of that area, e.g. "students:*"
+var DefaultRoles = map[string][]string{
+	RoleAdmin: {All},
+	RoleRegistrar: {
+		"students:read", "students:write", "students:delete", "students:trash", "students:history",
+		"courses:*", "enrollments:write", "grades:read", "attendance:read",
+		"guardians:*", "attachments:*",
+	},
+	RoleTeacher: {
+		"students:read", "courses:read", "grades:read", "attendance:read", "guardians:read", "attachments:read",
+	},
+	RoleReadOnly: {
+		"students:read", "courses:read",
+	},
+}
+
+// DefaultRoutes are the permissions required by every route, keyed by their pattern in main.go,
+// the routes of the config override them one by one
+var DefaultRoutes = map[string]string{
+	"POST /api/students":              "students:write",
+	"GET /api/students/search":        "students:read",
+	"GET /api/students/trash":         "students:trash",
+	"GET /api/students/{id}":          "students:read",
+	"GET /api/students":               "students:read",
+	"PUT /api/students/{id}":          "students:write",
+	"PATCH /api/students/{id}":        "students:write",
+	"DELETE /api/students/{id}":       "students:delete",
+	"DELETE /api/students":            "students:delete_all",
+	"POST /api/students/{id}/restore": "students:trash",
+	"GET /api/students/{id}/history":  "students:history",
+
+	"POST /api/courses":                   "courses:write",
+	"GET /api/courses":                    "courses:read",
+	"GET /api/courses/{id}":               "courses:read",
+	"GET /api/courses/{id}/students":      "courses:read",
+	"POST /api/students/{id}/enrollments": "enrollments:write",
+	"GET /api/students/{id}/enrollments":  "courses:read",
+
+	"POST /api/students/{id}/grades":         "grades:write",
+	"GET /api/students/{id}/grades":          "grades:read",
+	"GET /api/students/{id}/transcript":      "grades:read",
+	"GET /api/students/{id}/report-card.pdf": "grades:read",
+
+	"POST /api/attendance":                      "attendance:write",
+	"GET /api/attendance/summary":               "attendance:read",
+	"GET /api/students/{id}/attendance":         "attendance:read",
+	"GET /api/students/{id}/attendance/summary": "attendance:read",
+
+	"POST /api/students/{id}/guardians":                "guardians:write",
+	"GET /api/students/{id}/guardians":                 "guardians:read",
+	"POST /api/students/{id}/guardians/{guardianId}":   "guardians:write",
+	"GET /api/students/{id}/guardians/{guardianId}":    "guardians:read",
+	"PUT /api/students/{id}/guardians/{guardianId}":    "guardians:write",
+	"DELETE /api/students/{id}/guardians/{guardianId}": "guardians:write",
+	"GET /api/guardians/{id}/students":                 "guardians:read",
+
+	"POST /api/students/{id}/attachments":                  "attachments:write",
+	"GET /api/students/{id}/attachments":                   "attachments:read",
+	"GET /api/students/{id}/attachments/{attachmentId}":    "attachments:read",
+	"DELETE /api/students/{id}/attachments/{attachmentId}": "attachments:write",
+
+	"POST /api/api-keys":        "apikeys:manage",
+	"GET /api/api-keys":         "apikeys:manage",
+	"DELETE /api/api-keys/{id}": "apikeys:manage",
+}
+
+// Policy maps route patterns to the permission they require and roles to the permissions they grant
+// A route missing from the policy requires the All permission, so a new route is closed until it is declared
+type Policy struct {
+	roles  map[string][]string
+	routes map[string]string
+}
+
+// NewPolicy function builds a Policy from the roles and routes of the config, laid over DefaultRoles and DefaultRoutes
+// A role or route of the config replaces the built-in one of the same name, a role listing no permission grants nothing
+// It returns an error if a permission is empty, a wildcard is misplaced or a route requires a permission no role grants
+func NewPolicy(roleOverrides map[string][]string, routeOverrides map[string]string) (*Policy, error) {
+	roles := maps.Clone(DefaultRoles)
+	maps.Copy(roles, roleOverrides)
+	routes := maps.Clone(DefaultRoutes)
+	maps.Copy(routes, routeOverrides)
+
+	for role, permissions := range roles {
+		if strings.TrimSpace(role) == "" {
+			return nil, fmt.Errorf("rbac: a role has no name")
+		}
+		for _, permission := range permissions {
+			if err := checkPermission(permission, true); err != nil {
+				return nil, fmt.Errorf("rbac: role %q: %w", role, err)
+			}
+		}
+	}
+
+	policy := &Policy{roles: roles, routes: routes}
+
+	// Report every route nobody may call, it is most likely a typo in the config
+	var unreachable []string
+	for pattern, permission := range routes {
+		if err := checkPermission(permission, false); err != nil {
+			return nil, fmt.Errorf("rbac: route %q: %w", pattern, err)
+		}
+		if !policy.granted(permission) {
+			unreachable = append(unreachable, fmt.Sprintf("%q (%s)", pattern, permission))
+		}
+	}
+	if len(unreachable) > 0 {
+		sort.Strings(unreachable)
+		return nil, fmt.Errorf("rbac: no role grants the permission of route %s", strings.Join(unreachable, ", "))
+	}
+
+	return policy, nil
+}
+
+// checkPermission reports whether a permission is well formed, wildcards are only allowed in grants
+func checkPermission(permission string, grant bool) error {
+	switch {
+	case strings.TrimSpace(permission) == "":
+		return fmt.Errorf("empty permission")
+	case !grant && strings.Contains(permission, "*"):
+		return fmt.Errorf("permission %q: wildcards can only be granted, not required", permission)
+	case permission != All && strings.Contains(strings.TrimSuffix(permission, ":*"), "*"):
+		return fmt.Errorf("permission %q: a wildcard must be \"*\" or end the permission as \":*\"", permission)
+	}
+	return nil
+}
+
+// Permission returns the permission required by the route with the given pattern
+func (p *Policy) Permission(pattern string) string {
+	if permission, ok := p.routes[pattern]; ok {
+		return permission
+	}
+	return All
+}
+
+// Allowed reports whether any of the roles grants the permission
+func (p *Policy) Allowed(roles []string, permission string) bool {
+	for _, role := range roles {
+		for _, grant := range p.roles[role] {
+			if grants(grant, permission) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Covers reports whether any of the permissions granted directly to a client, e.g. the scopes of an API key, grants the permission
+func Covers(granted []string, permission string) bool {
+	for _, grant := range granted {
+		if grants(grant, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Holds reports whether a client with the given roles and scopes holds the permission
+// The permission may be a wildcard, e.g. "students:*" is only held through "students:*" or "*"
+func (p *Policy) Holds(roles []string, scopes []string, permission string) bool {
+	return p.Allowed(roles, permission) || Covers(scopes, permission)
+}
+
+// CheckGrant reports whether a permission can be granted, wildcards included
+func CheckGrant(permission string) error {
+	return checkPermission(permission, true)
+}
+
+// granted reports whether at least one role grants the permission
+func (p *Policy) granted(permission string) bool {
+	for role := range p.roles {
+		if p.Allowed([]string{role}, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// grants reports whether a granted permission covers the required one
+func grants(grant string, permission string) bool {
+	if grant == All || grant == permission {
+		return true
+	}
+	if area, ok := strings.CutSuffix(grant, ":*"); ok {
+		return strings.HasPrefix(permission, area+":")
+	}
+	return false
+}
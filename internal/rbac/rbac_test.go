@@ -0,0 +1,160 @@
+package rbac_test
+
+import (
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/rbac"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	policy, err := rbac.NewPolicy(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		role    string
+		pattern string
+		want    bool
+	}{
+		{rbac.RoleAdmin, "DELETE /api/students", true},
+		{rbac.RoleAdmin, "GET /api/unknown", true},
+		{rbac.RoleRegistrar, "POST /api/students", true},
+		{rbac.RoleRegistrar, "POST /api/courses", true},                                // through "courses:*"
+		{rbac.RoleRegistrar, "DELETE /api/students/{id}/guardians/{guardianId}", true}, // through "guardians:*"
+		{rbac.RoleRegistrar, "DELETE /api/students", false},
+		{rbac.RoleRegistrar, "POST /api/students/{id}/grades", false},
+		{rbac.RoleTeacher, "GET /api/students/{id}/transcript", true},
+		{rbac.RoleTeacher, "POST /api/students", false},
+		{rbac.RoleReadOnly, "GET /api/students", true},
+		{rbac.RoleReadOnly, "GET /api/students/{id}/grades", false},
+		// A route missing from the policy requires "*", which only the admin holds
+		{rbac.RoleRegistrar, "GET /api/unknown", false},
+		{"unknown-role", "GET /api/students", false},
+	}
+
+	for _, test := range tests {
+		permission := policy.Permission(test.pattern)
+		if got := policy.Allowed([]string{test.role}, permission); got != test.want {
+			t.Errorf("%s calling %q (%s): got %v, want %v", test.role, test.pattern, permission, got, test.want)
+		}
+	}
+
+	if permission := policy.Permission("GET /api/unknown"); permission != rbac.All {
+		t.Errorf("unknown route: got permission %q, want %q", permission, rbac.All)
+	}
+}
+
+func TestWildcards(t *testing.T) {
+	tests := []struct {
+		granted    string
+		permission string
+		want       bool
+	}{
+		{"*", "students:read", true},
+		{"*", rbac.CrossTenant, true},
+		{"*", rbac.All, true},
+		{"students:*", "students:read", true},
+		{"students:*", "students:delete_all", true},
+		{"students:*", "students:*", true},
+		{"students:*", "studentsx:read", false},
+		{"students:*", "courses:read", false},
+		{"students:*", rbac.All, false},
+		{"students:read", "students:read", true},
+		{"students:read", "students:write", false},
+		{"students:read", "students:*", false},
+	}
+
+	for _, test := range tests {
+		if got := rbac.Covers([]string{test.granted}, test.permission); got != test.want {
+			t.Errorf("%q covering %q: got %v, want %v", test.granted, test.permission, got, test.want)
+		}
+	}
+}
+
+func TestConfigOverridesDefaults(t *testing.T) {
+	policy, err := rbac.NewPolicy(
+		map[string][]string{
+			rbac.RoleTeacher: {"students:read", "grades:*"},
+			"auditor":        {"students:history"},
+		},
+		map[string]string{
+			"DELETE /api/students": "students:delete",
+			"GET /api/reports":     "students:read",
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		role    string
+		pattern string
+		want    bool
+	}{
+		{rbac.RoleTeacher, "POST /api/students/{id}/grades", true},     // granted by the override
+		{rbac.RoleTeacher, "GET /api/students/{id}/attendance", false}, // the override replaces the built-in role
+		{rbac.RoleRegistrar, "POST /api/students", true},               // a built-in role left alone
+		{rbac.RoleRegistrar, "DELETE /api/students", true},             // a route overridden
+		{rbac.RoleReadOnly, "GET /api/reports", true},                  // a route added
+		{"auditor", "GET /api/students/{id}/history", true},            // a role added
+		{"auditor", "GET /api/students", false},
+	}
+
+	for _, test := range tests {
+		if got := policy.Allowed([]string{test.role}, policy.Permission(test.pattern)); got != test.want {
+			t.Errorf("%s calling %q: got %v, want %v", test.role, test.pattern, got, test.want)
+		}
+	}
+
+	// The defaults themselves are left untouched
+	if rbac.DefaultRoutes["DELETE /api/students"] != "students:delete_all" || len(rbac.DefaultRoles[rbac.RoleTeacher]) != 6 {
+		t.Fatal("NewPolicy changed the built-in roles or routes")
+	}
+}
+
+func TestNewPolicyRejectsBadPermissions(t *testing.T) {
+	tests := []struct {
+		name   string
+		roles  map[string][]string
+		routes map[string]string
+	}{
+		{"role without name", map[string][]string{" ": {"students:read"}}, nil},
+		{"empty grant", map[string][]string{"clerk": {""}}, nil},
+		{"wildcard in the middle", map[string][]string{"clerk": {"students:*:read"}}, nil},
+		{"wildcard prefix", map[string][]string{"clerk": {"*:read"}}, nil},
+		{"route requiring a wildcard", nil, map[string]string{"GET /api/students": "students:*"}},
+		{"route requiring nothing", nil, map[string]string{"GET /api/students": ""}},
+		// With the admin holding "*" every route can be called, without it a typo leaves the route unreachable
+		{"route nobody may call", map[string][]string{rbac.RoleAdmin: {"students:*", "courses:*", "enrollments:*", "grades:*", "attendance:*", "guardians:*", "attachments:*", "apikeys:*"}},
+			map[string]string{"GET /api/students": "student:read"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := rbac.NewPolicy(test.roles, test.routes); err == nil {
+				t.Fatal("got no error")
+			}
+		})
+	}
+}
+
+func TestHolds(t *testing.T) {
+	policy, err := rbac.NewPolicy(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !policy.Holds(nil, []string{"students:read"}, "students:read") {
+		t.Error("a scope granting the permission: got false")
+	}
+	if !policy.Holds([]string{rbac.RoleReadOnly}, nil, "courses:read") {
+		t.Error("a role granting the permission: got false")
+	}
+	if policy.Holds([]string{rbac.RoleRegistrar}, []string{"students:read"}, rbac.CrossTenant) {
+		t.Error("tenants:cross without \"*\": got true")
+	}
+	if !policy.Holds([]string{rbac.RoleAdmin}, nil, rbac.CrossTenant) {
+		t.Error("tenants:cross through \"*\": got false")
+	}
+}
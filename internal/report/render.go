@@ -0,0 +1,136 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// lineHeight is the height of a table row in millimetres
+const lineHeight = 7
+
+// renderer draws the parts of a report card on a PDF document
+type renderer struct {
+	pdf    *gofpdf.Fpdf
+	family string              // family is the font family in use
+	text   func(string) string // text converts UTF-8 text to the encoding of the font
+}
+
+// font selects the font family of the renderer in the given style and size
+func (r *renderer) font(style string, size float64) {
+	r.pdf.SetFont(r.family, style, size)
+}
+
+// header draws the logo, the school name and the title
+func (r *renderer) header(school string, title string, logoPath string) {
+	if logoPath != "" {
+		r.pdf.ImageOptions(logoPath, 10, 10, 0, 20, false, gofpdf.ImageOptions{ReadDpi: true}, 0, "")
+		r.pdf.SetX(35)
+	}
+
+	r.font("B", 12)
+	r.pdf.CellFormat(0, 8, r.text(school), "", 1, "L", false, 0, "")
+	if logoPath != "" {
+		r.pdf.SetX(35)
+	}
+	r.font("B", 16)
+	r.pdf.CellFormat(0, 10, r.text(title), "", 1, "L", false, 0, "")
+	r.pdf.Ln(8)
+}
+
+// heading draws the heading of a section
+func (r *renderer) heading(text string) {
+	r.font("B", 13)
+	r.pdf.CellFormat(0, 9, r.text(text), "B", 1, "L", false, 0, "")
+	r.pdf.Ln(2)
+}
+
+// table draws a table with a shaded header row, widths are in millimetres
+func (r *renderer) table(widths []float64, header []string, rows [][]string) {
+	r.font("B", 10)
+	r.pdf.SetFillColor(230, 230, 230)
+	for i, cell := range header {
+		r.pdf.CellFormat(widths[i], lineHeight, r.text(cell), "1", 0, "L", true, 0, "")
+	}
+	r.pdf.Ln(-1)
+
+	r.font("", 10)
+	for _, row := range rows {
+		for i, cell := range row {
+			r.pdf.CellFormat(widths[i], lineHeight, r.text(cell), "1", 0, "L", false, 0, "")
+		}
+		r.pdf.Ln(-1)
+	}
+	r.pdf.Ln(6)
+}
+
+// note draws a line of plain text
+func (r *renderer) note(text string) {
+	r.font("", 10)
+	r.pdf.CellFormat(0, lineHeight, r.text(text), "", 1, "L", false, 0, "")
+	r.pdf.Ln(4)
+}
+
+// details draws the student's details
+func (r *renderer) details(student types.Student) {
+	r.heading("Student")
+	r.table([]float64{40, 150}, []string{"Field", "Value"}, [][]string{
+		{"ID", strconv.FormatInt(student.Id, 10)},
+		{"Name", student.Name},
+		{"Email", student.Email},
+		{"Age", strconv.Itoa(student.Age)},
+	})
+}
+
+// enrollments draws the courses the student is enrolled in
+func (r *renderer) enrollments(enrollments []EnrolledCourse) {
+	r.heading("Enrollments")
+	if len(enrollments) == 0 {
+		r.note("Not enrolled in any course.")
+		return
+	}
+
+	rows := make([][]string, len(enrollments))
+	for i, enrollment := range enrollments {
+		rows[i] = []string{
+			enrollment.Course.Code,
+			enrollment.Course.Title,
+			strconv.Itoa(enrollment.Course.Credits),
+			enrollment.EnrolledAt.Format("2006-01-02"),
+		}
+	}
+	r.table([]float64{30, 100, 25, 35}, []string{"Code", "Course", "Credits", "Enrolled on"}, rows)
+}
+
+// grades draws the grades of every term with the term and cumulative GPAs
+func (r *renderer) grades(transcript types.Transcript) {
+	r.heading("Grades")
+	if len(transcript.Terms) == 0 {
+		r.note("No grades recorded yet.")
+		return
+	}
+
+	for _, term := range transcript.Terms {
+		r.font("B", 11)
+		r.pdf.CellFormat(0, 8, r.text("Term "+term.Term), "", 1, "L", false, 0, "")
+
+		rows := make([][]string, len(term.Grades))
+		for i, grade := range term.Grades {
+			rows[i] = []string{
+				grade.Course.Code,
+				grade.Course.Title,
+				strconv.Itoa(grade.Course.Credits),
+				strconv.FormatFloat(*grade.Score, 'f', -1, 64),
+				grade.Letter,
+				fmt.Sprintf("%.2f", grade.Points),
+			}
+		}
+		r.table([]float64{25, 75, 20, 20, 20, 30}, []string{"Code", "Course", "Credits", "Score", "Grade", "Points"}, rows)
+		r.note(fmt.Sprintf("Term GPA: %.2f over %d credits", term.GPA, term.Credits))
+	}
+
+	r.font("B", 12)
+	r.pdf.CellFormat(0, 9, r.text(fmt.Sprintf("Cumulative GPA: %.2f over %d credits", transcript.GPA, transcript.Credits)), "T", 1, "L", false, 0, "")
+}
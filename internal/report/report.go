@@ -0,0 +1,167 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Sections that can be listed in the report card template
+const (
+	SectionDetails     = "details"
+	SectionEnrollments = "enrollments"
+	SectionGrades      = "grades"
+)
+
+// pageSizes lists the accepted paper sizes
+var pageSizes = map[string]bool{"A4": true, "Letter": true, "Legal": true}
+
+// EnrolledCourse is a course a student is enrolled in, as printed on the report card
+type EnrolledCourse struct {
+	Course     types.Course
+	EnrolledAt time.Time
+}
+
+// Data holds everything printed on a report card
+type Data struct {
+	School      string           // School is the configured school name
+	Student     types.Student    // Student is the student the report card is about
+	Enrollments []EnrolledCourse // Enrollments are the courses the student is enrolled in
+	Transcript  types.Transcript // Transcript holds the grades and GPAs of the student
+	GeneratedAt string           // GeneratedAt is the day the report card was generated (YYYY-MM-DD)
+}
+
+// Template renders report cards as PDF documents
+// It is built once from the configuration and is safe for concurrent use
+type Template struct {
+	school   string
+	title    *template.Template
+	footer   *template.Template
+	pageSize string
+	sections []string
+	logoPath string
+	fontPath string
+}
+
+// New function builds a Template from the report card configuration
+// It returns an error if a text template does not parse, the page size or a section is unknown,
+// or the logo or font file cannot be read
+func New(cfg config.ReportCard) (*Template, error) {
+	title, err := template.New("title").Parse(cfg.Title)
+	if err != nil {
+		return nil, fmt.Errorf("report card title: %w", err)
+	}
+
+	footer, err := template.New("footer").Parse(cfg.Footer)
+	if err != nil {
+		return nil, fmt.Errorf("report card footer: %w", err)
+	}
+
+	if !pageSizes[cfg.PageSize] {
+		return nil, fmt.Errorf("report card page size %q is not one of A4, Letter or Legal", cfg.PageSize)
+	}
+
+	for _, section := range cfg.Sections {
+		switch section {
+		case SectionDetails, SectionEnrollments, SectionGrades:
+		default:
+			return nil, fmt.Errorf("report card section %q is not one of details, enrollments or grades", section)
+		}
+	}
+
+	// Fail at startup rather than on the first request if a file is missing
+	for _, path := range []string{cfg.LogoPath, cfg.FontPath} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("report card: %w", err)
+		}
+	}
+
+	return &Template{
+		school:   cfg.School,
+		title:    title,
+		footer:   footer,
+		pageSize: cfg.PageSize,
+		sections: cfg.Sections,
+		logoPath: cfg.LogoPath,
+		fontPath: cfg.FontPath,
+	}, nil
+}
+
+// Render writes the report card of data as a PDF document to w
+// Nothing is written if the document cannot be generated
+func (t *Template) Render(w io.Writer, data Data) error {
+	data.School = t.school
+
+	title, err := execute(t.title, data)
+	if err != nil {
+		return err
+	}
+	footer, err := execute(t.footer, data)
+	if err != nil {
+		return err
+	}
+
+	pdf := gofpdf.New("P", "mm", t.pageSize, "")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator(t.school, true)
+	pdf.AliasNbPages("")
+
+	// Core fonts only cover Latin-1, a configured TrueType font covers whatever it has glyphs for
+	r := &renderer{pdf: pdf, family: "Helvetica", text: pdf.UnicodeTranslatorFromDescriptor("")}
+	if t.fontPath != "" {
+		pdf.AddUTF8Font("custom", "", t.fontPath)
+		pdf.AddUTF8Font("custom", "B", t.fontPath)
+		r.family = "custom"
+		r.text = func(s string) string { return s }
+	}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		r.font("", 8)
+		pdf.CellFormat(0, 10, r.text(footer), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	r.header(t.school, title, t.logoPath)
+
+	for _, section := range t.sections {
+		switch section {
+		case SectionDetails:
+			r.details(data.Student)
+		case SectionEnrollments:
+			r.enrollments(data.Enrollments)
+		case SectionGrades:
+			r.grades(data.Transcript)
+		}
+	}
+
+	// Render into memory first, so a failure never leaves a truncated document behind
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// execute runs a text template against the report card data
+func execute(tmpl *template.Template, data Data) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("report card %s: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
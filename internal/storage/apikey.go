@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// APIKeyStorage interface defines the methods of the API keys subsystem
+// Keys are looked up by the hash of the key, the key itself is never stored
+// It is optional: the server only accepts API keys and exposes their routes when the storage backend implements it
+type APIKeyStorage interface {
+	CreateAPIKey(ctx context.Context, key types.APIKey, hash string) (types.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]types.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (types.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (types.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int64, at time.Time) error
+}
+
+// APIKeyNotFound returns the error reported when no API key has the given ID, it wraps ErrNotFound
+func APIKeyNotFound(id int64) error {
+	return fmt.Errorf("api key not found with id %d: %w", id, ErrNotFound)
+}
+
+// UnknownAPIKey returns the error reported when no API key has the given hash, it wraps ErrNotFound
+func UnknownAPIKey() error {
+	return fmt.Errorf("api key %w", ErrNotFound)
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// AttachmentStorage interface defines the methods of the attachments subsystem
+// The content of an attachment is kept in a blob directory, its metadata next to the student
+// Deleting a student only moves it to the trash, its attachments are kept and out of reach until it is restored,
+// both are removed when the trash is purged
+// It is optional: the server only exposes the attachment routes when the storage backend implements it
+type AttachmentStorage interface {
+	CreateAttachment(ctx context.Context, studentId int64, fileName string, contentType string, content io.Reader) (types.Attachment, error)
+	GetStudentAttachments(ctx context.Context, studentId int64) ([]types.Attachment, error)
+	OpenAttachment(ctx context.Context, studentId int64, id int64) (types.Attachment, *os.File, error)
+	DeleteAttachment(ctx context.Context, studentId int64, id int64) error
+}
+
+// AttachmentNotFound returns the error reported when the student has no attachment with the given ID, it wraps ErrNotFound
+func AttachmentNotFound(studentId int64, id int64) error {
+	return fmt.Errorf("attachment %d of student %d: %w", id, studentId, ErrNotFound)
+}
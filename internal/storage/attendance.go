@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"math"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// AttendanceStorage interface defines the methods of the attendance subsystem
+// It is optional: the server only exposes the attendance routes when the storage backend implements it
+// Dates are YYYY-MM-DD strings and ranges include both ends
+type AttendanceStorage interface {
+	RecordAttendance(ctx context.Context, date string, records []types.Attendance) ([]types.Attendance, error)
+	GetStudentAttendance(ctx context.Context, studentId int64, from string, to string) ([]types.Attendance, error)
+	SummarizeAttendance(ctx context.Context, studentId int64, from string, to string) ([]types.AttendanceSummary, error)
+}
+
+// AttendanceRate returns the share of the counted days of a summary the student attended, rounded to four decimals
+// Present and late days count as attended, excused days are left out, and a summary without counted days has a rate of 0
+func AttendanceRate(summary types.AttendanceSummary) float64 {
+	counted := summary.Present + summary.Late + summary.Absent
+	if counted == 0 {
+		return 0
+	}
+	return math.Round(float64(summary.Present+summary.Late)/float64(counted)*10000) / 10000
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// AuditStorage interface defines the methods of the student history subsystem
+// A backend implementing it appends an entry to the history of a student in the same transaction
+// as every create, update, delete and restore, taking the actor and request ID from the context
+// (see the audit package)
+// It is optional: the server only exposes the history routes when the storage backend implements it
+type AuditStorage interface {
+	GetStudentHistory(ctx context.Context, id int64) ([]types.AuditEntry, error)
+	GetStudentAsOf(ctx context.Context, id int64, at time.Time) (types.Student, error)
+}
+
+// StudentNotFoundAt returns the error reported when a student did not exist at the given time, it wraps ErrNotFound
+func StudentNotFoundAt(id int64, at time.Time) error {
+	return fmt.Errorf("student %w with id %d as of %s", ErrNotFound, id, at.Format(time.RFC3339))
+}
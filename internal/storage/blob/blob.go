@@ -0,0 +1,90 @@
+package blob
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Store keeps files in a local directory under random keys
+// Files are spread over subdirectories named after the first two characters of their key
+type Store struct {
+	dir string
+}
+
+// Blob describes a file written to the Store
+type Blob struct {
+	Key      string // Key identifies the file in the Store
+	Size     int64  // Size is the length of the file in bytes
+	Checksum string // Checksum is the hex encoded SHA-256 of the content
+}
+
+// New function opens the Store rooted at dir, creating the directory if needed
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// path returns the file path of a key
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
+
+// Put writes the content to a new file and returns its key, size and checksum
+// The file only appears under its key once it has been written completely
+func (s *Store) Put(content io.Reader) (Blob, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return Blob{}, err
+	}
+	key := hex.EncodeToString(random)
+
+	if err := os.MkdirAll(filepath.Dir(s.path(key)), 0o750); err != nil {
+		return Blob{}, err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return Blob{}, err
+	}
+
+	// Remove the temporary file unless it was renamed into place
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), content)
+	if err != nil {
+		return Blob{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return Blob{}, err
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return Blob{}, err
+	}
+
+	return Blob{Key: key, Size: size, Checksum: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// Open opens the file of a key for reading, the caller must close it
+func (s *Store) Open(key string) (*os.File, error) {
+	return os.Open(s.path(key))
+}
+
+// Delete removes the file of a key, a missing file is not an error
+func (s *Store) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
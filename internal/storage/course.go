@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// ErrCourseFull is returned when enrolling in a course that has reached its capacity
+var ErrCourseFull = errors.New("course is full")
+
+// CourseStorage interface defines the methods of the course catalog and its enrollments
+// It is optional: the server only exposes the course routes when the storage backend implements it
+type CourseStorage interface {
+	CreateCourse(ctx context.Context, code string, title string, credits int, capacity int) (int64, error)
+	GetCourseById(ctx context.Context, id int64) (types.Course, error)
+	GetAllCourses(ctx context.Context) ([]types.Course, error)
+	EnrollStudent(ctx context.Context, studentId int64, courseId int64) (types.Enrollment, error)
+	GetStudentEnrollments(ctx context.Context, studentId int64) ([]types.Enrollment, error)
+	GetCourseStudents(ctx context.Context, courseId int64) ([]types.Student, error)
+}
+
+// CourseNotFound returns the error reported when no course has the given ID, it wraps ErrNotFound
+func CourseNotFound(id int64) error {
+	return fmt.Errorf("course not found with id %d: %w", id, ErrNotFound)
+}
+
+// CourseCodeTaken returns the error reported when another course already uses the code, it wraps ErrConflict
+func CourseCodeTaken(code string) error {
+	return fmt.Errorf("course with code %s %w", code, ErrConflict)
+}
+
+// AlreadyEnrolled returns the error reported when a student enrolls twice in a course, it wraps ErrConflict
+func AlreadyEnrolled(studentId int64, courseId int64) error {
+	return fmt.Errorf("student %d is already enrolled in course %d: %w", studentId, courseId, ErrConflict)
+}
+
+// CourseFull returns the error reported when a course has no seat left, it wraps ErrCourseFull
+func CourseFull(id int64, capacity int) error {
+	return fmt.Errorf("course %d has no seat left (capacity %d): %w", id, capacity, ErrCourseFull)
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// ErrNotEnrolled is returned when grading a student in a course they are not enrolled in
+var ErrNotEnrolled = errors.New("student is not enrolled in the course")
+
+// GradeStorage interface defines the methods of the grades subsystem
+// It is optional: the server only exposes the grade routes when the storage backend implements it
+type GradeStorage interface {
+	RecordGrade(ctx context.Context, studentId int64, courseId int64, term string, termStart string, score float64) (types.Grade, error)
+	GetStudentGrades(ctx context.Context, studentId int64) ([]types.CourseGrade, error)
+}
+
+// NotEnrolled returns the error reported when grading a student in a course they are not enrolled in, it wraps ErrNotEnrolled
+func NotEnrolled(studentId int64, courseId int64) error {
+	return fmt.Errorf("%w (student %d, course %d)", ErrNotEnrolled, studentId, courseId)
+}
+
+// GradeExists returns the error reported when a student already has a grade in a course for the term, it wraps ErrConflict
+func GradeExists(studentId int64, courseId int64, term string) error {
+	return fmt.Errorf("grade of student %d in course %d for term %s %w", studentId, courseId, term, ErrConflict)
+}
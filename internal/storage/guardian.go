@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// GuardianStorage interface defines the methods of the guardians subsystem
+// Guardians and students are linked many-to-many, a guardian is deleted with their last link
+// It is optional: the server only exposes the guardian routes when the storage backend implements it
+type GuardianStorage interface {
+	CreateGuardian(ctx context.Context, studentId int64, guardian types.Guardian) (types.Guardian, error)
+	LinkGuardian(ctx context.Context, studentId int64, guardianId int64, link types.GuardianLink) (types.Guardian, error)
+	GetStudentGuardians(ctx context.Context, studentId int64) ([]types.Guardian, error)
+	GetStudentGuardian(ctx context.Context, studentId int64, guardianId int64) (types.Guardian, error)
+	UpdateStudentGuardian(ctx context.Context, studentId int64, guardian types.Guardian) (types.Guardian, error)
+	UnlinkGuardian(ctx context.Context, studentId int64, guardianId int64) error
+	GetGuardianStudents(ctx context.Context, guardianId int64) ([]types.Student, error)
+}
+
+// GuardianNotFound returns the error reported when no guardian has the given ID, it wraps ErrNotFound
+func GuardianNotFound(id int64) error {
+	return fmt.Errorf("guardian not found with id %d: %w", id, ErrNotFound)
+}
+
+// GuardianNotLinked returns the error reported when a guardian is not linked to the student, it wraps ErrNotFound
+func GuardianNotLinked(studentId int64, guardianId int64) error {
+	return fmt.Errorf("guardian %d of student %d: %w", guardianId, studentId, ErrNotFound)
+}
+
+// GuardianAlreadyLinked returns the error reported when linking a guardian to a student twice, it wraps ErrConflict
+func GuardianAlreadyLinked(studentId int64, guardianId int64) error {
+	return fmt.Errorf("link between guardian %d and student %d %w", guardianId, studentId, ErrConflict)
+}
@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+const (
+	// DefaultLimit is the page size used when a listing does not ask for one
+	DefaultLimit = 20
+	// MaxLimit is the largest page size a listing may ask for
+	MaxLimit = 100
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded or was issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// sortable lists the student fields that may be used in a sort
+var sortable = map[string]bool{"id": true, "name": true, "email": true, "age": true}
+
+// SortField is one key of a student listing's sort order
+type SortField struct {
+	Field string // Field is the column to sort on ("id", "name", "email" or "age")
+	Desc  bool   // Desc sorts in descending order
+}
+
+// ListOptions describes which page of students to return
+// The zero value returns the first DefaultLimit students ordered by id
+type ListOptions struct {
+	Limit  int         // Limit is the page size, between 1 and MaxLimit
+	Offset int         // Offset skips that many matching students, it cannot be combined with Cursor
+	Cursor string      // Cursor continues a listing after the page that returned it as NextCursor
+	Sort   []SortField // Sort is the sort order, the id is always appended as a final tie breaker
+
+	AgeMin       *int   // AgeMin keeps students at least this old
+	AgeMax       *int   // AgeMax keeps students at most this old
+	EmailDomain  string // EmailDomain keeps students whose email is at this domain (case-insensitive)
+	NameContains string // NameContains keeps students whose name contains this text (case-insensitive)
+}
+
+// StudentPage is one page of a student listing
+type StudentPage struct {
+	Students   []types.Student // Students are the students on this page
+	NextCursor string          // NextCursor fetches the following page, empty on the last page
+	Total      int64           // Total is the number of students matching the filters across all pages
+}
+
+// ParseSort parses a sort expression such as "name,-age"
+// A leading "-" sorts that field in descending order
+func ParseSort(expr string) ([]SortField, error) {
+	var fields []SortField
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !sortable[field.Field] {
+			return nil, fmt.Errorf("cannot sort by %q", field.Field)
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("field %q appears twice in sort", field.Field)
+		}
+
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Normalize validates the options and fills in the defaults
+// Every backend calls it first, so they all agree on limits and tie breaking
+func (o *ListOptions) Normalize() error {
+	if o.Limit == 0 {
+		o.Limit = DefaultLimit
+	}
+	if o.Limit < 1 || o.Limit > MaxLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+	if o.Offset > 0 && o.Cursor != "" {
+		return fmt.Errorf("offset cannot be combined with cursor")
+	}
+	if o.AgeMin != nil && o.AgeMax != nil && *o.AgeMin > *o.AgeMax {
+		return fmt.Errorf("age_min must not be greater than age_max")
+	}
+
+	// The id is unique, so appending it makes the order total and the cursor unambiguous
+	for _, field := range o.Sort {
+		if !sortable[field.Field] {
+			return fmt.Errorf("cannot sort by %q", field.Field)
+		}
+		if field.Field == "id" {
+			return nil
+		}
+	}
+	o.Sort = append(o.Sort, SortField{Field: "id"})
+
+	return nil
+}
+
+// sortKey returns the canonical text of a sort order, it is embedded in cursors
+func sortKey(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Field
+		if field.Desc {
+			parts[i] = "-" + field.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// cursor is the decoded form of ListOptions.Cursor
+type cursor struct {
+	Sort    string        `json:"s"` // Sort is the sort order the cursor was issued for
+	Student types.Student `json:"v"` // Student holds the sort values of the last student of the previous page
+}
+
+// EncodeCursor returns the cursor continuing a listing after the given student
+func EncodeCursor(last types.Student, sort []SortField) string {
+	// Keep only the sort values, the rest of the student has no business in a URL
+	var values types.Student
+	for _, field := range sort {
+		switch field.Field {
+		case "name":
+			values.Name = last.Name
+		case "email":
+			values.Email = last.Email
+		case "age":
+			values.Age = last.Age
+		case "id":
+			values.Id = last.Id
+		}
+	}
+
+	// Encoding a struct of plain fields cannot fail
+	raw, _ := json.Marshal(cursor{Sort: sortKey(sort), Student: values})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor returns the last student of the previous page encoded in the cursor
+// It fails with ErrInvalidCursor if the cursor is malformed or was issued for another sort order
+func DecodeCursor(value string, sort []SortField) (types.Student, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return types.Student{}, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Sort != sortKey(sort) {
+		return types.Student{}, ErrInvalidCursor
+	}
+
+	return c.Student, nil
+}
+
+// SortValue returns the value of a sortable field of the student
+func SortValue(student types.Student, field string) any {
+	switch field {
+	case "name":
+		return student.Name
+	case "email":
+		return student.Email
+	case "age":
+		return student.Age
+	default:
+		return student.Id
+	}
+}
+
+// CompareStudents compares two students in the given sort order
+// It returns a negative number if a sorts before b, a positive number if it sorts after and zero if they are equal
+func CompareStudents(a types.Student, b types.Student, sort []SortField) int {
+	for _, field := range sort {
+		var c int
+
+		switch av := SortValue(a, field.Field).(type) {
+		case string:
+			c = strings.Compare(av, SortValue(b, field.Field).(string))
+		case int:
+			c = compareInt(int64(av), int64(SortValue(b, field.Field).(int)))
+		case int64:
+			c = compareInt(av, SortValue(b, field.Field).(int64))
+		}
+
+		if field.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func compareInt(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Matches reports whether the student passes the filters of the options
+// Backends that cannot filter in a query language (e.g. the in-memory one) use it
+func (o ListOptions) Matches(student types.Student) bool {
+	if o.AgeMin != nil && student.Age < *o.AgeMin {
+		return false
+	}
+	if o.AgeMax != nil && student.Age > *o.AgeMax {
+		return false
+	}
+	if o.EmailDomain != "" && !strings.HasSuffix(strings.ToLower(student.Email), "@"+strings.ToLower(o.EmailDomain)) {
+		return false
+	}
+	if o.NameContains != "" && !strings.Contains(strings.ToLower(student.Name), strings.ToLower(o.NameContains)) {
+		return false
+	}
+	return true
+}
+
+// SQLFilter builds the WHERE conditions and arguments of the filters of the options
+// It takes the driver's placeholder style and returns the conditions joined by AND ("" without filters)
+func (o ListOptions) SQLFilter(placeholder func(n int) string) (string, []any) {
+	var conditions []string
+	var args []any
+
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, placeholder(len(args))))
+	}
+
+	if o.AgeMin != nil {
+		add("age >= %s", *o.AgeMin)
+	}
+	if o.AgeMax != nil {
+		add("age <= %s", *o.AgeMax)
+	}
+	if o.EmailDomain != "" {
+		add(`LOWER(email) LIKE %s ESCAPE '\'`, "%@"+escapeLike(strings.ToLower(o.EmailDomain)))
+	}
+	if o.NameContains != "" {
+		add(`LOWER(name) LIKE %s ESCAPE '\'`, "%"+escapeLike(strings.ToLower(o.NameContains))+"%")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// SQLAfter builds the keyset condition selecting the rows sorted after the given student
+// Placeholders are numbered from offset+1 so the condition can follow the filter arguments
+func SQLAfter(last types.Student, sort []SortField, placeholder func(n int) string, offset int) (string, []any) {
+	var alternatives []string
+	var args []any
+
+	// (a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?) ...
+	for i, field := range sort {
+		var terms []string
+
+		for _, equal := range sort[:i] {
+			args = append(args, SortValue(last, equal.Field))
+			terms = append(terms, fmt.Sprintf("%s = %s", equal.Field, placeholder(offset+len(args))))
+		}
+
+		op := ">"
+		if field.Desc {
+			op = "<"
+		}
+		args = append(args, SortValue(last, field.Field))
+		terms = append(terms, fmt.Sprintf("%s %s %s", field.Field, op, placeholder(offset+len(args))))
+
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// SQLOrderBy builds the ORDER BY list of the sort order
+func SQLOrderBy(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, field := range sort {
+		parts[i] = field.Field
+		if field.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// escapeLike escapes the LIKE wildcards of a user supplied string, '\' is the escape character
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,470 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// Memory struct represents an in-memory student store
+// It is safe for concurrent use and mirrors the behaviour of the SQLite backend,
+// which makes it handy for handler tests and quick local demos
+type Memory struct {
+	mu       sync.RWMutex            // mu guards every field below
+	students map[int64]types.Student // students holds the stored students keyed by their ID, trashed ones included
+	tenants  map[int64]string        // tenants holds the tenant of every stored student keyed by their ID
+	lastID   int64                   // lastID is the last ID handed out, like SQLite's AUTOINCREMENT it is never reused
+}
+
+// New function initializes a new, empty Memory instance
+func New() *Memory {
+	return &Memory{
+		students: make(map[int64]types.Student),
+		tenants:  make(map[int64]string),
+	}
+}
+
+// CreateStudent function creates a new student in memory
+// It takes the student's name, email, and age as arguments and returns the ID of the newly created student and an error
+// The student belongs to the tenant of the context
+func (m *Memory) CreateStudent(ctx context.Context, name string, email string, age int) (int64, error) {
+	// Honour cancellation the same way an aborted SQL query would
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Emails are unique within the tenant, like the unique index of the SQL backends
+	if m.emailTaken(ctx, email, 0) {
+		return 0, storage.EmailTaken(email)
+	}
+
+	// Hand out the next ID, deleted IDs are never reused
+	m.lastID++
+	m.tenants[m.lastID] = tenant.ID(ctx)
+	m.students[m.lastID] = types.Student{
+		Id:      m.lastID,
+		Name:    name,
+		Email:   email,
+		Age:     age,
+		Version: 1,
+	}
+
+	return m.lastID, nil
+}
+
+// GetStudentById function retrieves a student by their ID
+// It takes the student's ID as an argument and returns the student data and an error
+// Like every students query, it only sees the students of the tenant of the context
+func (m *Memory) GetStudentById(ctx context.Context, id int64) (types.Student, error) {
+	if err := ctx.Err(); err != nil {
+		return types.Student{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	student, ok := m.active(ctx, id)
+	if !ok {
+		// If the student is not found, return an error
+		return types.Student{}, storage.StudentNotFound(id)
+	}
+
+	return student, nil
+}
+
+// active returns the student with the given ID unless they are missing, in the trash or of another tenant
+// The caller must hold the lock
+func (m *Memory) active(ctx context.Context, id int64) (types.Student, bool) {
+	student, ok := m.owned(ctx, id)
+	if !ok || student.DeletedAt != nil {
+		return types.Student{}, false
+	}
+	return student, true
+}
+
+// owned returns the student with the given ID, trashed or not, if they belong to the tenant of the context
+// The caller must hold the lock
+func (m *Memory) owned(ctx context.Context, id int64) (types.Student, bool) {
+	student, ok := m.students[id]
+	if !ok || m.tenants[id] != tenant.ID(ctx) {
+		return types.Student{}, false
+	}
+	return student, true
+}
+
+// GetAllStudents function retrieves one page of students
+// It takes the listing options (filters, sort order, limit and cursor or offset) and returns the page and an error
+// Filtering and ordering follow the same rules as the SQL backends
+func (m *Memory) GetAllStudents(ctx context.Context, opts storage.ListOptions) (storage.StudentPage, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.StudentPage{}, err
+	}
+
+	slog.Info("Get all students method called")
+
+	// Apply the default limit and the id tie breaker to the sort order
+	if err := opts.Normalize(); err != nil {
+		return storage.StudentPage{}, err
+	}
+
+	// Decode the cursor before taking the lock
+	var last types.Student
+	if opts.Cursor != "" {
+		var err error
+		if last, err = storage.DecodeCursor(opts.Cursor, opts.Sort); err != nil {
+			return storage.StudentPage{}, err
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Collect the students matching the filters, trashed students and other tenants are never listed
+	tenantID := tenant.ID(ctx)
+	var matching []types.Student
+	for id, student := range m.students {
+		if student.DeletedAt == nil && m.tenants[id] == tenantID && opts.Matches(student) {
+			matching = append(matching, student)
+		}
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return storage.CompareStudents(matching[i], matching[j], opts.Sort) < 0
+	})
+
+	page := storage.StudentPage{Total: int64(len(matching))}
+
+	// Skip to the first student after the cursor, or by the offset
+	start := opts.Offset
+	if opts.Cursor != "" {
+		start = sort.Search(len(matching), func(i int) bool {
+			return storage.CompareStudents(matching[i], last, opts.Sort) > 0
+		})
+	}
+	if start > len(matching) {
+		start = len(matching)
+	}
+
+	end := start + opts.Limit
+	if end < len(matching) {
+		page.NextCursor = storage.EncodeCursor(matching[end-1], opts.Sort)
+	} else {
+		end = len(matching)
+	}
+
+	page.Students = append(page.Students, matching[start:end]...)
+
+	return page, nil
+}
+
+// SearchStudents function searches students by name and email
+// It takes the search text and the maximum number of results and returns the best matches first
+// Every word of the query must match the start of a word in the name or email, names weigh more than emails
+func (m *Memory) SearchStudents(ctx context.Context, query string, limit int) ([]types.Student, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	terms := storage.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type match struct {
+		student types.Student
+		score   int
+	}
+
+	tenantID := tenant.ID(ctx)
+	var matches []match
+	for id, student := range m.students {
+		if student.DeletedAt != nil || m.tenants[id] != tenantID {
+			continue
+		}
+
+		nameWords := storage.SearchTerms(student.Name)
+		emailWords := storage.SearchTerms(student.Email)
+
+		// Score 10 per term found in the name and 1 per term found in the email, like the SQL weights
+		score := 0
+		for _, term := range terms {
+			termScore := 10*countPrefixed(nameWords, term) + countPrefixed(emailWords, term)
+			if termScore == 0 {
+				score = 0
+				break
+			}
+			score += termScore
+		}
+
+		if score > 0 {
+			matches = append(matches, match{student: student, score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].student.Id < matches[j].student.Id
+	})
+
+	var students []types.Student
+	for i := 0; i < len(matches) && i < limit; i++ {
+		students = append(students, matches[i].student)
+	}
+
+	return students, nil
+}
+
+// countPrefixed counts the words starting with prefix
+func countPrefixed(words []string, prefix string) int {
+	count := 0
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+// UpdateStudent function updates a student in memory
+// It takes the student's ID, name, email, age and expected version and returns the updated student data and an error
+// A version of 0 updates unconditionally, otherwise storage.ErrVersionMismatch is returned unless it matches
+func (m *Memory) UpdateStudent(ctx context.Context, id int64, name string, email string, age int, version int64) (types.Student, error) {
+	if err := ctx.Err(); err != nil {
+		return types.Student{}, err
+	}
+
+	slog.Info("Updating a student")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// If no student has this ID, report it the same way SQLite does
+	current, ok := m.active(ctx, id)
+	if !ok {
+		return types.Student{}, storage.StudentNotFound(id)
+	}
+	if version != 0 && current.Version != version {
+		return types.Student{}, storage.ErrVersionMismatch
+	}
+	if m.emailTaken(ctx, email, id) {
+		return types.Student{}, storage.EmailTaken(email)
+	}
+
+	student := types.Student{
+		Id:      id,
+		Name:    name,
+		Email:   email,
+		Age:     age,
+		Version: current.Version + 1,
+	}
+	m.students[id] = student
+
+	return student, nil
+}
+
+// PatchStudent function updates some fields of a student in memory
+// It takes the student's ID and the fields to change and returns the whole updated student and an error
+// Fields that are nil in the patch keep their current value, the version works as in UpdateStudent
+func (m *Memory) PatchStudent(ctx context.Context, id int64, patch types.StudentPatch, version int64) (types.Student, error) {
+	if err := ctx.Err(); err != nil {
+		return types.Student{}, err
+	}
+
+	slog.Info("Patching a student")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// If no student has this ID, report it the same way UpdateStudent does
+	student, ok := m.active(ctx, id)
+	if !ok {
+		return types.Student{}, storage.StudentNotFound(id)
+	}
+	if version != 0 && student.Version != version {
+		return types.Student{}, storage.ErrVersionMismatch
+	}
+	if patch.Email != nil && m.emailTaken(ctx, *patch.Email, id) {
+		return types.Student{}, storage.EmailTaken(*patch.Email)
+	}
+
+	// An empty patch changes nothing, not even the version
+	if patch == (types.StudentPatch{}) {
+		return student, nil
+	}
+
+	if patch.Name != nil {
+		student.Name = *patch.Name
+	}
+	if patch.Email != nil {
+		student.Email = *patch.Email
+	}
+	if patch.Age != nil {
+		student.Age = *patch.Age
+	}
+	student.Version++
+	m.students[id] = student
+
+	return student, nil
+}
+
+// DeleteStudentById function moves a student to the trash by their ID
+// It takes the student's ID as an argument and returns an error
+// Deleting an unknown or already trashed ID fails with storage.ErrNotFound, matching the SQL backends
+// A non-zero version must match the stored one, otherwise storage.ErrVersionMismatch is returned
+func (m *Memory) DeleteStudentById(ctx context.Context, id int64, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	slog.Info("Deleting a student")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	student, ok := m.active(ctx, id)
+	if !ok {
+		return storage.StudentNotFound(id)
+	}
+	if version != 0 && student.Version != version {
+		return storage.ErrVersionMismatch
+	}
+
+	m.students[id] = trashed(student, time.Now().UTC())
+
+	return nil
+}
+
+// trashed returns the student marked as deleted at the given time
+// The version is incremented, so ETags taken before the deletion do not match the restored student
+func trashed(student types.Student, at time.Time) types.Student {
+	student.DeletedAt = &at
+	student.Version++
+	return student
+}
+
+// emailTaken reports whether another student of the tenant than the one with the given ID uses the email
+// Emails are compared case-insensitively, like the unique index on LOWER(email), trashed students do not count
+// The caller must hold the lock
+func (m *Memory) emailTaken(ctx context.Context, email string, id int64) bool {
+	tenantID := tenant.ID(ctx)
+	for _, student := range m.students {
+		if student.Id != id && student.DeletedAt == nil && m.tenants[student.Id] == tenantID && strings.EqualFold(student.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteAllStudents function moves all students of the tenant to the trash
+// It returns an error
+func (m *Memory) DeleteAllStudents(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tenantID := tenant.ID(ctx)
+	now := time.Now().UTC()
+	for id, student := range m.students {
+		if student.DeletedAt == nil && m.tenants[id] == tenantID {
+			m.students[id] = trashed(student, now)
+		}
+	}
+
+	return nil
+}
+
+// GetDeletedStudents function retrieves the students of the tenant in the trash, most recently deleted first
+func (m *Memory) GetDeletedStudents(ctx context.Context) ([]types.Student, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tenantID := tenant.ID(ctx)
+	students := []types.Student{}
+	for id, student := range m.students {
+		if student.DeletedAt != nil && m.tenants[id] == tenantID {
+			students = append(students, student)
+		}
+	}
+
+	sort.Slice(students, func(i, j int) bool {
+		if !students[i].DeletedAt.Equal(*students[j].DeletedAt) {
+			return students[i].DeletedAt.After(*students[j].DeletedAt)
+		}
+		return students[i].Id < students[j].Id
+	})
+
+	return students, nil
+}
+
+// RestoreStudent function takes a student out of the trash and returns it
+// It fails with storage.ErrNotFound if no trashed student of the tenant has the ID and with storage.ErrConflict
+// if their email has been given to another student in the meantime
+func (m *Memory) RestoreStudent(ctx context.Context, id int64) (types.Student, error) {
+	if err := ctx.Err(); err != nil {
+		return types.Student{}, err
+	}
+
+	slog.Info("Restoring a student", slog.Int64("id", id))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	student, ok := m.owned(ctx, id)
+	if !ok || student.DeletedAt == nil {
+		return types.Student{}, storage.DeletedStudentNotFound(id)
+	}
+	if m.emailTaken(ctx, student.Email, id) {
+		return types.Student{}, storage.EmailTaken(student.Email)
+	}
+
+	student.DeletedAt = nil
+	student.Version++
+	m.students[id] = student
+
+	return student, nil
+}
+
+// PurgeDeletedStudents function deletes the students trashed before the given time for good
+// The retention is the same for every school, so the trash of every tenant is purged
+// It returns the number of students purged, the ID counter is kept so new students never reuse their IDs
+func (m *Memory) PurgeDeletedStudents(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var purged int64
+	for id, student := range m.students {
+		if student.DeletedAt != nil && student.DeletedAt.Before(before) {
+			delete(m.students, id)
+			delete(m.tenants, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,271 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Placeholder returns the bind parameter marker for the n-th (1 based) argument of a statement
+// SQL drivers disagree on the syntax, so every backend passes the one it understands
+type Placeholder func(n int) string
+
+// Question is the Placeholder used by SQLite and MySQL style drivers ("?")
+func Question(int) string { return "?" }
+
+// Dollar is the Placeholder used by PostgreSQL drivers ("$1", "$2", ...)
+func Dollar(n int) string { return "$" + strconv.Itoa(n) }
+
+// Migration represents a single versioned schema change
+// It is loaded from a pair of files named <version>_<name>.up.sql and <version>_<name>.down.sql
+type Migration struct {
+	Version int64  // Version orders the migrations, it must be unique
+	Name    string // Name is the descriptive part of the file name
+	Up      string // Up is the SQL applying the change
+	Down    string // Down is the SQL reverting the change, it may be empty if the change cannot be reverted
+}
+
+// Status describes whether a migration has been applied to the database
+type Status struct {
+	Migration
+	Applied   bool      // Applied reports whether the migration is recorded in schema_migrations
+	AppliedAt time.Time // AppliedAt is when the migration was applied, zero if it is pending
+}
+
+// Load reads every migration file found at the root of fsys
+// It returns the migrations ordered by version and an error if a file name is malformed,
+// a version is declared twice or a migration has no up file
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || path.Ext(fileName) != ".sql" {
+			continue
+		}
+
+		// Split "0001_create_students.up.sql" into version, name and direction
+		base := strings.TrimSuffix(fileName, ".sql")
+		direction := path.Ext(base)
+		if direction != ".up" && direction != ".down" {
+			return nil, fmt.Errorf("migration %s: expected an .up.sql or .down.sql suffix", fileName)
+		}
+		base = strings.TrimSuffix(base, direction)
+
+		versionPart, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: file name must start with a positive version number", fileName)
+		}
+
+		body, err := fs.ReadFile(fsys, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, migration.Name, name)
+		}
+
+		if direction == ".up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	// Order the migrations by version and make sure each one can be applied
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations against a database
+// The applied versions are tracked in the schema_migrations table
+type Migrator struct {
+	db          *sql.DB
+	migrations  []Migration
+	placeholder Placeholder
+}
+
+// New function initializes a new Migrator for the migrations found in fsys
+// It takes the database, the migration files and the driver's Placeholder style
+func New(db *sql.DB, fsys fs.FS, placeholder Placeholder) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:          db,
+		migrations:  migrations,
+		placeholder: placeholder,
+	}, nil
+}
+
+// ensureTable creates the schema_migrations table if it does not already exist
+func (m *Migrator) ensureTable() error {
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	return err
+}
+
+// applied returns the applied versions and when they were applied
+func (m *Migrator) applied() (map[int64]time.Time, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	versions := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+
+	return versions, rows.Err()
+}
+
+// Up applies every pending migration in version order
+// Each migration runs in its own transaction together with its schema_migrations row
+// It returns the number of migrations applied
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		insert := fmt.Sprintf("INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)",
+			m.placeholder(1), m.placeholder(2), m.placeholder(3))
+
+		err := m.inTx(migration.Up, insert, migration.Version, migration.Name, time.Now().UTC())
+		if err != nil {
+			return count, fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		slog.Info("Applied migration", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+		count++
+	}
+
+	return count, nil
+}
+
+// Down reverts the most recently applied migrations, newest first
+// It takes the number of migrations to revert and returns how many were reverted
+func (m *Migrator) Down(steps int) (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if strings.TrimSpace(migration.Down) == "" {
+			return count, fmt.Errorf("migration %d_%s cannot be reverted: it has no down file", migration.Version, migration.Name)
+		}
+
+		remove := fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %s", m.placeholder(1))
+
+		err := m.inTx(migration.Down, remove, migration.Version)
+		if err != nil {
+			return count, fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		slog.Info("Reverted migration", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+		count++
+	}
+
+	return count, nil
+}
+
+// Status returns every known migration together with whether it has been applied
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, Status{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// inTx runs the migration script followed by the bookkeeping statement in a single transaction
+func (m *Migrator) inTx(script string, bookkeeping string, args ...any) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(bookkeeping, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
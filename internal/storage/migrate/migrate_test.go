@@ -0,0 +1,136 @@
+package migrate_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage/migrate"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// migrations are two reversible changes
+var migrations = fstest.MapFS{
+	"0001_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER)")},
+	"0001_create_a.down.sql": {Data: []byte("DROP TABLE a")},
+	"0002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER)")},
+	"0002_create_b.down.sql": {Data: []byte("DROP TABLE b")},
+}
+
+// newMigrator returns a Migrator for fsys over an empty SQLite database
+func newMigrator(t *testing.T, fsys fstest.MapFS) (*migrate.Migrator, *sql.DB) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, fsys, migrate.Question)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator, db
+}
+
+// tableExists reports whether the database has a table of that name
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+// applied returns the applied state of every migration, in version order
+func applied(t *testing.T, migrator *migrate.Migrator) []bool {
+	t.Helper()
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := make([]bool, len(statuses))
+	for i, status := range statuses {
+		states[i] = status.Applied
+	}
+	return states
+}
+
+func TestUpAndDown(t *testing.T) {
+	migrator, db := newMigrator(t, migrations)
+
+	if n, err := migrator.Up(); err != nil || n != 2 {
+		t.Fatalf("Up: applied %d with error %v, want 2", n, err)
+	}
+	if !tableExists(t, db, "a") || !tableExists(t, db, "b") {
+		t.Fatalf("Up did not create both tables")
+	}
+
+	// Nothing is pending any more
+	if n, err := migrator.Up(); err != nil || n != 0 {
+		t.Fatalf("second Up: applied %d with error %v, want 0", n, err)
+	}
+
+	// Down reverts the newest migration first
+	if n, err := migrator.Down(1); err != nil || n != 1 {
+		t.Fatalf("Down(1): reverted %d with error %v, want 1", n, err)
+	}
+	if !tableExists(t, db, "a") || tableExists(t, db, "b") {
+		t.Fatalf("Down(1) did not revert only 0002")
+	}
+	if got := applied(t, migrator); len(got) != 2 || !got[0] || got[1] {
+		t.Fatalf("Status after Down(1): got %v, want [true false]", got)
+	}
+
+	if n, err := migrator.Down(5); err != nil || n != 1 {
+		t.Fatalf("Down(5): reverted %d with error %v, want the 1 left", n, err)
+	}
+	if tableExists(t, db, "a") {
+		t.Fatalf("Down(5) did not revert 0001")
+	}
+}
+
+func TestFailedMigrationIsNotRecorded(t *testing.T) {
+	broken := fstest.MapFS{
+		"0001_create_a.up.sql": migrations["0001_create_a.up.sql"],
+		"0002_broken.up.sql":   {Data: []byte("CREATE TABLE c (id INTEGER); INSERT INTO missing VALUES (1)")},
+	}
+	migrator, db := newMigrator(t, broken)
+
+	if n, err := migrator.Up(); err == nil || n != 1 {
+		t.Fatalf("Up: applied %d with error %v, want 1 and an error", n, err)
+	}
+
+	// The failed migration is rolled back as a whole and stays pending
+	if tableExists(t, db, "c") {
+		t.Fatalf("the failed migration left table c behind")
+	}
+	if got := applied(t, migrator); len(got) != 2 || !got[0] || got[1] {
+		t.Fatalf("Status after the failure: got %v, want [true false]", got)
+	}
+
+	// A migration without a down file cannot be reverted
+	if _, err := migrator.Down(1); err == nil {
+		t.Fatalf("Down of a migration without a down file: got no error")
+	}
+}
+
+func TestLoadRejectsMalformedFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"no version":        {"create_a.up.sql": {Data: []byte("SELECT 1")}},
+		"no direction":      {"0001_create_a.sql": {Data: []byte("SELECT 1")}},
+		"duplicate version": {"0001_a.up.sql": {Data: []byte("SELECT 1")}, "0001_b.up.sql": {Data: []byte("SELECT 1")}},
+		"no up file":        {"0001_a.down.sql": {Data: []byte("SELECT 1")}},
+	}
+
+	for name, fsys := range tests {
+		if _, err := migrate.Load(fsys); err == nil {
+			t.Errorf("Load with %s: got no error", name)
+		}
+	}
+}
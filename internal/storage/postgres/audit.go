@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// studentInTx reads and locks a student of the tenant of the context, trashed or not, inside a write transaction
+// The lock keeps a concurrent write from changing the student between this read and the write recorded against it
+// It returns sql.ErrNoRows if the tenant has no student with the ID
+func studentInTx(ctx context.Context, tx *sql.Tx, id int64) (types.Student, error) {
+	var student types.Student
+	err := tx.QueryRowContext(ctx, "SELECT id,name,email,age,version,deleted_at FROM students WHERE id = $1 AND tenant_id = $2 FOR UPDATE", id, tenant.ID(ctx)).
+		Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version, &student.DeletedAt)
+	return student, err
+}
+
+// recordChange appends a change of a student to their history, inside the transaction making the change
+// The actor, the request ID and the tenant are taken from the context
+func recordChange(ctx context.Context, tx *sql.Tx, action string, before *types.Student, after *types.Student) error {
+	entry := audit.NewEntry(ctx, action, before, after)
+
+	beforeState, err := jsonOrNull(entry.Before)
+	if err != nil {
+		return err
+	}
+	afterState, err := jsonOrNull(entry.After)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO student_audit (tenant_id, student_id, action, actor, request_id, at, before_state, after_state, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		tenant.ID(ctx), entry.StudentId, entry.Action, entry.Actor, entry.RequestId, entry.At, beforeState, afterState, string(changes))
+	return err
+}
+
+// jsonOrNull encodes a student as JSON text, a nil student is stored as NULL
+func jsonOrNull(student *types.Student) (any, error) {
+	if student == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(student)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+// GetStudentHistory function retrieves every recorded change of a student, oldest first
+// The history of trashed and purged students is kept, it fails with storage.ErrNotFound only
+// if the student neither exists nor has any history in the tenant of the context
+func (p *Postgres) GetStudentHistory(ctx context.Context, id int64) ([]types.AuditEntry, error) {
+	rows, err := p.Db.QueryContext(ctx, `SELECT id, student_id, action, actor, request_id, at, before_state, after_state, changes
+		FROM student_audit
+		WHERE tenant_id = $1 AND student_id = $2
+		ORDER BY id`, tenant.ID(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []types.AuditEntry{}
+	for rows.Next() {
+		var entry types.AuditEntry
+		var beforeState, afterState sql.NullString
+		var changes string
+
+		err := rows.Scan(&entry.Id, &entry.StudentId, &entry.Action, &entry.Actor, &entry.RequestId, &entry.At,
+			&beforeState, &afterState, &changes)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.Before, err = decodeState(beforeState); err != nil {
+			return nil, err
+		}
+		if entry.After, err = decodeState(afterState); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A student created before the history was recorded has none yet
+	if len(entries) == 0 {
+		var exists bool
+		if err := p.Db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM students WHERE id = $1 AND tenant_id = $2)", id, tenant.ID(ctx)).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, storage.StudentNotFound(id)
+		}
+	}
+
+	return entries, nil
+}
+
+// decodeState decodes a student stored by recordChange, NULL is a nil student
+func decodeState(state sql.NullString) (*types.Student, error) {
+	if !state.Valid {
+		return nil, nil
+	}
+
+	var student types.Student
+	if err := json.Unmarshal([]byte(state.String), &student); err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
+
+// GetStudentAsOf function retrieves a student as they were at the given time
+// It fails with storage.ErrNotFound if the student did not exist or was in the trash at that time
+// A student without any history has not changed since it was recorded, so their current state is returned
+func (p *Postgres) GetStudentAsOf(ctx context.Context, id int64, at time.Time) (types.Student, error) {
+	entries, err := p.GetStudentHistory(ctx, id)
+	if err != nil {
+		return types.Student{}, err
+	}
+
+	if len(entries) == 0 {
+		return p.GetStudentById(ctx, id)
+	}
+
+	student, ok := audit.AsOf(entries, at)
+	if !ok {
+		return types.Student{}, storage.StudentNotFoundAt(id, at)
+	}
+
+	return student, nil
+}
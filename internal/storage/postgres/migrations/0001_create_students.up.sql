@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS students (
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT,
+	email TEXT,
+	age INTEGER
+);
@@ -0,0 +1,7 @@
+-- Full-text search document over the searchable student columns, names rank above emails.
+ALTER TABLE students ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('simple', coalesce(email, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_students_search ON students USING GIN (search);
@@ -0,0 +1,2 @@
+-- Optimistic concurrency: every update increments the version, which is exposed as the ETag.
+ALTER TABLE students ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- Emails identify students, so no two students may share one (compared case-insensitively).
+-- Existing duplicates must be resolved by hand before this migration can be applied.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_unique ON students (LOWER(email));
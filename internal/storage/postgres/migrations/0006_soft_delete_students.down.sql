@@ -0,0 +1,7 @@
+-- Trashed students cannot be represented without the column, so they are deleted for good.
+DELETE FROM students WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_students_deleted_at;
+DROP INDEX IF EXISTS idx_students_email_unique;
+ALTER TABLE students DROP COLUMN IF EXISTS deleted_at;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_unique ON students (LOWER(email));
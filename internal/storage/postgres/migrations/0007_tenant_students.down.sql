@@ -0,0 +1,7 @@
+-- The students of other tenants cannot be represented without the column, so they are deleted for good.
+DELETE FROM students WHERE tenant_id <> 'default';
+
+DROP INDEX IF EXISTS idx_students_tenant;
+DROP INDEX IF EXISTS idx_students_email_unique;
+ALTER TABLE students DROP COLUMN IF EXISTS tenant_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_unique ON students (LOWER(email)) WHERE deleted_at IS NULL;
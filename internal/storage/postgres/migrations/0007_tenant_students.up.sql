@@ -0,0 +1,9 @@
+-- Every student belongs to a tenant (school), the students stored so far belong to the "default" tenant.
+ALTER TABLE students ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Emails are unique within a tenant, two schools may each have a student with the same email.
+DROP INDEX IF EXISTS idx_students_email_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_unique ON students (tenant_id, LOWER(email)) WHERE deleted_at IS NULL;
+
+-- Every students query is scoped to a tenant.
+CREATE INDEX IF NOT EXISTS idx_students_tenant ON students (tenant_id, id);
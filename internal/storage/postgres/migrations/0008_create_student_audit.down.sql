@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS student_audit_append_only ON student_audit;
+DROP FUNCTION IF EXISTS student_audit_append_only();
+DROP INDEX IF EXISTS idx_student_audit_student;
+DROP TABLE IF EXISTS student_audit;
@@ -0,0 +1,28 @@
+-- The history of every student change, appended in the same transaction as the change itself.
+-- It has no foreign key on purpose: the history outlives a purged student.
+CREATE TABLE IF NOT EXISTS student_audit (
+	id BIGSERIAL PRIMARY KEY,
+	tenant_id TEXT NOT NULL DEFAULT 'default',
+	student_id BIGINT NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	request_id TEXT NOT NULL DEFAULT '',
+	at TIMESTAMPTZ NOT NULL,
+	before_state JSONB,
+	after_state JSONB,
+	changes JSONB NOT NULL
+);
+
+-- The history of a student is read per tenant, oldest entry first.
+CREATE INDEX IF NOT EXISTS idx_student_audit_student ON student_audit (tenant_id, student_id, id);
+
+-- The history is append-only, recorded entries can neither be changed nor removed.
+CREATE OR REPLACE FUNCTION student_audit_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'student_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS student_audit_append_only ON student_audit;
+CREATE TRIGGER student_audit_append_only BEFORE UPDATE OR DELETE ON student_audit
+	FOR EACH ROW EXECUTE FUNCTION student_audit_append_only();
@@ -0,0 +1,590 @@
+package postgres
+
+import (
+	"context"
+	"database/sql" // Import the database/sql package for SQL database operations
+	"embed"        // Import the embed package to ship the migration files inside the binary
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config" // Import the config package for application configuration
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/migrate"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/lib/pq" // Import the PostgreSQL driver for database operations and its error type
+)
+
+// Postgres struct represents a PostgreSQL database connection
+type Postgres struct {
+	Db *sql.DB // Db is a pointer to the sql.DB type, which represents a database connection pool
+}
+
+// migrations holds the versioned schema migrations applied by New
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// isUniqueViolation reports whether err was caused by a unique constraint, e.g. the one on students.email
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// Open function connects to the PostgreSQL database configured by storage.dsn without touching its schema
+// It is used by the migrate subcommand, everything else should use New
+func Open(cfg *config.Config) (*Postgres, error) {
+	// A DSN is mandatory for postgres, there is no sensible default to fall back to
+	if cfg.Storage.DSN == "" {
+		return nil, fmt.Errorf("postgres storage requires storage.dsn to be set")
+	}
+
+	// Open a new database connection pool using the PostgreSQL driver and the DSN from the config
+	db, err := sql.Open("postgres", cfg.Storage.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	// sql.Open does not connect, so ping the server to fail fast on a bad DSN
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Postgres{
+		Db: db,
+	}, nil
+}
+
+// New function initializes a new Postgres instance
+// It takes a configuration object as an argument and returns a pointer to Postgres and an error
+// This function is used to establish a connection to the PostgreSQL database and bring its schema up to date
+func New(cfg *config.Config) (*Postgres, error) {
+	p, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply every pending schema migration before the database is used
+	migrator, err := p.Migrator()
+	if err != nil {
+		p.Db.Close()
+		return nil, err
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		p.Db.Close()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Migrator function returns a migrate.Migrator for the embedded PostgreSQL migrations
+func (p *Postgres) Migrator() (*migrate.Migrator, error) {
+	files, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(p.Db, files, migrate.Dollar)
+}
+
+// CreateStudent function creates a new student in the database
+// It takes the student's name, email, and age as arguments and returns the ID of the newly created student and an error
+// PostgreSQL does not support LastInsertId, so the generated id is read back with RETURNING
+// The student belongs to the tenant of the context and their creation is recorded in their history
+func (p *Postgres) CreateStudent(ctx context.Context, name string, email string, age int) (int64, error) {
+	// Insert and record the history in one transaction, so no change is ever missing from it
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, "INSERT INTO students (tenant_id,name,email,age) VALUES ($1,$2,$3,$4) RETURNING id",
+		tenant.ID(ctx), name, email, age).Scan(&id)
+	if err != nil {
+		// The email is already used by another student
+		if isUniqueViolation(err) {
+			return 0, storage.EmailTaken(email)
+		}
+		return 0, err
+	}
+
+	// A new student starts at version 1
+	created := types.Student{Id: id, Name: name, Email: email, Age: age, Version: 1}
+	if err := recordChange(ctx, tx, types.AuditCreate, nil, &created); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// GetStudentById function retrieves a student from the database by their ID
+// It takes the student's ID as an argument and returns the student data and an error
+// Like every students query, it only sees the students of the tenant of the context
+func (p *Postgres) GetStudentById(ctx context.Context, id int64) (types.Student, error) {
+	var student types.Student
+
+	err := p.Db.QueryRowContext(ctx, "SELECT id,name,email,age,version FROM students WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL", id, tenant.ID(ctx)).
+		Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
+
+	if err != nil {
+		// If the student is not found, return an error
+		if err == sql.ErrNoRows {
+			return types.Student{}, storage.StudentNotFound(id)
+		}
+		return types.Student{}, err
+	}
+
+	return student, nil
+}
+
+// GetAllStudents function retrieves one page of students from the database
+// It takes the listing options (filters, sort order, limit and cursor or offset) and returns the page and an error
+// The cursor is a keyset over the sort columns, so deep pages stay as cheap as the first one
+func (p *Postgres) GetAllStudents(ctx context.Context, opts storage.ListOptions) (storage.StudentPage, error) {
+	slog.Info("Get all students method called")
+
+	// Apply the default limit and the id tie breaker to the sort order
+	if err := opts.Normalize(); err != nil {
+		return storage.StudentPage{}, err
+	}
+
+	// Build the WHERE clause from the filters, trashed students and other tenants are never listed
+	filter, args := opts.SQLFilter(migrate.Dollar)
+	where := " WHERE deleted_at IS NULL"
+	if filter != "" {
+		where += " AND " + filter
+	}
+	args = append(args, tenant.ID(ctx))
+	where += " AND tenant_id = " + migrate.Dollar(len(args))
+
+	var page storage.StudentPage
+
+	// Count every matching student, the total is independent of the page
+	err := p.Db.QueryRowContext(ctx, "SELECT COUNT(*) FROM students"+where, args...).Scan(&page.Total)
+	if err != nil {
+		return storage.StudentPage{}, err
+	}
+
+	// Continue after the last student of the previous page when a cursor is given
+	if opts.Cursor != "" {
+		last, err := storage.DecodeCursor(opts.Cursor, opts.Sort)
+		if err != nil {
+			return storage.StudentPage{}, err
+		}
+
+		after, afterArgs := storage.SQLAfter(last, opts.Sort, migrate.Dollar, len(args))
+		where += " AND " + after
+		args = append(args, afterArgs...)
+	}
+
+	// Fetch one extra row to find out whether there is a next page
+	query := fmt.Sprintf("SELECT id,name,email,age,version FROM students%s ORDER BY %s LIMIT %d OFFSET %d",
+		where, storage.SQLOrderBy(opts.Sort), opts.Limit+1, opts.Offset)
+
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return storage.StudentPage{}, err
+	}
+
+	defer rows.Close()
+
+	// Iterate over the rows and scan the student data
+	for rows.Next() {
+		var student types.Student
+
+		err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
+		if err != nil {
+			return storage.StudentPage{}, err
+		}
+
+		page.Students = append(page.Students, student)
+	}
+
+	if err := rows.Err(); err != nil {
+		return storage.StudentPage{}, err
+	}
+
+	// Drop the extra row and point the cursor at the last student of this page
+	if len(page.Students) > opts.Limit {
+		page.Students = page.Students[:opts.Limit]
+		page.NextCursor = storage.EncodeCursor(page.Students[opts.Limit-1], opts.Sort)
+	}
+
+	return page, nil
+}
+
+// SearchStudents function searches students by name and email using the generated search column
+// It takes the search text and the maximum number of results and returns the best matches first
+// Every word of the query must match the start of a word in the name or email, names weigh more than emails
+func (p *Postgres) SearchStudents(ctx context.Context, query string, limit int) ([]types.Student, error) {
+	terms := storage.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	// Turn every term into a prefix query, e.g. jo:* & smi:*
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	rows, err := p.Db.QueryContext(ctx, `SELECT id, name, email, age, version
+		FROM students
+		WHERE search @@ to_tsquery('simple', $1) AND tenant_id = $3 AND deleted_at IS NULL
+		ORDER BY ts_rank(search, to_tsquery('simple', $1)) DESC, id
+		LIMIT $2`, strings.Join(terms, " & "), limit, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	// Iterate over the rows and scan the student data
+	var students []types.Student
+	for rows.Next() {
+		var student types.Student
+
+		if err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version); err != nil {
+			return nil, err
+		}
+
+		students = append(students, student)
+	}
+
+	return students, rows.Err()
+}
+
+// UpdateStudent function updates a student in the database
+// It takes the student's ID, name, email, age and expected version and returns the updated student data and an error
+// A version of 0 updates unconditionally, otherwise the row is only changed while its version still matches
+// Every update increments the student's version and is recorded in their history
+func (p *Postgres) UpdateStudent(ctx context.Context, id int64, name string, email string, age int, version int64) (types.Student, error) {
+	slog.Info("Updating a student")
+
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return types.Student{}, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Read the student as they were, for their history
+	before, err := studentInTx(ctx, tx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return types.Student{}, err
+	}
+
+	// Update the row and read it back in one statement, so the new version is returned too
+	var student types.Student
+	err = tx.QueryRowContext(ctx, `UPDATE students SET name=$1, email=$2, age=$3, version=version+1
+		WHERE id=$4 AND tenant_id=$5 AND deleted_at IS NULL AND ($6=0 OR version=$6)
+		RETURNING id,name,email,age,version`, name, email, age, id, tenant.ID(ctx), version).
+		Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
+
+	if err != nil {
+		// No row matched, find out whether the student is missing or was modified meanwhile
+		if err == sql.ErrNoRows {
+			return types.Student{}, staleOrMissing(ctx, tx, id)
+		}
+		// The new email belongs to another student
+		if isUniqueViolation(err) {
+			return types.Student{}, storage.EmailTaken(email)
+		}
+		return types.Student{}, err
+	}
+
+	if err := recordChange(ctx, tx, types.AuditUpdate, &before, &student); err != nil {
+		return types.Student{}, err
+	}
+
+	return student, tx.Commit()
+}
+
+// staleOrMissing explains why a conditional write matched no row
+// It returns storage.ErrVersionMismatch if the student exists and a storage.ErrNotFound error if it does not
+func staleOrMissing(ctx context.Context, tx *sql.Tx, id int64) error {
+	var exists bool
+
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM students WHERE id=$1 AND tenant_id=$2 AND deleted_at IS NULL)", id, tenant.ID(ctx)).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return storage.ErrVersionMismatch
+	}
+	return storage.StudentNotFound(id)
+}
+
+// PatchStudent function updates some fields of a student in the database
+// It takes the student's ID and the fields to change and returns the whole updated student and an error
+// Fields that are nil in the patch keep their current value, the version works as in UpdateStudent
+func (p *Postgres) PatchStudent(ctx context.Context, id int64, patch types.StudentPatch, version int64) (types.Student, error) {
+	slog.Info("Patching a student")
+
+	// Collect the assignments of the fields present in the patch
+	var sets []string
+	var args []any
+	for _, field := range []struct {
+		column string
+		value  any
+		ok     bool
+	}{
+		{"name", patch.Name, patch.Name != nil},
+		{"email", patch.Email, patch.Email != nil},
+		{"age", patch.Age, patch.Age != nil},
+	} {
+		if field.ok {
+			args = append(args, field.value)
+			sets = append(sets, fmt.Sprintf("%s=%s", field.column, migrate.Dollar(len(args))))
+		}
+	}
+
+	// An empty patch changes nothing, but the version must still match
+	if len(sets) == 0 {
+		student, err := p.GetStudentById(ctx, id)
+		if err == nil && version != 0 && student.Version != version {
+			return types.Student{}, storage.ErrVersionMismatch
+		}
+		return student, err
+	}
+
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return types.Student{}, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Read the student as they were, for their history
+	before, err := studentInTx(ctx, tx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return types.Student{}, err
+	}
+
+	args = append(args, id, tenant.ID(ctx), version)
+	query := fmt.Sprintf("UPDATE students SET %s, version=version+1 WHERE id=%s AND tenant_id=%s AND deleted_at IS NULL AND (%s=0 OR version=%s) RETURNING id,name,email,age,version",
+		strings.Join(sets, ", "), migrate.Dollar(len(args)-2), migrate.Dollar(len(args)-1), migrate.Dollar(len(args)), migrate.Dollar(len(args)))
+
+	// Apply the update and read the resulting row back in one statement
+	var student types.Student
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
+	if err != nil {
+		// No row matched, find out whether the student is missing or was modified meanwhile
+		if err == sql.ErrNoRows {
+			return types.Student{}, staleOrMissing(ctx, tx, id)
+		}
+		// Only a changed email can collide with another student
+		if isUniqueViolation(err) && patch.Email != nil {
+			return types.Student{}, storage.EmailTaken(*patch.Email)
+		}
+		return types.Student{}, err
+	}
+
+	if err := recordChange(ctx, tx, types.AuditUpdate, &before, &student); err != nil {
+		return types.Student{}, err
+	}
+
+	return student, tx.Commit()
+}
+
+// DeleteStudentById function moves a student to the trash by their ID
+// It takes the student's ID and expected version as arguments and returns an error
+// A version of 0 deletes unconditionally, otherwise a student whose version changed is kept and storage.ErrVersionMismatch is returned
+// Deleting a missing or already trashed student fails with storage.ErrNotFound
+// The version is incremented, so ETags taken before the deletion do not match the restored student
+// The deletion is recorded in the student's history
+func (p *Postgres) DeleteStudentById(ctx context.Context, id int64, version int64) error {
+	slog.Info("Deleting a student")
+
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Read the student as they were, for their history
+	before, err := studentInTx(ctx, tx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	// The deletion time is taken here rather than with NOW(), so the history records the same time as the row
+	deletedAt := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, `UPDATE students SET deleted_at=$1, version=version+1
+		WHERE id=$2 AND tenant_id=$3 AND deleted_at IS NULL AND ($4=0 OR version=$4)`, deletedAt, id, tenant.ID(ctx), version)
+	if err != nil {
+		return err
+	}
+
+	// No row matched, find out whether the student is missing or was modified meanwhile
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return staleOrMissing(ctx, tx, id)
+	}
+
+	if err := recordChange(ctx, tx, types.AuditDelete, &before, trashedCopy(before, deletedAt)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// trashedCopy returns the student as they are once moved to the trash at the given time
+func trashedCopy(student types.Student, deletedAt time.Time) *types.Student {
+	student.DeletedAt = &deletedAt
+	student.Version++
+	return &student
+}
+
+// DeleteAllStudents function moves all students of the tenant to the trash
+// It returns an error
+// Each deletion is recorded in the student's history
+func (p *Postgres) DeleteAllStudents(ctx context.Context) error {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Mark all students of the tenant as deleted and read back the ones that were affected
+	deletedAt := time.Now().UTC()
+	rows, err := tx.QueryContext(ctx, `UPDATE students SET deleted_at=$1, version=version+1
+		WHERE tenant_id=$2 AND deleted_at IS NULL
+		RETURNING id,name,email,age,version`, deletedAt, tenant.ID(ctx))
+	if err != nil {
+		return err
+	}
+
+	// Collect the students first, the transaction cannot run another statement while the rows are open
+	var students []types.Student
+	for rows.Next() {
+		var student types.Student
+
+		if err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version); err != nil {
+			rows.Close()
+			return err
+		}
+
+		students = append(students, student)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, student := range students {
+		// The student as they were before this deletion
+		before := student
+		before.Version--
+
+		if err := recordChange(ctx, tx, types.AuditDelete, &before, trashedCopy(before, deletedAt)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetDeletedStudents function retrieves the students of the tenant in the trash, most recently deleted first
+func (p *Postgres) GetDeletedStudents(ctx context.Context) ([]types.Student, error) {
+	rows, err := p.Db.QueryContext(ctx, `SELECT id, name, email, age, version, deleted_at
+		FROM students
+		WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id`, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	// Iterate over the rows and scan the student data
+	students := []types.Student{}
+	for rows.Next() {
+		var student types.Student
+
+		if err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version, &student.DeletedAt); err != nil {
+			return nil, err
+		}
+
+		students = append(students, student)
+	}
+
+	return students, rows.Err()
+}
+
+// RestoreStudent function takes a student out of the trash and returns it
+// It fails with storage.ErrNotFound if no trashed student of the tenant has the ID and with storage.ErrConflict
+// if their email has been given to another student in the meantime
+// The restoration is recorded in the student's history
+func (p *Postgres) RestoreStudent(ctx context.Context, id int64) (types.Student, error) {
+	slog.Info("Restoring a student", slog.Int64("id", id))
+
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return types.Student{}, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Read the student as they were, for their history
+	before, err := studentInTx(ctx, tx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return types.Student{}, err
+	}
+
+	var student types.Student
+	err = tx.QueryRowContext(ctx, `UPDATE students SET deleted_at=NULL, version=version+1
+		WHERE id=$1 AND tenant_id=$2 AND deleted_at IS NOT NULL
+		RETURNING id,name,email,age,version`, id, tenant.ID(ctx)).
+		Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.Student{}, storage.DeletedStudentNotFound(id)
+		}
+		// The email was reused while the student was in the trash
+		if isUniqueViolation(err) {
+			return types.Student{}, storage.EmailTaken(before.Email)
+		}
+		return types.Student{}, err
+	}
+
+	if err := recordChange(ctx, tx, types.AuditRestore, &before, &student); err != nil {
+		return types.Student{}, err
+	}
+
+	return student, tx.Commit()
+}
+
+// PurgeDeletedStudents function deletes the students trashed before the given time for good
+// The retention is the same for every school, so the trash of every tenant is purged
+// It returns the number of students purged
+func (p *Postgres) PurgeDeletedStudents(ctx context.Context, before time.Time) (int64, error) {
+	result, err := p.Db.ExecContext(ctx, "DELETE FROM students WHERE deleted_at IS NOT NULL AND deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
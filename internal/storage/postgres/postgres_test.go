@@ -0,0 +1,38 @@
+package postgres_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/postgres"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/storagetest"
+)
+
+// dsnVariable names the environment variable holding the DSN of the database the tests run against
+// Every test empties the students table, so it must not hold real data
+// scripts/test-postgres.sh starts a throwaway server and runs these tests against it
+const dsnVariable = "STUDENTS_API_TEST_POSTGRES_DSN"
+
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv(dsnVariable)
+	if dsn == "" {
+		t.Skipf("%s is not set", dsnVariable)
+	}
+
+	db, err := postgres.New(&config.Config{Storage: config.Storage{DSN: dsn}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Db.Close() })
+
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		// Restart the ids too, so every test starts from the same state
+		// The history is emptied as well, the ids it refers to are handed out again
+		if _, err := db.Db.Exec("TRUNCATE students, student_audit RESTART IDENTITY CASCADE"); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	})
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchTerms splits a free text search query into the terms every backend matches as word prefixes
+// Terms are lowercased and stripped of diacritics ("José" becomes "jose"), and everything but
+// letters and digits separates terms, so the result is safe to embed in a full-text query
+func SearchTerms(query string) []string {
+	// Decompose accented letters and drop the combining marks
+	folded := strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, norm.NFD.String(strings.ToLower(query)))
+
+	return strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,132 @@
+//go:build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// apiKeyColumns selects an API key, without its hash
+const apiKeyColumns = "id, tenant_id, name, prefix, scopes, created_by, created_at, expires_at, last_used_at, revoked_at"
+
+// scanAPIKey scans a row selected with apiKeyColumns, the scopes are stored as a JSON array
+func scanAPIKey(row interface{ Scan(...any) error }) (types.APIKey, error) {
+	var key types.APIKey
+	var scopes string
+	err := row.Scan(&key.Id, &key.Tenant, &key.Name, &key.Prefix, &scopes, &key.CreatedBy, &key.CreatedAt,
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt)
+	if err != nil {
+		return types.APIKey{}, err
+	}
+
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return types.APIKey{}, err
+	}
+	return key, nil
+}
+
+// CreateAPIKey function stores an API key of the tenant of the context under the hash of the key
+// It returns the key with its new ID, tenant and creation time
+func (s *Sqlite) CreateAPIKey(ctx context.Context, key types.APIKey, hash string) (types.APIKey, error) {
+	slog.Info("Creating an API key", slog.String("name", key.Name), slog.String("prefix", key.Prefix))
+
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return types.APIKey{}, err
+	}
+
+	key.Tenant = tenant.ID(ctx)
+	key.CreatedAt = time.Now().UTC()
+	err = s.Db.QueryRowContext(ctx, `INSERT INTO api_keys (tenant_id, name, prefix, key_hash, scopes, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`, key.Tenant, key.Name, key.Prefix, hash, string(scopes), key.CreatedBy, key.CreatedAt, key.ExpiresAt).Scan(&key.Id)
+	if err != nil {
+		return types.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// GetAPIKeys function retrieves every API key of the tenant, revoked and expired ones included, newest first
+func (s *Sqlite) GetAPIKeys(ctx context.Context) ([]types.APIKey, error) {
+	rows, err := s.Db.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE tenant_id = ? ORDER BY id DESC", tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := []types.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetAPIKeyByHash function retrieves the API key with the given hash, revoked and expired ones included
+// The key is looked up across the tenants, since it is what tells the tenant of its requests
+// It fails with storage.ErrNotFound if no key has the hash
+func (s *Sqlite) GetAPIKeyByHash(ctx context.Context, hash string) (types.APIKey, error) {
+	key, err := scanAPIKey(s.Db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ?", hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.APIKey{}, storage.UnknownAPIKey()
+		}
+		return types.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKey function revokes an API key, it is rejected from then on
+// Revoking a revoked key again keeps its first revocation time
+// It fails with storage.ErrNotFound if no key of the tenant has the ID
+func (s *Sqlite) RevokeAPIKey(ctx context.Context, id int64) (types.APIKey, error) {
+	slog.Info("Revoking an API key", slog.Int64("id", id))
+
+	key, err := scanAPIKey(s.Db.QueryRowContext(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?)
+		WHERE id = ? AND tenant_id = ?
+		RETURNING `+apiKeyColumns, time.Now().UTC(), id, tenant.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.APIKey{}, storage.APIKeyNotFound(id)
+		}
+		return types.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// TouchAPIKey function records that an API key was used at the given time
+// It fails with storage.ErrNotFound if no key has the ID
+func (s *Sqlite) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	result, err := s.Db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ?", at.UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.APIKeyNotFound(id)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+//go:build sqlite_fts5
+
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/auth"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+func TestAPIKeys(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "north-high")
+	db := newDB(t, filepath.Join(t.TempDir(), "students.db"))
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := db.CreateAPIKey(ctx, types.APIKey{Name: "nightly import", Prefix: prefix, Scopes: []string{"students:write", "grades:*"}}, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Id == 0 || created.Tenant != "north-high" {
+		t.Fatalf("got %+v, want a new key of north-high", created)
+	}
+
+	// Only the hash is stored, the key is looked up by it
+	var stored int
+	if err := db.Db.QueryRow("SELECT COUNT(*) FROM api_keys WHERE key_hash = ? AND prefix = ?", hash, key[:len(prefix)]).Scan(&stored); err != nil || stored != 1 {
+		t.Fatalf("got %d keys stored under the hash, %v", stored, err)
+	}
+	if err := db.Db.QueryRow("SELECT COUNT(*) FROM api_keys WHERE key_hash = ?", key).Scan(&stored); err != nil || stored != 0 {
+		t.Fatalf("got %d keys stored in the clear, %v", stored, err)
+	}
+	if _, err := db.GetAPIKeyByHash(ctx, auth.HashAPIKey(key+"x")); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("unknown hash: got %v, want ErrNotFound", err)
+	}
+
+	// The key authenticates its client and its use is recorded
+	authenticate := func() (auth.Identity, error) {
+		req := httptest.NewRequest("GET", "/api/students", nil)
+		req.Header.Set("Authorization", "ApiKey "+key)
+		return auth.NewAPIKeys(db).Authenticate(req)
+	}
+	identity, err := authenticate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Tenant != "north-high" || len(identity.Scopes) != 2 || identity.Scopes[1] != "grades:*" {
+		t.Fatalf("got %+v, want the scopes and tenant of the key", identity)
+	}
+	found, err := db.GetAPIKeyByHash(ctx, hash)
+	if err != nil || found.LastUsedAt == nil {
+		t.Fatalf("got %+v, %v, want its last use recorded", found, err)
+	}
+
+	// The keys of another tenant are not listed
+	listed, err := db.GetAPIKeys(tenant.WithID(context.Background(), "south-academy"))
+	if err != nil || len(listed) != 0 {
+		t.Fatalf("keys of another tenant: got %+v, %v, want none", listed, err)
+	}
+
+	// A revoked key stays listed but is rejected
+	revoked, err := db.RevokeAPIKey(ctx, created.Id)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("revoke: got %+v, %v", revoked, err)
+	}
+	if _, err := authenticate(); err == nil {
+		t.Fatal("revoked key: got no error")
+	}
+	if listed, err := db.GetAPIKeys(ctx); err != nil || len(listed) != 1 || listed[0].RevokedAt == nil {
+		t.Fatalf("keys after revoking: got %+v, %v, want the revoked key", listed, err)
+	}
+	if _, err := db.RevokeAPIKey(ctx, created.Id+1); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("revoke an unknown key: got %v, want ErrNotFound", err)
+	}
+}
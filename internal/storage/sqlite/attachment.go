@@ -0,0 +1,181 @@
+//go:build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// attachmentColumns selects the metadata of an attachment
+const attachmentColumns = "id, student_id, file_name, content_type, size, checksum, created_at, blob_key"
+
+// scanAttachment scans a row selected with attachmentColumns
+func scanAttachment(row interface{ Scan(...any) error }) (types.Attachment, error) {
+	var attachment types.Attachment
+	err := row.Scan(&attachment.Id, &attachment.StudentId, &attachment.FileName, &attachment.ContentType,
+		&attachment.Size, &attachment.Checksum, &attachment.CreatedAt, &attachment.BlobKey)
+	return attachment, err
+}
+
+// CreateAttachment function stores a file attached to a student
+// It writes the content to the blob directory, records its metadata and returns it with its new ID
+// It fails with storage.ErrNotFound if the student does not exist, in which case no file is left behind
+func (s *Sqlite) CreateAttachment(ctx context.Context, studentId int64, fileName string, contentType string, content io.Reader) (types.Attachment, error) {
+	if s.Blobs == nil {
+		return types.Attachment{}, fmt.Errorf("attachments are not available on this connection")
+	}
+
+	slog.Info("Storing an attachment", slog.Int64("student_id", studentId), slog.String("content_type", contentType))
+
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return types.Attachment{}, err
+	}
+
+	blob, err := s.Blobs.Put(content)
+	if err != nil {
+		return types.Attachment{}, err
+	}
+
+	attachment := types.Attachment{
+		StudentId:   studentId,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        blob.Size,
+		Checksum:    blob.Checksum,
+		CreatedAt:   time.Now().UTC(),
+		BlobKey:     blob.Key,
+	}
+
+	err = s.Db.QueryRowContext(ctx, `INSERT INTO attachments (student_id, blob_key, file_name, content_type, size, checksum, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`, studentId, blob.Key, fileName, contentType, blob.Size, blob.Checksum, attachment.CreatedAt).Scan(&attachment.Id)
+	if err != nil {
+		// The metadata could not be recorded, so the file would never be reachable
+		s.removeBlobs([]string{blob.Key})
+
+		if isForeignKeyViolation(err) {
+			// The student was deleted after it was looked up
+			return types.Attachment{}, storage.StudentNotFound(studentId)
+		}
+		return types.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// GetStudentAttachments function retrieves the metadata of the attachments of a student, newest first
+// It fails with storage.ErrNotFound if the student does not exist
+func (s *Sqlite) GetStudentAttachments(ctx context.Context, studentId int64) ([]types.Attachment, error) {
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Db.QueryContext(ctx, "SELECT "+attachmentColumns+" FROM attachments WHERE student_id = ? ORDER BY created_at DESC, id DESC", studentId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	attachments := []types.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+// OpenAttachment function retrieves the metadata of an attachment and opens its content
+// The caller must close the returned file, it fails with storage.ErrNotFound if the student has no such attachment
+func (s *Sqlite) OpenAttachment(ctx context.Context, studentId int64, id int64) (types.Attachment, *os.File, error) {
+	if s.Blobs == nil {
+		return types.Attachment{}, nil, fmt.Errorf("attachments are not available on this connection")
+	}
+
+	attachment, err := scanAttachment(s.Db.QueryRowContext(ctx, "SELECT "+attachmentColumns+" FROM attachments WHERE student_id = ? AND id = ? AND student_id IN "+tenantStudents,
+		studentId, id, tenant.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.Attachment{}, nil, storage.AttachmentNotFound(studentId, id)
+		}
+		return types.Attachment{}, nil, err
+	}
+
+	file, err := s.Blobs.Open(attachment.BlobKey)
+	if err != nil {
+		return types.Attachment{}, nil, err
+	}
+
+	return attachment, file, nil
+}
+
+// DeleteAttachment function deletes an attachment of a student, metadata and content
+// It fails with storage.ErrNotFound if the student has no such attachment
+func (s *Sqlite) DeleteAttachment(ctx context.Context, studentId int64, id int64) error {
+	slog.Info("Deleting an attachment", slog.Int64("student_id", studentId), slog.Int64("id", id))
+
+	var key string
+	err := s.Db.QueryRowContext(ctx, "DELETE FROM attachments WHERE student_id = ? AND id = ? AND student_id IN "+tenantStudents+" RETURNING blob_key",
+		studentId, id, tenant.ID(ctx)).Scan(&key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return storage.AttachmentNotFound(studentId, id)
+		}
+		return err
+	}
+
+	s.removeBlobs([]string{key})
+	return nil
+}
+
+// attachmentKeys returns the blob keys of the attachments of the students trashed before the given time
+// PurgeDeletedStudents calls it inside its transaction, since the foreign key removes the rows but not the files
+func attachmentKeys(ctx context.Context, tx *sql.Tx, before time.Time) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT a.blob_key FROM attachments a
+		JOIN students s ON s.id = a.student_id
+		WHERE s.deleted_at IS NOT NULL AND s.deleted_at < ?`, before.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// removeBlobs deletes attachment files whose metadata is gone
+// A file that cannot be removed is only logged: its row is already deleted, so it is unreachable either way
+func (s *Sqlite) removeBlobs(keys []string) {
+	if s.Blobs == nil {
+		return
+	}
+
+	for _, key := range keys {
+		if err := s.Blobs.Delete(key); err != nil {
+			slog.Error("Could not remove attachment file", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}
+}
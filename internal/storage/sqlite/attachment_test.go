@@ -0,0 +1,116 @@
+//go:build sqlite_fts5
+
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+)
+
+// blobFiles counts the attachment files kept next to the database in dir
+func blobFiles(t *testing.T, dir string) int {
+	t.Helper()
+
+	files := 0
+	err := filepath.WalkDir(filepath.Join(dir, "attachments"), func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			files++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestCreateAttachmentRemovesOrphanedFile(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "north-high")
+	dir := t.TempDir()
+	db := newDB(t, filepath.Join(dir, "students.db"))
+	student := newStudents(t, db, ctx, 1)[0]
+
+	// Fail the metadata insert once the file has been written
+	if _, err := db.Db.Exec(`CREATE TRIGGER fail_attachments BEFORE INSERT ON attachments BEGIN
+		SELECT RAISE(ABORT, 'attachments are read-only');
+	END`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.CreateAttachment(ctx, student, "report.pdf", "application/pdf", strings.NewReader("%PDF-1.4")); err == nil {
+		t.Fatal("CreateAttachment with a failing insert: got no error")
+	}
+	if files := blobFiles(t, dir); files != 0 {
+		t.Fatalf("got %d files left after the failed insert, want none", files)
+	}
+
+	// No file is written at all for an unknown student
+	if _, err := db.Db.Exec("DROP TRIGGER fail_attachments"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := db.CreateAttachment(ctx, student+1000, "report.pdf", "application/pdf", strings.NewReader("%PDF-1.4"))
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("CreateAttachment of an unknown student: got %v, want ErrNotFound", err)
+	}
+	if files := blobFiles(t, dir); files != 0 {
+		t.Fatalf("got %d files for an unknown student, want none", files)
+	}
+}
+
+func TestAttachmentsFollowTheTrash(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "north-high")
+	dir := t.TempDir()
+	db := newDB(t, filepath.Join(dir, "students.db"))
+	student := newStudents(t, db, ctx, 1)[0]
+
+	attachment, err := db.CreateAttachment(ctx, student, "report.pdf", "application/pdf", strings.NewReader("%PDF-1.4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The trash hides the attachment but keeps its file
+	if err := db.DeleteStudentById(ctx, student, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := db.OpenAttachment(ctx, student, attachment.Id); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("OpenAttachment of a trashed student: got %v, want ErrNotFound", err)
+	}
+	if files := blobFiles(t, dir); files != 1 {
+		t.Fatalf("got %d files while the student is in the trash, want 1", files)
+	}
+
+	// Restoring the student brings the attachment back with its content
+	if _, err := db.RestoreStudent(ctx, student); err != nil {
+		t.Fatal(err)
+	}
+	_, file, err := db.OpenAttachment(ctx, student, attachment.Id)
+	if err != nil {
+		t.Fatalf("OpenAttachment after the restore: %v", err)
+	}
+	content, err := io.ReadAll(file)
+	file.Close()
+	if err != nil || string(content) != "%PDF-1.4" {
+		t.Fatalf("content after the restore: got %q and error %v, want the uploaded file", content, err)
+	}
+
+	// Purging the trash removes the file for good
+	if err := db.DeleteStudentById(ctx, student, 0); err != nil {
+		t.Fatal(err)
+	}
+	purged, err := db.PurgeDeletedStudents(ctx, time.Now().Add(time.Second))
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeDeletedStudents: got %d and error %v, want 1 student", purged, err)
+	}
+	if files := blobFiles(t, dir); files != 0 {
+		t.Fatalf("got %d files after the purge, want none", files)
+	}
+}
@@ -0,0 +1,143 @@
+//go:build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// RecordAttendance function records the attendance of several students for one day
+// It takes the day and the records and returns them as stored and an error
+// The sheet is saved in a single transaction: an unknown or trashed student fails it as a whole with storage.ErrNotFound
+// A student who already has a record for the day gets it replaced, so a sheet can be corrected by submitting it again
+func (s *Sqlite) RecordAttendance(ctx context.Context, date string, records []types.Attendance) ([]types.Attendance, error) {
+	slog.Info("Recording attendance", slog.String("date", date), slog.Int("records", len(records)))
+
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// The record is selected from the student row, so nothing is inserted for a student of another tenant or in the trash
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO attendance (student_id, date, status, note, recorded_at)
+		SELECT id, ?, ?, ?, ? FROM students WHERE id = ? AND id IN `+tenantStudents+`
+		ON CONFLICT (student_id, date) DO UPDATE SET status = excluded.status, note = excluded.note, recorded_at = excluded.recorded_at
+		RETURNING id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer stmt.Close()
+
+	recordedAt := time.Now().UTC()
+	stored := make([]types.Attendance, 0, len(records))
+
+	for _, record := range records {
+		record.Date = date
+		record.RecordedAt = recordedAt
+
+		err := stmt.QueryRowContext(ctx, record.Date, record.Status, record.Note, record.RecordedAt, record.StudentId, tenant.ID(ctx)).Scan(&record.Id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, storage.StudentNotFound(record.StudentId)
+			}
+			return nil, err
+		}
+
+		stored = append(stored, record)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// GetStudentAttendance function retrieves the attendance records of a student between two days, oldest first
+// It fails with storage.ErrNotFound if the student does not exist
+func (s *Sqlite) GetStudentAttendance(ctx context.Context, studentId int64, from string, to string) ([]types.Attendance, error) {
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `SELECT id, student_id, date, status, note, recorded_at
+		FROM attendance
+		WHERE student_id = ? AND date BETWEEN ? AND ?
+		ORDER BY date`, studentId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := []types.Attendance{}
+	for rows.Next() {
+		var record types.Attendance
+
+		if err := rows.Scan(&record.Id, &record.StudentId, &record.Date, &record.Status, &record.Note, &record.RecordedAt); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// SummarizeAttendance function counts the attendance records between two days per student
+// A studentId of 0 summarizes every student of the tenant with at least one record in the range, ordered by ID, trashed students excepted,
+// otherwise only that student is summarized and storage.ErrNotFound is returned if they do not exist
+func (s *Sqlite) SummarizeAttendance(ctx context.Context, studentId int64, from string, to string) ([]types.AttendanceSummary, error) {
+	if studentId != 0 {
+		if _, err := s.GetStudentById(ctx, studentId); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `SELECT student_id, COUNT(*),
+			SUM(status = 'present'), SUM(status = 'absent'), SUM(status = 'late'), SUM(status = 'excused')
+		FROM attendance
+		WHERE (? = 0 OR student_id = ?) AND date BETWEEN ? AND ?
+			AND student_id IN `+tenantStudents+`
+		GROUP BY student_id
+		ORDER BY student_id`, studentId, studentId, from, to, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	summaries := []types.AttendanceSummary{}
+	for rows.Next() {
+		summary := types.AttendanceSummary{From: from, To: to}
+
+		err := rows.Scan(&summary.StudentId, &summary.Days, &summary.Present, &summary.Absent, &summary.Late, &summary.Excused)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Rate = storage.AttendanceRate(summary)
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A student without any record in the range still gets an empty summary
+	if studentId != 0 && len(summaries) == 0 {
+		summaries = append(summaries, types.AttendanceSummary{StudentId: studentId, From: from, To: to})
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,116 @@
+//go:build sqlite_fts5
+
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+func TestRecordAttendance(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "north-high")
+	db := newDB(t, filepath.Join(t.TempDir(), "students.db"))
+	students := newStudents(t, db, ctx, 3)
+
+	sheet := []types.Attendance{
+		{StudentId: students[0], Status: types.AttendancePresent},
+		{StudentId: students[1], Status: types.AttendanceAbsent},
+	}
+	stored, err := db.RecordAttendance(ctx, "2026-09-01", sheet)
+	if err != nil || len(stored) != 2 || stored[0].Id == 0 || stored[0].Date != "2026-09-01" {
+		t.Fatalf("RecordAttendance: got %+v and error %v, want both records of the day", stored, err)
+	}
+
+	// Submitting the day again corrects the records instead of adding to them
+	corrected := []types.Attendance{{StudentId: students[1], Status: types.AttendanceExcused, Note: "doctor's note"}}
+	if _, err := db.RecordAttendance(ctx, "2026-09-01", corrected); err != nil {
+		t.Fatal(err)
+	}
+	records, err := db.GetStudentAttendance(ctx, students[1], "2026-09-01", "2026-09-01")
+	if err != nil || len(records) != 1 || records[0].Status != types.AttendanceExcused || records[0].Note != "doctor's note" {
+		t.Fatalf("GetStudentAttendance after the correction: got %+v and error %v, want one excused record", records, err)
+	}
+
+	// An unknown student fails the whole sheet, the records before it are not kept either
+	unknown := []types.Attendance{
+		{StudentId: students[2], Status: types.AttendancePresent},
+		{StudentId: students[2] + 1000, Status: types.AttendancePresent},
+	}
+	if _, err := db.RecordAttendance(ctx, "2026-09-02", unknown); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("RecordAttendance with an unknown student: got %v, want ErrNotFound", err)
+	}
+	if records, err := db.GetStudentAttendance(ctx, students[2], "2026-09-02", "2026-09-02"); err != nil || len(records) != 0 {
+		t.Fatalf("GetStudentAttendance after the failed sheet: got %+v and error %v, want none", records, err)
+	}
+
+	// Students of another tenant and students in the trash cannot be marked
+	other := tenant.WithID(context.Background(), "south-academy")
+	if _, err := db.RecordAttendance(other, "2026-09-02", []types.Attendance{{StudentId: students[2], Status: types.AttendancePresent}}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("RecordAttendance of the student of another tenant: got %v, want ErrNotFound", err)
+	}
+	if err := db.DeleteStudentById(ctx, students[2], 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.RecordAttendance(ctx, "2026-09-02", []types.Attendance{{StudentId: students[2], Status: types.AttendancePresent}}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("RecordAttendance of a trashed student: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestSummarizeAttendance(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "north-high")
+	db := newDB(t, filepath.Join(t.TempDir(), "students.db"))
+	student := newStudents(t, db, ctx, 1)[0]
+
+	// Two present days, one late, one absent and two excused
+	for date, status := range map[string]string{
+		"2026-09-01": types.AttendancePresent,
+		"2026-09-02": types.AttendancePresent,
+		"2026-09-03": types.AttendanceLate,
+		"2026-09-04": types.AttendanceAbsent,
+		"2026-09-07": types.AttendanceExcused,
+		"2026-09-08": types.AttendanceExcused,
+	} {
+		if _, err := db.RecordAttendance(ctx, date, []types.Attendance{{StudentId: student, Status: status}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summaries, err := db.SummarizeAttendance(ctx, student, "2026-09-01", "2026-09-30")
+	if err != nil || len(summaries) != 1 {
+		t.Fatalf("SummarizeAttendance: got %+v and error %v, want one summary", summaries, err)
+	}
+	got := summaries[0]
+	if got.Days != 6 || got.Present != 2 || got.Late != 1 || got.Absent != 1 || got.Excused != 2 {
+		t.Fatalf("SummarizeAttendance: got %+v, want 6 days counted by status", got)
+	}
+	// Excused days are left out: 3 attended of 4 counted days, not 3 of 6
+	if got.Rate != 0.75 {
+		t.Fatalf("SummarizeAttendance rate: got %v, want 0.75", got.Rate)
+	}
+
+	// A range of excused days only has no counted day and a rate of 0
+	summaries, err = db.SummarizeAttendance(ctx, student, "2026-09-07", "2026-09-08")
+	if err != nil || len(summaries) != 1 || summaries[0].Excused != 2 || summaries[0].Rate != 0 {
+		t.Fatalf("SummarizeAttendance of excused days: got %+v and error %v, want a rate of 0", summaries, err)
+	}
+
+	// The ends of the range are included and the rate is rounded to four decimals: 2 of 3 days
+	summaries, err = db.SummarizeAttendance(ctx, student, "2026-09-02", "2026-09-04")
+	if err != nil || len(summaries) != 1 || summaries[0].Days != 3 || summaries[0].Rate != 0.6667 {
+		t.Fatalf("SummarizeAttendance of 2026-09-02 to 2026-09-04: got %+v and error %v, want a rate of 0.6667", summaries, err)
+	}
+
+	// Trashed students leave the summary of the tenant
+	if err := db.DeleteStudentById(ctx, student, 0); err != nil {
+		t.Fatal(err)
+	}
+	if summaries, err := db.SummarizeAttendance(ctx, 0, "2026-09-01", "2026-09-30"); err != nil || len(summaries) != 0 {
+		t.Fatalf("SummarizeAttendance after the trash: got %+v and error %v, want none", summaries, err)
+	}
+}
@@ -0,0 +1,198 @@
+//go:build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// CreateCourse function creates a new course in the catalog of the tenant
+// It takes the course's code, title, credits and capacity and returns the ID of the newly created course and an error
+// Course codes are unique within the tenant regardless of case, a duplicate fails with storage.ErrConflict
+func (s *Sqlite) CreateCourse(ctx context.Context, code string, title string, credits int, capacity int) (int64, error) {
+	result, err := s.Db.ExecContext(ctx, "INSERT INTO courses (tenant_id, code, title, credits, capacity) VALUES (?, ?, ?, ?, ?)",
+		tenant.ID(ctx), code, title, credits, capacity)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, storage.CourseCodeTaken(code)
+		}
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// enrolledCount counts the enrollments of the course c by the students of its tenant, the seats of trashed students are free again
+const enrolledCount = `(SELECT COUNT(*) FROM enrollments e
+	JOIN students es ON es.id = e.student_id
+	WHERE e.course_id = c.id AND es.tenant_id = c.tenant_id AND es.deleted_at IS NULL)`
+
+// courseColumns selects a course together with its number of enrollments
+const courseColumns = `c.id, c.code, c.title, c.credits, c.capacity, ` + enrolledCount
+
+// scanCourse scans a row selected with courseColumns
+func scanCourse(row interface{ Scan(...any) error }) (types.Course, error) {
+	var course types.Course
+	err := row.Scan(&course.Id, &course.Code, &course.Title, &course.Credits, &course.Capacity, &course.Enrolled)
+	return course, err
+}
+
+// GetCourseById function retrieves a course of the tenant by its ID
+// It takes the course's ID and returns the course, including its number of enrollments, and an error
+func (s *Sqlite) GetCourseById(ctx context.Context, id int64) (types.Course, error) {
+	course, err := scanCourse(s.Db.QueryRowContext(ctx, "SELECT "+courseColumns+" FROM courses c WHERE c.id = ? AND c.tenant_id = ?", id, tenant.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.Course{}, storage.CourseNotFound(id)
+		}
+		return types.Course{}, err
+	}
+
+	return course, nil
+}
+
+// GetAllCourses function retrieves every course of the catalog of the tenant ordered by code
+func (s *Sqlite) GetAllCourses(ctx context.Context) ([]types.Course, error) {
+	rows, err := s.Db.QueryContext(ctx, "SELECT "+courseColumns+" FROM courses c WHERE c.tenant_id = ? ORDER BY UPPER(c.code)", tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	courses := []types.Course{}
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, course)
+	}
+
+	return courses, rows.Err()
+}
+
+// EnrollStudent function enrolls a student in a course
+// It returns the new enrollment and an error
+// The capacity is checked by the insert itself, so concurrent enrollments can never overfill a course
+// It fails with storage.ErrNotFound for an unknown student or course, storage.ErrConflict when the student
+// is already enrolled and storage.ErrCourseFull when no seat is left
+func (s *Sqlite) EnrollStudent(ctx context.Context, studentId int64, courseId int64) (types.Enrollment, error) {
+	slog.Info("Enrolling a student", slog.Int64("student_id", studentId), slog.Int64("course_id", courseId))
+
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return types.Enrollment{}, err
+	}
+
+	enrollment := types.Enrollment{
+		StudentId:  studentId,
+		CourseId:   courseId,
+		EnrolledAt: time.Now().UTC(),
+	}
+
+	// Insert only while the course has a free seat, no row is inserted if it is full or does not exist in the tenant
+	err := s.Db.QueryRowContext(ctx, `INSERT INTO enrollments (student_id, course_id, enrolled_at)
+		SELECT ?, c.id, ? FROM courses c
+		WHERE c.id = ? AND c.tenant_id = ? AND c.capacity > `+enrolledCount+`
+		RETURNING id`, studentId, enrollment.EnrolledAt, courseId, tenant.ID(ctx)).Scan(&enrollment.Id)
+
+	switch {
+	case err == nil:
+		return enrollment, nil
+	case isUniqueViolation(err):
+		return types.Enrollment{}, storage.AlreadyEnrolled(studentId, courseId)
+	case isForeignKeyViolation(err):
+		// The student was deleted after it was looked up
+		return types.Enrollment{}, storage.StudentNotFound(studentId)
+	case err != sql.ErrNoRows:
+		return types.Enrollment{}, err
+	}
+
+	// Nothing was inserted: find out whether the course is missing, full, or already taken by the student
+	course, err := s.GetCourseById(ctx, courseId)
+	if err != nil {
+		return types.Enrollment{}, err
+	}
+
+	var enrolled bool
+	err = s.Db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM enrollments WHERE student_id = ? AND course_id = ?)", studentId, courseId).Scan(&enrolled)
+	if err != nil {
+		return types.Enrollment{}, err
+	}
+	if enrolled {
+		return types.Enrollment{}, storage.AlreadyEnrolled(studentId, courseId)
+	}
+
+	return types.Enrollment{}, storage.CourseFull(courseId, course.Capacity)
+}
+
+// GetStudentEnrollments function retrieves the enrollments of a student in the courses of the tenant, oldest first
+// It fails with storage.ErrNotFound if the student does not exist
+func (s *Sqlite) GetStudentEnrollments(ctx context.Context, studentId int64) ([]types.Enrollment, error) {
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `SELECT e.id, e.student_id, e.course_id, e.enrolled_at
+		FROM enrollments e
+		JOIN courses c ON c.id = e.course_id
+		WHERE e.student_id = ? AND c.tenant_id = ?
+		ORDER BY e.enrolled_at, e.id`, studentId, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	enrollments := []types.Enrollment{}
+	for rows.Next() {
+		var enrollment types.Enrollment
+
+		if err := rows.Scan(&enrollment.Id, &enrollment.StudentId, &enrollment.CourseId, &enrollment.EnrolledAt); err != nil {
+			return nil, err
+		}
+
+		enrollments = append(enrollments, enrollment)
+	}
+
+	return enrollments, rows.Err()
+}
+
+// GetCourseStudents function retrieves the students of the tenant enrolled in a course ordered by name
+// It fails with storage.ErrNotFound if the course does not exist
+func (s *Sqlite) GetCourseStudents(ctx context.Context, courseId int64) ([]types.Student, error) {
+	if _, err := s.GetCourseById(ctx, courseId); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `SELECT s.id, s.name, s.email, s.age, s.version
+		FROM enrollments e
+		JOIN students s ON s.id = e.student_id
+		WHERE e.course_id = ? AND s.tenant_id = ? AND s.deleted_at IS NULL
+		ORDER BY s.name, s.id`, courseId, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	students := []types.Student{}
+	for rows.Next() {
+		var student types.Student
+
+		if err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version); err != nil {
+			return nil, err
+		}
+
+		students = append(students, student)
+	}
+
+	return students, rows.Err()
+}
@@ -0,0 +1,168 @@
+//go:build sqlite_fts5
+
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/sqlite"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+)
+
+// newStudents creates n students in the tenant of ctx and returns their ids
+func newStudents(t *testing.T, db *sqlite.Sqlite, ctx context.Context, n int) []int64 {
+	t.Helper()
+
+	ids := make([]int64, n)
+	for i := range ids {
+		id, err := db.CreateStudent(ctx, fmt.Sprintf("Student %c", 'A'+i%26), fmt.Sprintf("student%d@example.edu", i), 15)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func TestEnrollStudent(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "north-high")
+	db := newDB(t, filepath.Join(t.TempDir(), "students.db"))
+	students := newStudents(t, db, ctx, 3)
+
+	course, err := db.CreateCourse(ctx, "MATH101", "Algebra", 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enrollment, err := db.EnrollStudent(ctx, students[0], course)
+	if err != nil || enrollment.Id == 0 || enrollment.StudentId != students[0] || enrollment.CourseId != course {
+		t.Fatalf("EnrollStudent: got %+v and error %v, want a new enrollment", enrollment, err)
+	}
+
+	// A student takes a seat only once
+	if _, err := db.EnrollStudent(ctx, students[0], course); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("EnrollStudent twice: got %v, want ErrConflict", err)
+	}
+
+	// The second seat is the last one
+	if _, err := db.EnrollStudent(ctx, students[1], course); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EnrollStudent(ctx, students[2], course); !errors.Is(err, storage.ErrCourseFull) {
+		t.Fatalf("EnrollStudent in a full course: got %v, want ErrCourseFull", err)
+	}
+	// A student already holding a seat of a full course is told so, not that it is full
+	if _, err := db.EnrollStudent(ctx, students[1], course); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("EnrollStudent twice in a full course: got %v, want ErrConflict", err)
+	}
+
+	// A trashed student frees their seat
+	if err := db.DeleteStudentById(ctx, students[1], 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EnrollStudent(ctx, students[2], course); err != nil {
+		t.Fatalf("EnrollStudent in a seat freed by the trash: %v", err)
+	}
+
+	// The student whose seat was taken cannot be restored into the full course, and stays in the trash
+	if _, err := db.RestoreStudent(ctx, students[1]); !errors.Is(err, storage.ErrCourseFull) {
+		t.Fatalf("RestoreStudent into a full course: got %v, want ErrCourseFull", err)
+	}
+	if full, err := db.GetCourseById(ctx, course); err != nil || full.Enrolled != 2 {
+		t.Fatalf("GetCourseById after the refused restore: got %+v and error %v, want 2 enrolled", full, err)
+	}
+	if trashed, err := db.GetDeletedStudents(ctx); err != nil || len(trashed) != 1 || trashed[0].Id != students[1] {
+		t.Fatalf("GetDeletedStudents after the refused restore: got %+v and error %v, want the student still trashed", trashed, err)
+	}
+
+	// Once a seat is free again, the student comes back with their enrollment
+	if err := db.DeleteStudentById(ctx, students[2], 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.RestoreStudent(ctx, students[1]); err != nil {
+		t.Fatalf("RestoreStudent into a free seat: %v", err)
+	}
+	if enrollments, err := db.GetStudentEnrollments(ctx, students[1]); err != nil || len(enrollments) != 1 {
+		t.Fatalf("GetStudentEnrollments after the restore: got %+v and error %v, want the enrollment back", enrollments, err)
+	}
+
+	if _, err := db.EnrollStudent(ctx, students[0], course+1000); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("EnrollStudent in an unknown course: got %v, want ErrNotFound", err)
+	}
+	if _, err := db.EnrollStudent(ctx, students[0]+1000, course); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("EnrollStudent of an unknown student: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestEnrollStudentAcrossTenants(t *testing.T) {
+	north := tenant.WithID(context.Background(), "north-high")
+	south := tenant.WithID(context.Background(), "south-academy")
+	db := newDB(t, filepath.Join(t.TempDir(), "students.db"))
+
+	student := newStudents(t, db, north, 1)[0]
+	course, err := db.CreateCourse(south, "MATH101", "Algebra", 3, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The course of another school does not exist for the student's school, and the student not for the course's
+	if _, err := db.EnrollStudent(north, student, course); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("EnrollStudent in the course of another tenant: got %v, want ErrNotFound", err)
+	}
+	if _, err := db.EnrollStudent(south, student, course); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("EnrollStudent of the student of another tenant: got %v, want ErrNotFound", err)
+	}
+
+	students, err := db.GetCourseStudents(south, course)
+	if err != nil || len(students) != 0 {
+		t.Fatalf("GetCourseStudents: got %+v and error %v, want no student", students, err)
+	}
+}
+
+func TestEnrollStudentConcurrently(t *testing.T) {
+	const capacity = 5
+	ctx := tenant.WithID(context.Background(), "north-high")
+	db := newDB(t, filepath.Join(t.TempDir(), "students.db"))
+	students := newStudents(t, db, ctx, 4*capacity)
+
+	course, err := db.CreateCourse(ctx, "MATH101", "Algebra", 3, capacity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every student races for a seat at once
+	errs := make([]error, len(students))
+	var wg sync.WaitGroup
+	for i, student := range students {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = db.EnrollStudent(ctx, student, course)
+		}()
+	}
+	wg.Wait()
+
+	enrolled := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			enrolled++
+		case !errors.Is(err, storage.ErrCourseFull):
+			t.Fatalf("EnrollStudent of student %d: got %v, want success or ErrCourseFull", students[i], err)
+		}
+	}
+	if enrolled != capacity {
+		t.Fatalf("got %d students enrolled, want exactly the capacity of %d", enrolled, capacity)
+	}
+
+	found, err := db.GetCourseById(ctx, course)
+	if err != nil || found.Enrolled != capacity {
+		t.Fatalf("GetCourseById: got %+v and error %v, want %d seats taken", found, err, capacity)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// RecordGrade function records the score of a student in a course for a term starting on termStart (YYYY-MM-DD)
+// It returns the new grade, without its letter which depends on the grading scale, and an error
+// The student must be enrolled in the course; an unknown student or course fails with storage.ErrNotFound,
+// a missing enrollment or a second grade for the same term with storage.ErrConflict
+func (s *Sqlite) RecordGrade(ctx context.Context, studentId int64, courseId int64, term string, termStart string, score float64) (types.Grade, error) {
+	slog.Info("Recording a grade", slog.Int64("student_id", studentId), slog.Int64("course_id", courseId), slog.String("term", term))
+
+	grade := types.Grade{
+		StudentId: studentId,
+		CourseId:  courseId,
+		Term:      term,
+		TermStart: termStart,
+		Score:     &score,
+		GradedAt:  time.Now().UTC(),
+	}
+
+	// Insert only if the student of the tenant is enrolled in the course
+	err := s.Db.QueryRowContext(ctx, `INSERT INTO grades (student_id, course_id, term, term_start, score, graded_at)
+		SELECT student_id, course_id, ?, ?, ?, ? FROM enrollments
+		WHERE student_id = ? AND course_id = ? AND student_id IN `+tenantStudents+`
+		RETURNING id`, term, termStart, score, grade.GradedAt, studentId, courseId, tenant.ID(ctx)).Scan(&grade.Id)
+
+	switch {
+	case err == nil:
+		return grade, nil
+	case isUniqueViolation(err):
+		return types.Grade{}, storage.GradeExists(studentId, courseId, term)
+	case err != sql.ErrNoRows:
+		return types.Grade{}, err
+	}
+
+	// Nothing was inserted: report a missing student or course before the missing enrollment
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return types.Grade{}, err
+	}
+	if _, err := s.GetCourseById(ctx, courseId); err != nil {
+		return types.Grade{}, err
+	}
+
+	return types.Grade{}, storage.NotEnrolled(studentId, courseId)
+}
+
+// GetStudentGrades function retrieves every grade of a student together with its course
+// The grades are ordered by the start of their term, term and course code, it fails with storage.ErrNotFound if the student does not exist
+func (s *Sqlite) GetStudentGrades(ctx context.Context, studentId int64) ([]types.CourseGrade, error) {
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `SELECT g.id, g.student_id, g.course_id, g.term, g.term_start, g.score, g.graded_at, `+courseColumns+`
+		FROM grades g
+		JOIN courses c ON c.id = g.course_id
+		WHERE g.student_id = ? AND c.tenant_id = ?
+		ORDER BY g.term_start, g.term, UPPER(c.code)`, studentId, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	grades := []types.CourseGrade{}
+	for rows.Next() {
+		var grade types.CourseGrade
+
+		err := rows.Scan(&grade.Id, &grade.StudentId, &grade.CourseId, &grade.Term, &grade.TermStart, &grade.Score, &grade.GradedAt,
+			&grade.Course.Id, &grade.Course.Code, &grade.Course.Title, &grade.Course.Credits, &grade.Course.Capacity, &grade.Course.Enrolled)
+		if err != nil {
+			return nil, err
+		}
+
+		grades = append(grades, grade)
+	}
+
+	return grades, rows.Err()
+}
@@ -0,0 +1,85 @@
+//go:build sqlite_fts5
+
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+)
+
+func TestGetStudentGradesByTermStart(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "north-high")
+	db := newDB(t, filepath.Join(t.TempDir(), "students.db"))
+	student := newStudents(t, db, ctx, 1)[0]
+
+	var courses []int64
+	for _, code := range []string{"CS101", "HI101"} {
+		course, err := db.CreateCourse(ctx, code, code, 3, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.EnrollStudent(ctx, student, course); err != nil {
+			t.Fatal(err)
+		}
+		courses = append(courses, course)
+	}
+
+	// The Fall 2023 grade is entered last, it is still listed first
+	if _, err := db.RecordGrade(ctx, student, courses[0], "Spring 2024", "2024-01-15", 90); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.RecordGrade(ctx, student, courses[1], "Fall 2023", "2023-09-04", 80); err != nil {
+		t.Fatal(err)
+	}
+
+	grades, err := db.GetStudentGrades(ctx, student)
+	if err != nil || len(grades) != 2 {
+		t.Fatalf("GetStudentGrades: got %+v and error %v, want 2 grades", grades, err)
+	}
+	if grades[0].Term != "Fall 2023" || grades[0].TermStart != "2023-09-04" || grades[1].Term != "Spring 2024" {
+		t.Fatalf("GetStudentGrades: got %+v, want Fall 2023 first", grades)
+	}
+}
+
+func TestTermStartMigrationKeepsOrder(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "north-high")
+	db := newDB(t, filepath.Join(t.TempDir(), "students.db"))
+	student := newStudents(t, db, ctx, 1)[0]
+
+	course, err := db.CreateCourse(ctx, "CS101", "Computing", 3, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EnrollStudent(ctx, student, course); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.RecordGrade(ctx, student, course, "Fall 2025", "2025-09-01", 90); err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := db.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Down(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Before the column, a term was placed by the day it was first graded
+	graded := time.Date(2025, 12, 15, 10, 30, 0, 0, time.UTC)
+	if _, err := db.Db.Exec("UPDATE grades SET graded_at = ?", graded); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	grades, err := db.GetStudentGrades(ctx, student)
+	if err != nil || len(grades) != 1 || grades[0].TermStart != "2025-12-15" {
+		t.Fatalf("GetStudentGrades after the migration: got %+v and error %v, want the term starting on the day it was graded", grades, err)
+	}
+}
@@ -0,0 +1,240 @@
+//go:build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// guardianColumns selects a guardian together with its link to a student
+const guardianColumns = "g.id, g.name, sg.relationship, g.phone, g.email, sg.priority"
+
+// scanGuardian scans a row selected with guardianColumns
+func scanGuardian(row interface{ Scan(...any) error }) (types.Guardian, error) {
+	var guardian types.Guardian
+	err := row.Scan(&guardian.Id, &guardian.Name, &guardian.Relationship, &guardian.Phone, &guardian.Email, &guardian.Priority)
+	return guardian, err
+}
+
+// guardianKnown reports whether the guardian belongs to the tenant of the context
+// Another school's guardian is reported as unknown instead of being linked or listed
+func (s *Sqlite) guardianKnown(ctx context.Context, guardianId int64) (bool, error) {
+	var known bool
+	err := s.Db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM guardians WHERE id = ? AND tenant_id = ?)", guardianId, tenant.ID(ctx)).Scan(&known)
+	return known, err
+}
+
+// CreateGuardian function creates a new guardian in the tenant of the student and links them to the student
+// It returns the guardian with its new ID and an error, storage.ErrNotFound if the student does not exist
+func (s *Sqlite) CreateGuardian(ctx context.Context, studentId int64, guardian types.Guardian) (types.Guardian, error) {
+	slog.Info("Creating a guardian", slog.Int64("student_id", studentId))
+
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return types.Guardian{}, err
+	}
+
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return types.Guardian{}, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "INSERT INTO guardians (tenant_id, name, phone, email) VALUES (?, ?, ?, ?)",
+		tenant.ID(ctx), guardian.Name, guardian.Phone, guardian.Email)
+	if err != nil {
+		return types.Guardian{}, err
+	}
+	if guardian.Id, err = result.LastInsertId(); err != nil {
+		return types.Guardian{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO student_guardians (student_id, guardian_id, relationship, priority) VALUES (?, ?, ?, ?)",
+		studentId, guardian.Id, guardian.Relationship, guardian.Priority)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			// The student was deleted after it was looked up
+			return types.Guardian{}, storage.StudentNotFound(studentId)
+		}
+		return types.Guardian{}, err
+	}
+
+	return guardian, tx.Commit()
+}
+
+// LinkGuardian function links an existing guardian to another student
+// It returns the guardian as seen from that student and an error: storage.ErrNotFound for an unknown
+// student or guardian, storage.ErrConflict if they are already linked
+func (s *Sqlite) LinkGuardian(ctx context.Context, studentId int64, guardianId int64, link types.GuardianLink) (types.Guardian, error) {
+	slog.Info("Linking a guardian", slog.Int64("student_id", studentId), slog.Int64("guardian_id", guardianId))
+
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return types.Guardian{}, err
+	}
+	if known, err := s.guardianKnown(ctx, guardianId); err != nil {
+		return types.Guardian{}, err
+	} else if !known {
+		return types.Guardian{}, storage.GuardianNotFound(guardianId)
+	}
+
+	_, err := s.Db.ExecContext(ctx, "INSERT INTO student_guardians (student_id, guardian_id, relationship, priority) VALUES (?, ?, ?, ?)",
+		studentId, guardianId, link.Relationship, link.Priority)
+	switch {
+	case isUniqueViolation(err), isPrimaryKeyViolation(err):
+		return types.Guardian{}, storage.GuardianAlreadyLinked(studentId, guardianId)
+	case isForeignKeyViolation(err):
+		return types.Guardian{}, storage.GuardianNotFound(guardianId)
+	case err != nil:
+		return types.Guardian{}, err
+	}
+
+	return s.GetStudentGuardian(ctx, studentId, guardianId)
+}
+
+// GetStudentGuardians function retrieves the guardians of a student, the ones to contact first come first
+// It fails with storage.ErrNotFound if the student does not exist
+func (s *Sqlite) GetStudentGuardians(ctx context.Context, studentId int64) ([]types.Guardian, error) {
+	if _, err := s.GetStudentById(ctx, studentId); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Db.QueryContext(ctx, "SELECT "+guardianColumns+`
+		FROM student_guardians sg
+		JOIN guardians g ON g.id = sg.guardian_id
+		WHERE sg.student_id = ? AND g.tenant_id = ?
+		ORDER BY sg.priority, g.name, g.id`, studentId, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	guardians := []types.Guardian{}
+	for rows.Next() {
+		guardian, err := scanGuardian(rows)
+		if err != nil {
+			return nil, err
+		}
+		guardians = append(guardians, guardian)
+	}
+
+	return guardians, rows.Err()
+}
+
+// GetStudentGuardian function retrieves one guardian of a student
+// It fails with storage.ErrNotFound if the guardian is not linked to the student
+func (s *Sqlite) GetStudentGuardian(ctx context.Context, studentId int64, guardianId int64) (types.Guardian, error) {
+	guardian, err := scanGuardian(s.Db.QueryRowContext(ctx, "SELECT "+guardianColumns+`
+		FROM student_guardians sg
+		JOIN guardians g ON g.id = sg.guardian_id
+		WHERE sg.student_id = ? AND sg.guardian_id = ? AND g.tenant_id = ? AND sg.student_id IN `+tenantStudents,
+		studentId, guardianId, tenant.ID(ctx), tenant.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.Guardian{}, storage.GuardianNotLinked(studentId, guardianId)
+		}
+		return types.Guardian{}, err
+	}
+
+	return guardian, nil
+}
+
+// UpdateStudentGuardian function updates a guardian of a student
+// The contact details change for every student of the guardian, the relationship and priority only for this one
+// It fails with storage.ErrNotFound if the guardian is not linked to the student
+func (s *Sqlite) UpdateStudentGuardian(ctx context.Context, studentId int64, guardian types.Guardian) (types.Guardian, error) {
+	slog.Info("Updating a guardian", slog.Int64("student_id", studentId), slog.Int64("guardian_id", guardian.Id))
+
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return types.Guardian{}, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE student_guardians SET relationship = ?, priority = ? WHERE student_id = ? AND guardian_id = ? AND student_id IN "+tenantStudents,
+		guardian.Relationship, guardian.Priority, studentId, guardian.Id, tenant.ID(ctx))
+	if err != nil {
+		return types.Guardian{}, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return types.Guardian{}, err
+	} else if affected == 0 {
+		return types.Guardian{}, storage.GuardianNotLinked(studentId, guardian.Id)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE guardians SET name = ?, phone = ?, email = ? WHERE id = ? AND tenant_id = ?",
+		guardian.Name, guardian.Phone, guardian.Email, guardian.Id, tenant.ID(ctx))
+	if err != nil {
+		return types.Guardian{}, err
+	}
+
+	return guardian, tx.Commit()
+}
+
+// UnlinkGuardian function removes a guardian from a student
+// The guardian is deleted once no student is linked to them anymore
+// It fails with storage.ErrNotFound if the guardian is not linked to the student
+func (s *Sqlite) UnlinkGuardian(ctx context.Context, studentId int64, guardianId int64) error {
+	slog.Info("Unlinking a guardian", slog.Int64("student_id", studentId), slog.Int64("guardian_id", guardianId))
+
+	result, err := s.Db.ExecContext(ctx, "DELETE FROM student_guardians WHERE student_id = ? AND guardian_id = ? AND student_id IN "+tenantStudents,
+		studentId, guardianId, tenant.ID(ctx))
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.GuardianNotLinked(studentId, guardianId)
+	}
+
+	return nil
+}
+
+// GetGuardianStudents function retrieves every student of the tenant linked to a guardian ordered by name
+// It fails with storage.ErrNotFound if the guardian does not exist in the tenant
+func (s *Sqlite) GetGuardianStudents(ctx context.Context, guardianId int64) ([]types.Student, error) {
+	known, err := s.guardianKnown(ctx, guardianId)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, storage.GuardianNotFound(guardianId)
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `SELECT s.id, s.name, s.email, s.age, s.version
+		FROM student_guardians sg
+		JOIN students s ON s.id = sg.student_id
+		WHERE sg.guardian_id = ? AND s.tenant_id = ? AND s.deleted_at IS NULL
+		ORDER BY s.name, s.id`, guardianId, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	students := []types.Student{}
+	for rows.Next() {
+		var student types.Student
+
+		if err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version); err != nil {
+			return nil, err
+		}
+
+		students = append(students, student)
+	}
+
+	return students, rows.Err()
+}
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS students (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT,
+	email TEXT,
+	age INTEGER
+);
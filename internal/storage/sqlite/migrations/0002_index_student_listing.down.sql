@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_students_name;
+DROP INDEX IF EXISTS idx_students_email;
+DROP INDEX IF EXISTS idx_students_age;
@@ -0,0 +1,4 @@
+-- Indexes backing the sort orders and filters of GET /api/students
+CREATE INDEX IF NOT EXISTS idx_students_name ON students (name, id);
+CREATE INDEX IF NOT EXISTS idx_students_email ON students (email, id);
+CREATE INDEX IF NOT EXISTS idx_students_age ON students (age, id);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS students_fts_insert;
+DROP TRIGGER IF EXISTS students_fts_delete;
+DROP TRIGGER IF EXISTS students_fts_update;
+DROP TABLE IF EXISTS students_fts;
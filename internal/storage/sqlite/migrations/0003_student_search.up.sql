@@ -0,0 +1,27 @@
+-- External content FTS5 index over the searchable student columns.
+-- It requires a go-sqlite3 build with FTS5 enabled (go build -tags sqlite_fts5).
+CREATE VIRTUAL TABLE IF NOT EXISTS students_fts USING fts5(
+	name,
+	email,
+	content = 'students',
+	content_rowid = 'id',
+	tokenize = 'unicode61 remove_diacritics 2',
+	prefix = '2 3'
+);
+
+-- Keep the index in sync with the students table.
+CREATE TRIGGER IF NOT EXISTS students_fts_insert AFTER INSERT ON students BEGIN
+	INSERT INTO students_fts (rowid, name, email) VALUES (new.id, new.name, new.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS students_fts_delete AFTER DELETE ON students BEGIN
+	INSERT INTO students_fts (students_fts, rowid, name, email) VALUES ('delete', old.id, old.name, old.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS students_fts_update AFTER UPDATE ON students BEGIN
+	INSERT INTO students_fts (students_fts, rowid, name, email) VALUES ('delete', old.id, old.name, old.email);
+	INSERT INTO students_fts (rowid, name, email) VALUES (new.id, new.name, new.email);
+END;
+
+-- Index the students that existed before this migration.
+INSERT INTO students_fts (students_fts) VALUES ('rebuild');
@@ -0,0 +1,22 @@
+-- The course catalog, codes are unique regardless of case.
+CREATE TABLE IF NOT EXISTS courses (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	code TEXT NOT NULL,
+	title TEXT NOT NULL,
+	credits INTEGER NOT NULL DEFAULT 0,
+	capacity INTEGER NOT NULL CHECK (capacity > 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_courses_code_unique ON courses (UPPER(code));
+
+-- A student enrolls at most once in a course, enrollments go away with their student or course.
+CREATE TABLE IF NOT EXISTS enrollments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	course_id INTEGER NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+	enrolled_at TIMESTAMP NOT NULL,
+	UNIQUE (student_id, course_id)
+);
+
+-- Capacity checks and class lists look enrollments up by course.
+CREATE INDEX IF NOT EXISTS idx_enrollments_course ON enrollments (course_id);
@@ -0,0 +1,10 @@
+-- One score out of 100 per student, course and term, grades go away with their student or course.
+CREATE TABLE IF NOT EXISTS grades (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	course_id INTEGER NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+	term TEXT NOT NULL,
+	score REAL NOT NULL CHECK (score >= 0 AND score <= 100),
+	graded_at TIMESTAMP NOT NULL,
+	UNIQUE (student_id, course_id, term)
+);
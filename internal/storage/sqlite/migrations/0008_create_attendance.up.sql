@@ -0,0 +1,13 @@
+-- One attendance record per student and day, dates are stored as YYYY-MM-DD so they compare as text.
+CREATE TABLE IF NOT EXISTS attendance (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	date TEXT NOT NULL,
+	status TEXT NOT NULL CHECK (status IN ('present', 'absent', 'late', 'excused')),
+	note TEXT NOT NULL DEFAULT '',
+	recorded_at TIMESTAMP NOT NULL,
+	UNIQUE (student_id, date)
+);
+
+-- Class wide summaries scan a date range across every student.
+CREATE INDEX IF NOT EXISTS idx_attendance_date ON attendance (date);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS student_guardians_orphan;
+DROP TABLE IF EXISTS student_guardians;
+DROP TABLE IF EXISTS guardians;
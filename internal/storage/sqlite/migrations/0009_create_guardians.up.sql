@@ -0,0 +1,25 @@
+-- Guardians and emergency contacts, shared by the students they are linked to.
+CREATE TABLE IF NOT EXISTS guardians (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	phone TEXT NOT NULL,
+	email TEXT NOT NULL DEFAULT ''
+);
+
+-- The relationship and contact priority belong to the link, e.g. a parent of two siblings.
+CREATE TABLE IF NOT EXISTS student_guardians (
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	guardian_id INTEGER NOT NULL REFERENCES guardians (id) ON DELETE CASCADE,
+	relationship TEXT NOT NULL,
+	priority INTEGER NOT NULL DEFAULT 1,
+	PRIMARY KEY (student_id, guardian_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_guardians_guardian ON student_guardians (guardian_id);
+
+-- A guardian without any student left is removed, whether the link or the student was deleted.
+CREATE TRIGGER IF NOT EXISTS student_guardians_orphan AFTER DELETE ON student_guardians
+WHEN NOT EXISTS (SELECT 1 FROM student_guardians WHERE guardian_id = old.guardian_id)
+BEGIN
+	DELETE FROM guardians WHERE id = old.guardian_id;
+END;
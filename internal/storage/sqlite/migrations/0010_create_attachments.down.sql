@@ -0,0 +1,3 @@
+-- The files of the attachments live outside the database, a migration cannot reach the blob directory:
+-- they stay on disk and the blob directory may be removed by hand once the table is gone.
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,13 @@
+-- Metadata of the files attached to students, the content lives in the blob directory under blob_key.
+CREATE TABLE IF NOT EXISTS attachments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	blob_key TEXT NOT NULL UNIQUE,
+	file_name TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	checksum TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_student ON attachments (student_id);
@@ -0,0 +1,9 @@
+-- Trashed students cannot be represented without the column, so they are deleted for good.
+-- Their attachments follow through the foreign key, but a migration cannot reach the blob directory:
+-- the files of those attachments stay on disk, the blobs whose key no attachment has may be removed by hand.
+DELETE FROM students WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_students_deleted_at;
+DROP INDEX IF EXISTS idx_students_email_unique;
+ALTER TABLE students DROP COLUMN deleted_at;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_unique ON students (LOWER(email));
@@ -0,0 +1,9 @@
+-- Deleted students are kept in the trash until they are restored or purged.
+ALTER TABLE students ADD COLUMN deleted_at TIMESTAMP;
+
+-- A trashed student no longer holds on to their email, it may be reused by a new student.
+DROP INDEX IF EXISTS idx_students_email_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_unique ON students (LOWER(email)) WHERE deleted_at IS NULL;
+
+-- The trash listing and the purge job look trashed students up by deletion time.
+CREATE INDEX IF NOT EXISTS idx_students_deleted_at ON students (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS student_audit_no_delete;
+DROP TRIGGER IF EXISTS student_audit_no_update;
+DROP INDEX IF EXISTS idx_student_audit_student;
+DROP TABLE IF EXISTS student_audit;
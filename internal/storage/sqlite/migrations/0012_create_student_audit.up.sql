@@ -0,0 +1,24 @@
+-- The history of every student change, appended in the same transaction as the change itself.
+-- It has no foreign key on purpose: the history outlives a purged student.
+CREATE TABLE IF NOT EXISTS student_audit (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	request_id TEXT NOT NULL DEFAULT '',
+	at TIMESTAMP NOT NULL,
+	before_state TEXT,
+	after_state TEXT,
+	changes TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_audit_student ON student_audit (student_id, id);
+
+-- The history is append-only, recorded entries can neither be changed nor removed.
+CREATE TRIGGER IF NOT EXISTS student_audit_no_update BEFORE UPDATE ON student_audit BEGIN
+	SELECT RAISE(ABORT, 'student_audit is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS student_audit_no_delete BEFORE DELETE ON student_audit BEGIN
+	SELECT RAISE(ABORT, 'student_audit is append-only');
+END;
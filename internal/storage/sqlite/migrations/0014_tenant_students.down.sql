@@ -0,0 +1,16 @@
+-- The students and API keys of other tenants cannot be represented without the column, so they are deleted for good.
+-- Their history is append-only, it stays behind like the history of a purged student.
+DELETE FROM students WHERE tenant_id <> 'default';
+DELETE FROM api_keys WHERE tenant_id <> 'default';
+
+DROP INDEX IF EXISTS idx_api_keys_tenant;
+ALTER TABLE api_keys DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_student_audit_student;
+ALTER TABLE student_audit DROP COLUMN tenant_id;
+CREATE INDEX IF NOT EXISTS idx_student_audit_student ON student_audit (student_id, id);
+
+DROP INDEX IF EXISTS idx_students_tenant;
+DROP INDEX IF EXISTS idx_students_email_unique;
+ALTER TABLE students DROP COLUMN tenant_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_unique ON students (LOWER(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+-- Every student belongs to a tenant (school), the students stored so far belong to the "default" tenant.
+ALTER TABLE students ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Emails are unique within a tenant, two schools may each have a student with the same email.
+DROP INDEX IF EXISTS idx_students_email_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_unique ON students (tenant_id, LOWER(email)) WHERE deleted_at IS NULL;
+
+-- Every students query is scoped to a tenant.
+CREATE INDEX IF NOT EXISTS idx_students_tenant ON students (tenant_id, id);
+
+-- The history of a student and the API keys belong to the tenant of the student and of the issuer.
+ALTER TABLE student_audit ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+DROP INDEX IF EXISTS idx_student_audit_student;
+CREATE INDEX IF NOT EXISTS idx_student_audit_student ON student_audit (tenant_id, student_id, id);
+
+ALTER TABLE api_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys (tenant_id, id);
@@ -0,0 +1,11 @@
+-- The courses and guardians of other tenants cannot be represented without the column, so they are deleted for good,
+-- together with their enrollments, grades and links.
+DELETE FROM courses WHERE tenant_id <> 'default';
+DELETE FROM guardians WHERE tenant_id <> 'default';
+
+DROP INDEX IF EXISTS idx_guardians_tenant;
+ALTER TABLE guardians DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_courses_code_unique;
+ALTER TABLE courses DROP COLUMN tenant_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_courses_code_unique ON courses (UPPER(code));
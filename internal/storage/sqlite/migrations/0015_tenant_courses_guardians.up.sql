@@ -0,0 +1,15 @@
+-- Every course belongs to a tenant (school), the courses stored so far belong to the "default" tenant.
+-- The enrollments of other tenants' students in them stay behind, they are neither listed nor take a seat.
+ALTER TABLE courses ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Course codes are unique within a tenant, two schools may each teach MATH101.
+DROP INDEX IF EXISTS idx_courses_code_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_courses_code_unique ON courses (tenant_id, UPPER(code));
+
+-- Every guardian belongs to the tenant of their students, a guardian could only be linked within one tenant.
+ALTER TABLE guardians ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+UPDATE guardians SET tenant_id = COALESCE((SELECT s.tenant_id FROM student_guardians sg
+	JOIN students s ON s.id = sg.student_id
+	WHERE sg.guardian_id = guardians.id LIMIT 1), 'default');
+
+CREATE INDEX IF NOT EXISTS idx_guardians_tenant ON guardians (tenant_id, id);
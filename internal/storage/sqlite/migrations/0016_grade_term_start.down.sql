@@ -0,0 +1 @@
+ALTER TABLE grades DROP COLUMN term_start;
@@ -0,0 +1,5 @@
+-- Terms are listed in the order they ran, which the free text term name does not tell.
+-- The grades stored so far start their term on the day the student was first graded in it, the order used until now.
+ALTER TABLE grades ADD COLUMN term_start TEXT NOT NULL DEFAULT '';
+UPDATE grades SET term_start = (SELECT SUBSTR(MIN(g.graded_at), 1, 10) FROM grades g
+	WHERE g.student_id = grades.student_id AND g.term = grades.term);
@@ -1,56 +1,150 @@
+//go:build sqlite_fts5
+
+// Package sqlite is the default storage backend, a SQLite database at the configured storage path
+// It is only built with the sqlite_fts5 tag, which makes go-sqlite3 compile in the FTS5 extension
+// the student search index needs: go build -tags sqlite_fts5 ./..., or make build
 package sqlite
 
 import (
+	"context"
 	"database/sql" // Import the database/sql package for SQL database operations
+	"embed"        // Import the embed package to ship the migration files inside the binary
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/Priyang1310/Students-API-GO/internal/config" // Import the config package for application configuration
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/blob"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/migrate"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
 	"github.com/Priyang1310/Students-API-GO/internal/types"
-	_ "github.com/mattn/go-sqlite3" // Import the SQLite driver for database operations
+	"github.com/mattn/go-sqlite3" // Import the SQLite driver for database operations and its error codes
 )
 
 // Sqlite struct represents a SQLite database connection
 type Sqlite struct {
-	Db *sql.DB // Db is a pointer to the sql.DB type, which represents a database connection
+	Db    *sql.DB     // Db is a pointer to the sql.DB type, which represents a database connection
+	Blobs *blob.Store // Blobs holds the content of the attachments, it is nil when opened by the migrate subcommand
+}
+
+// migrations holds the versioned schema migrations applied by New
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// isUniqueViolation reports whether err was caused by a UNIQUE constraint, e.g. the one on students.email
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// isForeignKeyViolation reports whether err was caused by a FOREIGN KEY constraint, e.g. an enrollment of an unknown student
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
+
+// isPrimaryKeyViolation reports whether err was caused by a PRIMARY KEY constraint, e.g. a link stored twice
+func isPrimaryKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+}
+
+// tenantStudents selects the IDs of the students of one tenant that are not in the trash, its only parameter is the tenant
+// The records keyed by a student ID, e.g. grades or attachments, are filtered with it, so a tenant never reaches another's
+// and the records of a trashed student are out of reach like the student, until it is restored
+const tenantStudents = "(SELECT id FROM students WHERE tenant_id = ? AND deleted_at IS NULL)"
+
+// connectionOptions are added to the DSN of the database
+// SQLite leaves foreign keys off by default, and the enrollments rely on them to cascade deletions
+// Transactions begin IMMEDIATE, i.e. take the write lock up front: every transaction writes, most after reading
+// the student first, and two DEFERRED ones holding the read lock could never upgrade it, so one failed with
+// "database is locked" instead of waiting for the other
+const connectionOptions = "_foreign_keys=on&_txlock=immediate"
+
+// dsn returns the DSN of the database at path with the connection options
+func dsn(path string) string {
+	if strings.Contains(path, "?") {
+		return path + "&" + connectionOptions
+	}
+	return path + "?" + connectionOptions
+}
+
+// Open function opens the SQLite database at the configured storage path without touching its schema
+// It is used by the migrate subcommand, everything else should use New
+func Open(cfg *config.Config) (*Sqlite, error) {
+	// Open a new database connection using the SQLite driver and the storage path from the config
+	// Foreign keys are enforced on every connection, so enrollments and the like follow their student
+	db, err := sql.Open("sqlite3", dsn(cfg.StoragePath))
+	if err != nil {
+		// If there is an error opening the database, return nil and the error
+		return nil, err
+	}
+
+	return &Sqlite{
+		Db: db, // Assign the database connection to the Db field of the Sqlite struct
+	}, nil
 }
 
 // New function initializes a new Sqlite instance
 // It takes a configuration object as an argument and returns a pointer to Sqlite and an error
-// This function is used to establish a connection to the SQLite database
+// This function is used to establish a connection to the SQLite database and bring its schema up to date
 func New(cfg *config.Config) (*Sqlite, error) {
-	// Open a new database connection using the SQLite driver and the storage path from the config
-	db, err := sql.Open("sqlite3", cfg.StoragePath)
+	s, err := Open(cfg)
 	if err != nil {
-		// If there is an error opening the database, return nil and the error
 		return nil, err
 	}
 
-	// Execute a SQL command to create the 'students' table if it does not already exist
-	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS students (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT, 
-		email TEXT, 
-		age INTEGER 
-	)`)
+	// Apply every pending schema migration before the database is used
+	migrator, err := s.Migrator()
+	if err != nil {
+		s.Db.Close()
+		return nil, err
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		s.Db.Close()
+		return nil, err
+	}
+
+	// Attachments are kept as files next to the database
+	if s.Blobs, err = blob.New(cfg.AttachmentDir()); err != nil {
+		s.Db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
 
+// Migrator function returns a migrate.Migrator for the embedded SQLite migrations
+func (s *Sqlite) Migrator() (*migrate.Migrator, error) {
+	files, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		// If there is an error executing the SQL command, return nil and the error
 		return nil, err
 	}
 
-	// Return a new Sqlite instance with the established database connection
-	return &Sqlite{
-		Db: db, // Assign the database connection to the Db field of the Sqlite struct
-	}, nil
+	return migrate.New(s.Db, files, migrate.Question)
 }
 
 // CreateStudent function creates a new student in the database
 // It takes the student's name, email, and age as arguments and returns the ID of the newly created student and an error
-// This function is used to insert a new student into the 'students' table
-func (s *Sqlite) CreateStudent(name string, email string, age int) (int64, error) {
-	// Prepare a SQL statement to insert a new student into the 'students' table
-	stmt, err := s.Db.Prepare("INSERT INTO students (name,email,age) VALUES (?,?,?) ")
+// This function is used to insert a new student into the 'students' table and record the creation in their history
+func (s *Sqlite) CreateStudent(ctx context.Context, name string, email string, age int) (int64, error) {
+	// Insert and record the history in one transaction, so no change is ever missing from it
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Prepare a SQL statement to insert a new student of the tenant into the 'students' table
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO students (tenant_id,name,email,age) VALUES (?,?,?,?) ")
 	if err != nil {
 		return 0, err
 	}
@@ -58,9 +152,13 @@ func (s *Sqlite) CreateStudent(name string, email string, age int) (int64, error
 	defer stmt.Close()
 
 	// Execute the prepared SQL statement with the provided student data
-	result, err := stmt.Exec(name, email, age)
+	result, err := stmt.ExecContext(ctx, tenant.ID(ctx), name, email, age)
 
 	if err != nil {
+		// The email is already used by another student
+		if isUniqueViolation(err) {
+			return 0, storage.EmailTaken(email)
+		}
 		return 0, err
 	}
 
@@ -70,15 +168,22 @@ func (s *Sqlite) CreateStudent(name string, email string, age int) (int64, error
 		return 0, err
 	}
 
-	return id, nil
+	// A new student starts at version 1
+	created := types.Student{Id: id, Name: name, Email: email, Age: age, Version: 1}
+	if err := recordChange(ctx, tx, types.AuditCreate, nil, &created); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
 }
 
 // GetStudentById function retrieves a student from the database by their ID
 // It takes the student's ID as an argument and returns the student data and an error
 // This function is used to select a student from the 'students' table by their ID
-func (s *Sqlite) GetStudentById(id int64) (types.Student, error) {
+// Like every students query, it only sees the students of the tenant of the context
+func (s *Sqlite) GetStudentById(ctx context.Context, id int64) (types.Student, error) {
 	// Prepare a SQL statement to select a student from the 'students' table by their ID
-	stmt, err := s.Db.Prepare("SELECT * FROM students WHERE id = ?")
+	stmt, err := s.Db.PrepareContext(ctx, "SELECT id,name,email,age,version FROM students WHERE id = ? AND tenant_id = ? AND deleted_at IS NULL")
 	if err != nil {
 		return types.Student{}, err
 	}
@@ -88,12 +193,12 @@ func (s *Sqlite) GetStudentById(id int64) (types.Student, error) {
 	// Execute the prepared SQL statement with the provided student ID
 	var student types.Student
 
-	err = stmt.QueryRow(id).Scan(&student.Id, &student.Name, &student.Email, &student.Age)
+	err = stmt.QueryRowContext(ctx, id, tenant.ID(ctx)).Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
 
 	if err != nil {
 		// If the student is not found, return an error
 		if err == sql.ErrNoRows {
-			return types.Student{}, fmt.Errorf("student not found with id %s", fmt.Sprint(id))
+			return types.Student{}, storage.StudentNotFound(id)
 		}
 		return types.Student{}, err
 	}
@@ -101,124 +206,370 @@ func (s *Sqlite) GetStudentById(id int64) (types.Student, error) {
 	return student, nil
 }
 
-// GetAllStudents function retrieves all students from the database
-// It returns a slice of student data and an error
-// This function is used to select all students from the 'students' table
-func (s *Sqlite) GetAllStudents() ([]types.Student, error) {
-	// Prepare a SQL statement to select all students from the 'students' table
-	stmt, err := s.Db.Prepare("SELECT id,name,email,age FROM students")
+// GetAllStudents function retrieves one page of students from the database
+// It takes the listing options (filters, sort order, limit and cursor or offset) and returns the page and an error
+// The cursor is a keyset over the sort columns, so deep pages stay as cheap as the first one
+func (s *Sqlite) GetAllStudents(ctx context.Context, opts storage.ListOptions) (storage.StudentPage, error) {
 	slog.Info("Get all students method called")
+
+	// Apply the default limit and the id tie breaker to the sort order
+	if err := opts.Normalize(); err != nil {
+		return storage.StudentPage{}, err
+	}
+
+	// Build the WHERE clause from the filters, trashed students and other tenants are never listed
+	filter, args := opts.SQLFilter(migrate.Question)
+	where := " WHERE deleted_at IS NULL"
+	if filter != "" {
+		where += " AND " + filter
+	}
+	args = append(args, tenant.ID(ctx))
+	where += " AND tenant_id = " + migrate.Question(len(args))
+
+	var page storage.StudentPage
+
+	// Count every matching student, the total is independent of the page
+	err := s.Db.QueryRowContext(ctx, "SELECT COUNT(*) FROM students"+where, args...).Scan(&page.Total)
 	if err != nil {
-		return nil, err
+		return storage.StudentPage{}, err
 	}
-	defer stmt.Close()
 
-	// Execute the prepared SQL statement
-	rows, err := stmt.Query()
+	// Continue after the last student of the previous page when a cursor is given
+	if opts.Cursor != "" {
+		last, err := storage.DecodeCursor(opts.Cursor, opts.Sort)
+		if err != nil {
+			return storage.StudentPage{}, err
+		}
+
+		after, afterArgs := storage.SQLAfter(last, opts.Sort, migrate.Question, len(args))
+		where += " AND " + after
+		args = append(args, afterArgs...)
+	}
+
+	// Fetch one extra row to find out whether there is a next page
+	query := fmt.Sprintf("SELECT id,name,email,age,version FROM students%s ORDER BY %s LIMIT %d OFFSET %d",
+		where, storage.SQLOrderBy(opts.Sort), opts.Limit+1, opts.Offset)
+
+	rows, err := s.Db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return storage.StudentPage{}, err
 	}
 
 	defer rows.Close()
 
-	// Initialize a slice to store the student data
-	var students []types.Student
-
 	// Iterate over the rows and scan the student data
 	for rows.Next() {
 		var student types.Student
 
-		err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age)
+		err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
 		if err != nil {
+			return storage.StudentPage{}, err
+		}
+
+		page.Students = append(page.Students, student)
+	}
+
+	if err := rows.Err(); err != nil {
+		return storage.StudentPage{}, err
+	}
+
+	// Drop the extra row and point the cursor at the last student of this page
+	if len(page.Students) > opts.Limit {
+		page.Students = page.Students[:opts.Limit]
+		page.NextCursor = storage.EncodeCursor(page.Students[opts.Limit-1], opts.Sort)
+	}
+
+	return page, nil
+}
+
+// SearchStudents function searches students by name and email using the students_fts index
+// It takes the search text and the maximum number of results and returns the best matches first
+// Every word of the query must match the start of a word in the name or email, names weigh more than emails
+func (s *Sqlite) SearchStudents(ctx context.Context, query string, limit int) ([]types.Student, error) {
+	terms := storage.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	// Turn every term into a quoted prefix query, e.g. "jo"* "smi"*
+	for i, term := range terms {
+		terms[i] = `"` + term + `"*`
+	}
+
+	rows, err := s.Db.QueryContext(ctx, `SELECT s.id, s.name, s.email, s.age, s.version
+		FROM students_fts
+		JOIN students s ON s.id = students_fts.rowid
+		WHERE students_fts MATCH ? AND s.tenant_id = ? AND s.deleted_at IS NULL
+		ORDER BY bm25(students_fts, 10.0, 1.0), s.id
+		LIMIT ?`, strings.Join(terms, " "), tenant.ID(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	// Iterate over the rows and scan the student data
+	var students []types.Student
+	for rows.Next() {
+		var student types.Student
+
+		if err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version); err != nil {
 			return nil, err
 		}
 
-		// Append the student data to the slice
 		students = append(students, student)
 	}
 
-	return students, nil
+	return students, rows.Err()
 }
 
 // UpdateStudent function updates a student in the database
-// It takes the student's ID, name, email, and age as arguments and returns the updated student data and an error
-// This function is used to update a student in the 'students' table
-func (s *Sqlite) UpdateStudent(id int64, name string, email string, age int) (types.Student, error) {
-	// Prepare a SQL statement to update a student in the 'students' table
+// It takes the student's ID, name, email, age and expected version and returns the updated student data and an error
+// A version of 0 updates unconditionally, otherwise the row is only changed while its version still matches
+// Every update increments the student's version and is recorded in their history
+func (s *Sqlite) UpdateStudent(ctx context.Context, id int64, name string, email string, age int, version int64) (types.Student, error) {
 	slog.Info("Updating a student")
-	stmt, err := s.Db.Prepare("UPDATE students SET name=?, email=?,age=? WHERE id=?")
+
+	tx, err := s.Db.BeginTx(ctx, nil)
 	if err != nil {
 		return types.Student{}, err
 	}
 
-	defer stmt.Close()
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
 
-	// Initialize a student struct to store the updated data
+	// Read the student as they were, for their history
+	before, err := studentInTx(ctx, tx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return types.Student{}, err
+	}
+
+	// Update the row and read it back in one statement, so the new version is returned too
 	var student types.Student
+	err = tx.QueryRowContext(ctx, `UPDATE students SET name=?, email=?, age=?, version=version+1
+		WHERE id=? AND tenant_id=? AND deleted_at IS NULL AND (?=0 OR version=?)
+		RETURNING id,name,email,age,version`, name, email, age, id, tenant.ID(ctx), version, version).
+		Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
 
-	// Execute the prepared SQL statement with the provided student data
-	result, err := stmt.Exec(name, email, age, id)
 	if err != nil {
+		// No row matched, find out whether the student is missing or was modified meanwhile
+		if err == sql.ErrNoRows {
+			return types.Student{}, staleOrMissing(ctx, tx, id)
+		}
+		// The new email belongs to another student
+		if isUniqueViolation(err) {
+			return types.Student{}, storage.EmailTaken(email)
+		}
 		return types.Student{}, err
 	}
 
-	// Get the number of rows affected by the update
-	rowsAffected, err := result.RowsAffected()
+	if err := recordChange(ctx, tx, types.AuditUpdate, &before, &student); err != nil {
+		return types.Student{}, err
+	}
+
+	return student, tx.Commit()
+}
+
+// staleOrMissing explains why a conditional write matched no row
+// It returns storage.ErrVersionMismatch if the student exists and a storage.ErrNotFound error if it does not
+func staleOrMissing(ctx context.Context, tx *sql.Tx, id int64) error {
+	var exists bool
+
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM students WHERE id=? AND tenant_id=? AND deleted_at IS NULL)", id, tenant.ID(ctx)).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return storage.ErrVersionMismatch
+	}
+	return storage.StudentNotFound(id)
+}
+
+// PatchStudent function updates some fields of a student in the database
+// It takes the student's ID and the fields to change and returns the whole updated student and an error
+// Fields that are nil in the patch keep their current value, the version works as in UpdateStudent
+func (s *Sqlite) PatchStudent(ctx context.Context, id int64, patch types.StudentPatch, version int64) (types.Student, error) {
+	slog.Info("Patching a student")
+
+	// Collect the assignments of the fields present in the patch
+	var sets []string
+	var args []any
+	for _, field := range []struct {
+		column string
+		value  any
+		ok     bool
+	}{
+		{"name", patch.Name, patch.Name != nil},
+		{"email", patch.Email, patch.Email != nil},
+		{"age", patch.Age, patch.Age != nil},
+	} {
+		if field.ok {
+			args = append(args, field.value)
+			sets = append(sets, fmt.Sprintf("%s=%s", field.column, migrate.Question(len(args))))
+		}
+	}
+
+	// An empty patch changes nothing, but the version must still match
+	if len(sets) == 0 {
+		student, err := s.GetStudentById(ctx, id)
+		if err == nil && version != 0 && student.Version != version {
+			return types.Student{}, storage.ErrVersionMismatch
+		}
+		return student, err
+	}
+
+	tx, err := s.Db.BeginTx(ctx, nil)
 	if err != nil {
 		return types.Student{}, err
 	}
-	// If no rows were affected, return an error
-	if rowsAffected == 0 {
-		return types.Student{}, fmt.Errorf("no rows affected")
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Read the student as they were, for their history
+	before, err := studentInTx(ctx, tx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return types.Student{}, err
 	}
 
-	// Update the student struct with the provided data
-	student.Age = age
-	student.Email = email
-	student.Name = name
-	student.Id = id
+	args = append(args, id, tenant.ID(ctx), version, version)
+	query := fmt.Sprintf("UPDATE students SET %s, version=version+1 WHERE id=%s AND tenant_id=%s AND deleted_at IS NULL AND (%s=0 OR version=%s) RETURNING id,name,email,age,version",
+		strings.Join(sets, ", "), migrate.Question(len(args)-3), migrate.Question(len(args)-2), migrate.Question(len(args)-1), migrate.Question(len(args)))
 
-	return student, nil
+	// Apply the update and read the resulting row back in one statement
+	var student types.Student
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
+	if err != nil {
+		// No row matched, find out whether the student is missing or was modified meanwhile
+		if err == sql.ErrNoRows {
+			return types.Student{}, staleOrMissing(ctx, tx, id)
+		}
+		// Only a changed email can collide with another student
+		if isUniqueViolation(err) && patch.Email != nil {
+			return types.Student{}, storage.EmailTaken(*patch.Email)
+		}
+		return types.Student{}, err
+	}
+
+	if err := recordChange(ctx, tx, types.AuditUpdate, &before, &student); err != nil {
+		return types.Student{}, err
+	}
+
+	return student, tx.Commit()
 }
 
-// DeleteStudentById function deletes a student from the database by their ID
-// It takes the student's ID as an argument and returns an error
-// This function is used to delete a student from the 'students' table by their ID
-func (s *Sqlite) DeleteStudentById(id int64) error {
-	// Prepare a SQL statement to delete a student from the 'students' table by their ID
+// DeleteStudentById function moves a student to the trash by their ID
+// It takes the student's ID and expected version as arguments and returns an error
+// A version of 0 deletes unconditionally, otherwise a student whose version changed is kept and storage.ErrVersionMismatch is returned
+// Deleting a missing or already trashed student fails with storage.ErrNotFound
+// The row stays in the 'students' table with its deletion time, until it is restored or purged
+// Its attachments stay as well, the files are only removed by PurgeDeletedStudents
+func (s *Sqlite) DeleteStudentById(ctx context.Context, id int64, version int64) error {
 	slog.Info("Deleting a student")
-	stmt, err := s.Db.Prepare("DELETE FROM students WHERE id=?")
+
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Read the student as they were, for their history
+	before, err := studentInTx(ctx, tx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	// Prepare a SQL statement to mark a student of the 'students' table as deleted by their ID
+	// The version is incremented, so ETags taken before the deletion do not match the restored student
+	stmt, err := tx.PrepareContext(ctx, `UPDATE students SET deleted_at=?, version=version+1
+		WHERE id=? AND tenant_id=? AND deleted_at IS NULL AND (?=0 OR version=?)`)
 	if err != nil {
 		return err
 	}
 
+	defer stmt.Close()
+
 	// Execute the prepared SQL statement with the provided student ID
-	_, err = stmt.Exec(id)
+	deletedAt := time.Now().UTC()
+	result, err := stmt.ExecContext(ctx, deletedAt, id, tenant.ID(ctx), version, version)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// No row matched, find out whether the student is missing or was modified meanwhile
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return staleOrMissing(ctx, tx, id)
+	}
+
+	if err := recordChange(ctx, tx, types.AuditDelete, &before, trashedCopy(before, deletedAt)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// DeleteAllStudents function deletes all students from the database
-// It returns an error
-// This function is used to delete all students from the 'students' table
-func (s *Sqlite) DeleteAllStudents() error {
-	// Prepare a SQL statement to delete all students from the 'students' table
-	stmt, err := s.Db.Prepare("DELETE FROM students")
+// trashedCopy returns the student as they are once moved to the trash at the given time
+func trashedCopy(student types.Student, deletedAt time.Time) *types.Student {
+	student.DeletedAt = &deletedAt
+	student.Version++
+	return &student
+}
 
+// DeleteAllStudents function moves all students of the tenant to the trash
+// It returns an error
+// This function is used to mark every student of the tenant as deleted, each deletion is recorded in the student's history
+func (s *Sqlite) DeleteAllStudents(ctx context.Context) error {
+	tx, err := s.Db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	// Execute the prepared SQL statement
-	_, err = stmt.Exec()
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
 
+	// Mark all students of the tenant as deleted and read back the ones that were affected
+	deletedAt := time.Now().UTC()
+	rows, err := tx.QueryContext(ctx, `UPDATE students SET deleted_at=?, version=version+1
+		WHERE tenant_id=? AND deleted_at IS NULL
+		RETURNING id,name,email,age,version`, deletedAt, tenant.ID(ctx))
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
\ No newline at end of file
+	// Collect the students first, the transaction cannot run another statement while the rows are open
+	var students []types.Student
+	for rows.Next() {
+		var student types.Student
+
+		if err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version); err != nil {
+			rows.Close()
+			return err
+		}
+
+		students = append(students, student)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, student := range students {
+		// The student as they were before this deletion
+		before := student
+		before.Version--
+
+		if err := recordChange(ctx, tx, types.AuditDelete, &before, trashedCopy(before, deletedAt)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
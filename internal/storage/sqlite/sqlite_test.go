@@ -0,0 +1,31 @@
+//go:build sqlite_fts5
+
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/sqlite"
+	"github.com/Priyang1310/Students-API-GO/internal/storage/storagetest"
+)
+
+// newDB opens a new database at path, bringing its schema up to date
+func newDB(t *testing.T, path string) *sqlite.Sqlite {
+	t.Helper()
+
+	db, err := sqlite.New(&config.Config{StoragePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Db.Close() })
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return newDB(t, filepath.Join(t.TempDir(), "students.db"))
+	})
+}
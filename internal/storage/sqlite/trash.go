@@ -0,0 +1,151 @@
+//go:build sqlite_fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// GetDeletedStudents function retrieves the students of the tenant in the trash, most recently deleted first
+func (s *Sqlite) GetDeletedStudents(ctx context.Context) ([]types.Student, error) {
+	rows, err := s.Db.QueryContext(ctx, `SELECT id, name, email, age, version, deleted_at
+		FROM students
+		WHERE tenant_id = ? AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id`, tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	// Iterate over the rows and scan the student data
+	students := []types.Student{}
+	for rows.Next() {
+		var student types.Student
+
+		if err := rows.Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version, &student.DeletedAt); err != nil {
+			return nil, err
+		}
+
+		students = append(students, student)
+	}
+
+	return students, rows.Err()
+}
+
+// RestoreStudent function takes a student out of the trash and returns it
+// Their enrollments, grades and other records were kept, so they come back as well
+// It fails with storage.ErrNotFound if no trashed student of the tenant has the ID, with storage.ErrConflict
+// if their email has been given to another student in the meantime and with storage.ErrCourseFull if their seat
+// in one of their courses was taken, the student then stays in the trash
+// The restoration is recorded in the student's history
+func (s *Sqlite) RestoreStudent(ctx context.Context, id int64) (types.Student, error) {
+	slog.Info("Restoring a student", slog.Int64("id", id))
+
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return types.Student{}, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	// Read the student as they were, for their history
+	before, err := studentInTx(ctx, tx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return types.Student{}, err
+	}
+
+	var student types.Student
+	err = tx.QueryRowContext(ctx, `UPDATE students SET deleted_at=NULL, version=version+1
+		WHERE id=? AND tenant_id=? AND deleted_at IS NOT NULL
+		RETURNING id,name,email,age,version`, id, tenant.ID(ctx)).
+		Scan(&student.Id, &student.Name, &student.Email, &student.Age, &student.Version)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.Student{}, storage.DeletedStudentNotFound(id)
+		}
+		// The email was reused while the student was in the trash
+		if isUniqueViolation(err) {
+			return types.Student{}, storage.EmailTaken(before.Email)
+		}
+		return types.Student{}, err
+	}
+
+	// The seats of a trashed student are free again, the restored enrollments must still fit in their courses
+	if err := checkCapacity(ctx, tx, id); err != nil {
+		return types.Student{}, err
+	}
+
+	if err := recordChange(ctx, tx, types.AuditRestore, &before, &student); err != nil {
+		return types.Student{}, err
+	}
+
+	return student, tx.Commit()
+}
+
+// checkCapacity fails with storage.ErrCourseFull if a course the student is enrolled in has more enrollments than seats
+// It runs in the transaction restoring the student, after the student is counted again
+func checkCapacity(ctx context.Context, tx *sql.Tx, studentId int64) error {
+	var courseId int64
+	var capacity int
+	err := tx.QueryRowContext(ctx, `SELECT c.id, c.capacity FROM enrollments e
+		JOIN courses c ON c.id = e.course_id
+		WHERE e.student_id = ? AND c.capacity < `+enrolledCount+`
+		ORDER BY c.id LIMIT 1`, studentId).Scan(&courseId, &capacity)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return nil
+	case err != nil:
+		return err
+	default:
+		return storage.CourseFull(courseId, capacity)
+	}
+}
+
+// PurgeDeletedStudents function deletes the students trashed before the given time for good
+// Their enrollments, grades, attendance, guardian links and attachments follow through the foreign keys,
+// and the attachment files are removed once the deletion is committed
+// The retention is the same for every school, so the trash of every tenant is purged
+// It returns the number of students purged
+func (s *Sqlite) PurgeDeletedStudents(ctx context.Context, before time.Time) (int64, error) {
+	// Delete in a transaction, so the attachment files removed afterwards are exactly the ones of the deleted rows
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	keys, err := attachmentKeys(ctx, tx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM students WHERE deleted_at IS NOT NULL AND deleted_at < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	s.removeBlobs(keys)
+	return purged, nil
+}
@@ -1,12 +1,55 @@
 package storage
 
-import "github.com/Priyang1310/Students-API-GO/internal/types"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// ErrNotFound is wrapped by the errors every backend returns for a missing record
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is wrapped by the errors every backend returns when a write would violate a uniqueness rule
+var ErrConflict = errors.New("already exists")
+
+// ErrVersionMismatch is returned by conditional writes when the student's version no longer matches the expected one
+var ErrVersionMismatch = errors.New("student was modified concurrently")
+
+// Storage is implemented by every student storage backend.
+// Each method takes the request context so that a client disconnect or a
+// timeout cancels the underlying query.
+// The version arguments implement optimistic concurrency: a non-zero version
+// makes the write fail with ErrVersionMismatch unless it matches the stored one.
+// Deleting a student only moves it to the trash: every other method ignores
+// trashed students until they are restored or purged for good.
 type Storage interface {
-	CreateStudent(name string, email string, age int) (int64, error)
-	GetStudentById(id int64) (types.Student, error)
-	GetAllStudents() ([]types.Student, error)
-	UpdateStudent(id int64, name string, email string, age int) (types.Student, error)
-	DeleteStudentById(id int64) error
-	DeleteAllStudents() error
+	CreateStudent(ctx context.Context, name string, email string, age int) (int64, error)
+	GetStudentById(ctx context.Context, id int64) (types.Student, error)
+	GetAllStudents(ctx context.Context, opts ListOptions) (StudentPage, error)
+	SearchStudents(ctx context.Context, query string, limit int) ([]types.Student, error)
+	UpdateStudent(ctx context.Context, id int64, name string, email string, age int, version int64) (types.Student, error)
+	PatchStudent(ctx context.Context, id int64, patch types.StudentPatch, version int64) (types.Student, error)
+	DeleteStudentById(ctx context.Context, id int64, version int64) error
+	DeleteAllStudents(ctx context.Context) error
+	GetDeletedStudents(ctx context.Context) ([]types.Student, error)
+	RestoreStudent(ctx context.Context, id int64) (types.Student, error)
+	PurgeDeletedStudents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// StudentNotFound returns the error reported for an unknown student ID, it wraps ErrNotFound
+func StudentNotFound(id int64) error {
+	return fmt.Errorf("student %w with id %d", ErrNotFound, id)
+}
+
+// DeletedStudentNotFound returns the error reported when no trashed student has the ID, it wraps ErrNotFound
+func DeletedStudentNotFound(id int64) error {
+	return fmt.Errorf("deleted student %w with id %d", ErrNotFound, id)
+}
+
+// EmailTaken returns the error reported when an email is already used by another student, it wraps ErrConflict
+func EmailTaken(email string) error {
+	return fmt.Errorf("student with email %s %w", email, ErrConflict)
 }
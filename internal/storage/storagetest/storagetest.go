@@ -0,0 +1,546 @@
+// Package storagetest holds the conformance tests every storage.Storage backend must pass,
+// so the memory, SQLite and PostgreSQL backends keep behaving the same way
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Priyang1310/Students-API-GO/internal/audit"
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+)
+
+// Run runs the conformance tests against the backend returned by open
+// open is called once per test and must return a backend holding no students
+func Run(t *testing.T, open func(t *testing.T) storage.Storage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, db storage.Storage, ctx context.Context)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"UniqueEmail", testUniqueEmail},
+		{"Update", testUpdate},
+		{"ConcurrentWrites", testConcurrentWrites},
+		{"Patch", testPatch},
+		{"List", testList},
+		{"ListFilters", testListFilters},
+		{"Search", testSearch},
+		{"Trash", testTrash},
+		{"DeleteAll", testDeleteAll},
+		{"Purge", testPurge},
+		{"Tenants", testTenants},
+		{"History", testHistory},
+		{"Cancelled", testCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, open(t), context.Background())
+		})
+	}
+}
+
+// create stores a student and fails the test if it cannot
+func create(t *testing.T, db storage.Storage, ctx context.Context, name string, email string, age int) int64 {
+	t.Helper()
+
+	id, err := db.CreateStudent(ctx, name, email, age)
+	if err != nil {
+		t.Fatalf("CreateStudent(%q): %v", email, err)
+	}
+	return id
+}
+
+func wantErr(t *testing.T, call string, err error, target error) {
+	t.Helper()
+
+	if !errors.Is(err, target) {
+		t.Fatalf("%s: got error %v, want %v", call, err, target)
+	}
+}
+
+// ids returns the IDs of the students, in their order
+func ids(students []types.Student) []int64 {
+	list := make([]int64, len(students))
+	for i, student := range students {
+		list[i] = student.Id
+	}
+	return list
+}
+
+// sameIDs reports whether two ID lists hold the same IDs in the same order
+func sameIDs(a []int64, b []int64) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func testCreateAndGet(t *testing.T, db storage.Storage, ctx context.Context) {
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+	if id <= 0 {
+		t.Fatalf("CreateStudent returned id %d, want a positive id", id)
+	}
+
+	student, err := db.GetStudentById(ctx, id)
+	if err != nil {
+		t.Fatalf("GetStudentById: %v", err)
+	}
+	want := types.Student{Id: id, Name: "Ann Lee", Email: "ann@example.edu", Age: 15, Version: 1}
+	if student != want {
+		t.Fatalf("GetStudentById: got %+v, want %+v", student, want)
+	}
+
+	other := create(t, db, ctx, "Bob Ray", "bob@example.edu", 16)
+	if other <= id {
+		t.Fatalf("CreateStudent returned id %d after %d, want increasing ids", other, id)
+	}
+
+	_, err = db.GetStudentById(ctx, other+1000)
+	wantErr(t, "GetStudentById of an unknown id", err, storage.ErrNotFound)
+}
+
+func testUniqueEmail(t *testing.T, db storage.Storage, ctx context.Context) {
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+
+	_, err := db.CreateStudent(ctx, "Ann Other", "ANN@example.edu", 16)
+	wantErr(t, "CreateStudent with a taken email", err, storage.ErrConflict)
+
+	other := create(t, db, ctx, "Bob Ray", "bob@example.edu", 16)
+	_, err = db.UpdateStudent(ctx, other, "Bob Ray", "ann@example.edu", 16, 0)
+	wantErr(t, "UpdateStudent to a taken email", err, storage.ErrConflict)
+
+	email := "ann@example.edu"
+	_, err = db.PatchStudent(ctx, other, types.StudentPatch{Email: &email}, 0)
+	wantErr(t, "PatchStudent to a taken email", err, storage.ErrConflict)
+
+	// A trashed student gives their email back
+	if err := db.DeleteStudentById(ctx, id, 0); err != nil {
+		t.Fatalf("DeleteStudentById: %v", err)
+	}
+	create(t, db, ctx, "Ann Again", "ann@example.edu", 17)
+}
+
+func testUpdate(t *testing.T, db storage.Storage, ctx context.Context) {
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+
+	updated, err := db.UpdateStudent(ctx, id, "Ann Smith", "ann.smith@example.edu", 16, 1)
+	if err != nil {
+		t.Fatalf("UpdateStudent: %v", err)
+	}
+	want := types.Student{Id: id, Name: "Ann Smith", Email: "ann.smith@example.edu", Age: 16, Version: 2}
+	if updated != want {
+		t.Fatalf("UpdateStudent: got %+v, want %+v", updated, want)
+	}
+	if stored, _ := db.GetStudentById(ctx, id); stored != want {
+		t.Fatalf("GetStudentById after UpdateStudent: got %+v, want %+v", stored, want)
+	}
+
+	_, err = db.UpdateStudent(ctx, id, "Ann Stale", "ann@example.edu", 17, 1)
+	wantErr(t, "UpdateStudent with a stale version", err, storage.ErrVersionMismatch)
+
+	// A version of 0 updates unconditionally
+	if updated, err = db.UpdateStudent(ctx, id, "Ann Lee", "ann@example.edu", 17, 0); err != nil || updated.Version != 3 {
+		t.Fatalf("UpdateStudent without a version: got version %d and error %v, want version 3", updated.Version, err)
+	}
+
+	_, err = db.UpdateStudent(ctx, id+1000, "Nobody", "nobody@example.edu", 20, 0)
+	wantErr(t, "UpdateStudent of an unknown id", err, storage.ErrNotFound)
+}
+
+func testConcurrentWrites(t *testing.T, db storage.Storage, ctx context.Context) {
+	const writers = 20
+
+	students := make([]int64, writers)
+	for i := range students {
+		students[i] = create(t, db, ctx, fmt.Sprintf("Student %d", i), fmt.Sprintf("student%d@example.edu", i), 15)
+	}
+
+	// Every writer changes a different student, none of them may fail for the others
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i, id := range students {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = db.UpdateStudent(ctx, id, fmt.Sprintf("Updated %d", i), fmt.Sprintf("updated%d@example.edu", i), 16, 1)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("concurrent UpdateStudent of student %d: %v", students[i], err)
+		}
+	}
+	for i, id := range students {
+		if stored, err := db.GetStudentById(ctx, id); err != nil || stored.Name != fmt.Sprintf("Updated %d", i) || stored.Version != 2 {
+			t.Errorf("GetStudentById after concurrent updates: got %+v and error %v, want version 2 named Updated %d", stored, err, i)
+		}
+	}
+}
+
+func testPatch(t *testing.T, db storage.Storage, ctx context.Context) {
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+
+	age := 16
+	patched, err := db.PatchStudent(ctx, id, types.StudentPatch{Age: &age}, 1)
+	if err != nil {
+		t.Fatalf("PatchStudent: %v", err)
+	}
+	want := types.Student{Id: id, Name: "Ann Lee", Email: "ann@example.edu", Age: 16, Version: 2}
+	if patched != want {
+		t.Fatalf("PatchStudent: got %+v, want %+v", patched, want)
+	}
+
+	// An empty patch changes nothing, not even the version
+	if patched, err = db.PatchStudent(ctx, id, types.StudentPatch{}, 0); err != nil || patched != want {
+		t.Fatalf("PatchStudent with an empty patch: got %+v and error %v, want %+v", patched, err, want)
+	}
+
+	name := "Ann Stale"
+	_, err = db.PatchStudent(ctx, id, types.StudentPatch{Name: &name}, 1)
+	wantErr(t, "PatchStudent with a stale version", err, storage.ErrVersionMismatch)
+
+	_, err = db.PatchStudent(ctx, id+1000, types.StudentPatch{Name: &name}, 0)
+	wantErr(t, "PatchStudent of an unknown id", err, storage.ErrNotFound)
+}
+
+func testList(t *testing.T, db storage.Storage, ctx context.Context) {
+	// Two students share a name, so the id must break the tie
+	var created []int64
+	for i, name := range []string{"Eve Moss", "Cal Dunn", "Ann Lee", "Cal Dunn", "Bea Hart"} {
+		created = append(created, create(t, db, ctx, name, fmt.Sprintf("s%d@example.edu", i), 10+i))
+	}
+	byName := []int64{created[2], created[4], created[1], created[3], created[0]}
+
+	// Walk the pages with the cursor
+	opts := storage.ListOptions{Limit: 2, Sort: []storage.SortField{{Field: "name"}}}
+	var listed []int64
+	for pages := 0; ; pages++ {
+		if pages > len(created) {
+			t.Fatalf("GetAllStudents: the cursor never reached the last page")
+		}
+
+		page, err := db.GetAllStudents(ctx, opts)
+		if err != nil {
+			t.Fatalf("GetAllStudents: %v", err)
+		}
+		if page.Total != int64(len(created)) {
+			t.Fatalf("GetAllStudents: got total %d, want %d", page.Total, len(created))
+		}
+		if len(page.Students) > 2 {
+			t.Fatalf("GetAllStudents: got %d students, want at most the limit of 2", len(page.Students))
+		}
+
+		listed = append(listed, ids(page.Students)...)
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if !sameIDs(listed, byName) {
+		t.Fatalf("GetAllStudents by name: got %v, want %v", listed, byName)
+	}
+
+	// Descending ages with an offset
+	page, err := db.GetAllStudents(ctx, storage.ListOptions{Offset: 3, Sort: []storage.SortField{{Field: "age", Desc: true}}})
+	if err != nil {
+		t.Fatalf("GetAllStudents with an offset: %v", err)
+	}
+	if want := []int64{created[1], created[0]}; !sameIDs(ids(page.Students), want) || page.NextCursor != "" {
+		t.Fatalf("GetAllStudents by -age from offset 3: got %v and cursor %q, want %v and no cursor", ids(page.Students), page.NextCursor, want)
+	}
+
+	// A cursor only continues the sort order it was issued for
+	_, err = db.GetAllStudents(ctx, storage.ListOptions{Cursor: storage.EncodeCursor(types.Student{Name: "Cal Dunn"}, []storage.SortField{{Field: "name"}, {Field: "id"}})})
+	wantErr(t, "GetAllStudents with a cursor of another sort order", err, storage.ErrInvalidCursor)
+}
+
+func testListFilters(t *testing.T, db storage.Storage, ctx context.Context) {
+	ann := create(t, db, ctx, "Ann Lee", "ann@north.edu", 12)
+	bob := create(t, db, ctx, "Bob Leeds", "bob@south.edu", 15)
+	cal := create(t, db, ctx, "Cal Dunn", "cal@NORTH.edu", 18)
+
+	minAge, maxAge := 13, 18
+	tests := []struct {
+		name string
+		opts storage.ListOptions
+		want []int64
+	}{
+		{"age range", storage.ListOptions{AgeMin: &minAge, AgeMax: &maxAge}, []int64{bob, cal}},
+		{"email domain", storage.ListOptions{EmailDomain: "North.edu"}, []int64{ann, cal}},
+		{"name contains", storage.ListOptions{NameContains: "LEE"}, []int64{ann, bob}},
+		{"every filter", storage.ListOptions{AgeMin: &minAge, EmailDomain: "north.edu", NameContains: "dun"}, []int64{cal}},
+	}
+
+	for _, tt := range tests {
+		page, err := db.GetAllStudents(ctx, tt.opts)
+		if err != nil {
+			t.Fatalf("GetAllStudents by %s: %v", tt.name, err)
+		}
+		if !sameIDs(ids(page.Students), tt.want) || page.Total != int64(len(tt.want)) {
+			t.Fatalf("GetAllStudents by %s: got %v of %d, want %v", tt.name, ids(page.Students), page.Total, tt.want)
+		}
+	}
+}
+
+func testSearch(t *testing.T, db storage.Storage, ctx context.Context) {
+	john := create(t, db, ctx, "John Smith", "jsmith@example.edu", 15)
+	joanna := create(t, db, ctx, "Joanna Black", "jb@example.edu", 14)
+	mary := create(t, db, ctx, "Mary-Jane Watson", "mj.watson@example.org", 16)
+
+	// The ranking differs between backends, so only the matches are compared
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"jo", []int64{john, joanna}},
+		{"SMI", []int64{john}},
+		{"jo bl", []int64{joanna}},
+		{"jane", []int64{mary}},
+		{"watson", []int64{mary}},
+		{"example org", []int64{mary}},
+		{"mith", nil},
+		{"zzz", nil},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		found, err := db.SearchStudents(ctx, tt.query, 10)
+		if err != nil {
+			t.Fatalf("SearchStudents(%q): %v", tt.query, err)
+		}
+
+		got := ids(found)
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		if len(got) != len(tt.want) || (len(got) > 0 && !sameIDs(got, tt.want)) {
+			t.Fatalf("SearchStudents(%q): got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if found, err := db.SearchStudents(ctx, "jo", 1); err != nil || len(found) != 1 {
+		t.Fatalf("SearchStudents with a limit of 1: got %d students and error %v, want 1", len(found), err)
+	}
+
+	// Trashed students are not found
+	if err := db.DeleteStudentById(ctx, john, 0); err != nil {
+		t.Fatalf("DeleteStudentById: %v", err)
+	}
+	if found, err := db.SearchStudents(ctx, "smith", 10); err != nil || len(found) != 0 {
+		t.Fatalf("SearchStudents of a trashed student: got %v and error %v, want none", ids(found), err)
+	}
+}
+
+func testTrash(t *testing.T, db storage.Storage, ctx context.Context) {
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+	keep := create(t, db, ctx, "Bob Ray", "bob@example.edu", 16)
+
+	wantErr(t, "DeleteStudentById with a stale version", db.DeleteStudentById(ctx, id, 7), storage.ErrVersionMismatch)
+	if err := db.DeleteStudentById(ctx, id, 1); err != nil {
+		t.Fatalf("DeleteStudentById: %v", err)
+	}
+
+	// The trashed student is out of reach of every other method
+	_, err := db.GetStudentById(ctx, id)
+	wantErr(t, "GetStudentById of a trashed student", err, storage.ErrNotFound)
+	_, err = db.UpdateStudent(ctx, id, "Ann Lee", "ann@example.edu", 15, 0)
+	wantErr(t, "UpdateStudent of a trashed student", err, storage.ErrNotFound)
+	wantErr(t, "DeleteStudentById of a trashed student", db.DeleteStudentById(ctx, id, 0), storage.ErrNotFound)
+	if page, err := db.GetAllStudents(ctx, storage.ListOptions{}); err != nil || !sameIDs(ids(page.Students), []int64{keep}) {
+		t.Fatalf("GetAllStudents with a trashed student: got %v and error %v, want [%d]", ids(page.Students), err, keep)
+	}
+
+	trash, err := db.GetDeletedStudents(ctx)
+	if err != nil {
+		t.Fatalf("GetDeletedStudents: %v", err)
+	}
+	if len(trash) != 1 || trash[0].Id != id || trash[0].DeletedAt == nil {
+		t.Fatalf("GetDeletedStudents: got %+v, want student %d with its deletion time", trash, id)
+	}
+
+	_, err = db.RestoreStudent(ctx, keep)
+	wantErr(t, "RestoreStudent of a student not in the trash", err, storage.ErrNotFound)
+
+	// The version moves on with the deletion and the restore, so earlier ETags no longer match
+	restored, err := db.RestoreStudent(ctx, id)
+	if err != nil {
+		t.Fatalf("RestoreStudent: %v", err)
+	}
+	if restored.Id != id || restored.DeletedAt != nil || restored.Version <= 1 {
+		t.Fatalf("RestoreStudent: got %+v, want student %d out of the trash with a version above 1", restored, id)
+	}
+	if _, err := db.GetStudentById(ctx, id); err != nil {
+		t.Fatalf("GetStudentById of a restored student: %v", err)
+	}
+
+	// A student whose email was given to someone else cannot come back
+	if err := db.DeleteStudentById(ctx, id, 0); err != nil {
+		t.Fatalf("DeleteStudentById: %v", err)
+	}
+	create(t, db, ctx, "Ann Other", "ann@example.edu", 17)
+	_, err = db.RestoreStudent(ctx, id)
+	wantErr(t, "RestoreStudent with a taken email", err, storage.ErrConflict)
+}
+
+func testDeleteAll(t *testing.T, db storage.Storage, ctx context.Context) {
+	create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+	create(t, db, ctx, "Bob Ray", "bob@example.edu", 16)
+
+	other := tenant.WithID(ctx, "other-school")
+	kept := create(t, db, other, "Cal Dunn", "cal@example.edu", 17)
+
+	if err := db.DeleteAllStudents(ctx); err != nil {
+		t.Fatalf("DeleteAllStudents: %v", err)
+	}
+
+	if page, err := db.GetAllStudents(ctx, storage.ListOptions{}); err != nil || page.Total != 0 {
+		t.Fatalf("GetAllStudents after DeleteAllStudents: got %v and error %v, want none", ids(page.Students), err)
+	}
+	if trash, err := db.GetDeletedStudents(ctx); err != nil || len(trash) != 2 {
+		t.Fatalf("GetDeletedStudents after DeleteAllStudents: got %v and error %v, want 2 students", ids(trash), err)
+	}
+
+	// Only the students of the tenant are deleted
+	if _, err := db.GetStudentById(other, kept); err != nil {
+		t.Fatalf("GetStudentById of another tenant after DeleteAllStudents: %v", err)
+	}
+}
+
+func testPurge(t *testing.T, db storage.Storage, ctx context.Context) {
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+	kept := create(t, db, ctx, "Bob Ray", "bob@example.edu", 16)
+	if err := db.DeleteStudentById(ctx, id, 0); err != nil {
+		t.Fatalf("DeleteStudentById: %v", err)
+	}
+
+	// Nothing was trashed an hour ago
+	if _, err := db.PurgeDeletedStudents(ctx, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("PurgeDeletedStudents: %v", err)
+	}
+	if trash, err := db.GetDeletedStudents(ctx); err != nil || len(trash) != 1 {
+		t.Fatalf("GetDeletedStudents after purging older students: got %v and error %v, want [%d]", ids(trash), err, id)
+	}
+
+	purged, err := db.PurgeDeletedStudents(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("PurgeDeletedStudents: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("PurgeDeletedStudents: purged %d students, want 1", purged)
+	}
+
+	if trash, err := db.GetDeletedStudents(ctx); err != nil || len(trash) != 0 {
+		t.Fatalf("GetDeletedStudents after the purge: got %v and error %v, want none", ids(trash), err)
+	}
+	_, err = db.RestoreStudent(ctx, id)
+	wantErr(t, "RestoreStudent of a purged student", err, storage.ErrNotFound)
+	if _, err := db.GetStudentById(ctx, kept); err != nil {
+		t.Fatalf("GetStudentById of a student not in the trash after the purge: %v", err)
+	}
+
+	// IDs of purged students are never handed out again
+	if next := create(t, db, ctx, "Cal Dunn", "cal@example.edu", 17); next <= kept {
+		t.Fatalf("CreateStudent after the purge returned id %d, want an id above %d", next, kept)
+	}
+}
+
+func testCancelled(t *testing.T, db storage.Storage, ctx context.Context) {
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := db.GetStudentById(cancelled, id); err == nil {
+		t.Fatalf("GetStudentById with a cancelled context: got no error")
+	}
+	if _, err := db.CreateStudent(cancelled, "Bob Ray", "bob@example.edu", 16); err == nil {
+		t.Fatalf("CreateStudent with a cancelled context: got no error")
+	}
+}
+
+func testTenants(t *testing.T, db storage.Storage, ctx context.Context) {
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+	other := tenant.WithID(ctx, "other-school")
+
+	_, err := db.GetStudentById(other, id)
+	wantErr(t, "GetStudentById of another tenant", err, storage.ErrNotFound)
+	_, err = db.UpdateStudent(other, id, "Ann Lee", "ann@example.edu", 16, 0)
+	wantErr(t, "UpdateStudent of another tenant", err, storage.ErrNotFound)
+	wantErr(t, "DeleteStudentById of another tenant", db.DeleteStudentById(other, id, 0), storage.ErrNotFound)
+
+	if page, err := db.GetAllStudents(other, storage.ListOptions{}); err != nil || page.Total != 0 {
+		t.Fatalf("GetAllStudents of another tenant: got %v and error %v, want none", ids(page.Students), err)
+	}
+	if found, err := db.SearchStudents(other, "ann", 10); err != nil || len(found) != 0 {
+		t.Fatalf("SearchStudents of another tenant: got %v and error %v, want none", ids(found), err)
+	}
+
+	// Emails are only unique within a tenant
+	create(t, db, other, "Ann Twin", "ann@example.edu", 15)
+}
+
+func testHistory(t *testing.T, db storage.Storage, ctx context.Context) {
+	history, ok := db.(storage.AuditStorage)
+	if !ok {
+		t.Skip("the backend keeps no student history")
+	}
+	ctx = audit.WithRequestID(audit.WithActor(ctx, "registrar"), "req-1")
+
+	// Every change is recorded, the pauses keep their times apart
+	id := create(t, db, ctx, "Ann Lee", "ann@example.edu", 15)
+	time.Sleep(10 * time.Millisecond)
+	if _, err := db.UpdateStudent(ctx, id, "Ann Smith", "ann@example.edu", 15, 0); err != nil {
+		t.Fatalf("UpdateStudent: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	age := 16
+	if _, err := db.PatchStudent(ctx, id, types.StudentPatch{Age: &age}, 0); err != nil {
+		t.Fatalf("PatchStudent: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := db.DeleteStudentById(ctx, id, 0); err != nil {
+		t.Fatalf("DeleteStudentById: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := db.RestoreStudent(ctx, id); err != nil {
+		t.Fatalf("RestoreStudent: %v", err)
+	}
+
+	entries, err := history.GetStudentHistory(ctx, id)
+	if err != nil {
+		t.Fatalf("GetStudentHistory: %v", err)
+	}
+	actions := []string{types.AuditCreate, types.AuditUpdate, types.AuditUpdate, types.AuditDelete, types.AuditRestore}
+	if len(entries) != len(actions) {
+		t.Fatalf("GetStudentHistory: got %d entries, want %d", len(entries), len(actions))
+	}
+	for i, entry := range entries {
+		if entry.Action != actions[i] || entry.Actor != "registrar" || entry.RequestId != "req-1" || entry.StudentId != id {
+			t.Fatalf("GetStudentHistory entry %d: got %+v, want a %s of student %d by registrar in req-1", i, entry, actions[i], id)
+		}
+	}
+	if change, ok := entries[1].Changes["name"]; !ok || change.Before != "Ann Lee" || change.After != "Ann Smith" || len(entries[1].Changes) != 1 {
+		t.Fatalf("GetStudentHistory update changes: got %v, want only the name", entries[1].Changes)
+	}
+
+	// The point-in-time view replays the history
+	student, err := history.GetStudentAsOf(ctx, id, entries[1].At)
+	if err != nil || student.Name != "Ann Smith" || student.Age != 15 {
+		t.Fatalf("GetStudentAsOf after the update: got %+v and error %v, want Ann Smith aged 15", student, err)
+	}
+	_, err = history.GetStudentAsOf(ctx, id, entries[0].At.Add(-time.Second))
+	wantErr(t, "GetStudentAsOf before the creation", err, storage.ErrNotFound)
+	_, err = history.GetStudentAsOf(ctx, id, entries[3].At)
+	wantErr(t, "GetStudentAsOf while in the trash", err, storage.ErrNotFound)
+	if student, err := history.GetStudentAsOf(ctx, id, time.Now()); err != nil || student.Age != 16 || student.DeletedAt != nil {
+		t.Fatalf("GetStudentAsOf now: got %+v and error %v, want the restored student aged 16", student, err)
+	}
+
+	_, err = history.GetStudentHistory(ctx, id+1000)
+	wantErr(t, "GetStudentHistory of an unknown id", err, storage.ErrNotFound)
+}
@@ -0,0 +1,158 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+)
+
+// Default is the tenant of every request when tenancy is disabled
+// The data stored before tenancy was enabled belongs to it
+const Default = "default"
+
+var (
+	// ErrNoTenant is returned by Resolve when a request names no tenant and there is no default one
+	ErrNoTenant = errors.New("tenant required")
+	// ErrUnknownTenant is returned by Resolve when a request names a tenant that is not configured
+	ErrUnknownTenant = errors.New("unknown tenant")
+	// ErrTenantMismatch is returned by Resolve when a request names two different tenants,
+	// e.g. a client bound to one school asking for another
+	ErrTenantMismatch = errors.New("tenant mismatch")
+)
+
+// validID matches the accepted tenant IDs, they are DNS labels so that every tenant can have its subdomain
+var validID = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// contextKey is the type of the context keys of this package, it avoids collisions with other packages
+type contextKey int
+
+const idKey contextKey = iota
+
+// WithID returns a copy of the context carrying the tenant of the request
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey, id)
+}
+
+// ID returns the tenant carried by the context, Default if there is none
+// Every storage backend scopes the students it reads and writes to this tenant
+func ID(ctx context.Context) string {
+	if id, ok := ctx.Value(idKey).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
+
+// Settings struct holds a setting that tenants may override, e.g. the grading scale
+type Settings[T any] struct {
+	Default T            // Default is the setting of the tenants that do not override it
+	Tenants map[string]T // Tenants holds the setting of every tenant overriding it
+}
+
+// For returns the setting of the tenant carried by the context
+func (s Settings[T]) For(ctx context.Context) T {
+	if setting, ok := s.Tenants[ID(ctx)]; ok {
+		return setting
+	}
+	return s.Default
+}
+
+// Resolver struct finds out the tenant of a request, from a header, a subdomain or the client's identity
+type Resolver struct {
+	header   string
+	domain   string
+	fallback string
+	known    map[string]bool
+}
+
+// NewResolver function builds the Resolver configured by the tenancy section of the config
+// It returns an error if a tenant ID is not a lowercase DNS label or the default tenant is not configured
+func NewResolver(cfg config.Tenancy) (*Resolver, error) {
+	if len(cfg.Tenants) == 0 {
+		return nil, fmt.Errorf("tenancy: no tenant is configured")
+	}
+
+	res := &Resolver{
+		header:   cfg.Header,
+		domain:   strings.ToLower(strings.Trim(cfg.Domain, ".")),
+		fallback: cfg.Default,
+		known:    make(map[string]bool, len(cfg.Tenants)),
+	}
+	for id := range cfg.Tenants {
+		if !validID.MatchString(id) {
+			return nil, fmt.Errorf("tenancy: tenant id %q must be a lowercase DNS label, e.g. \"north-high\"", id)
+		}
+		res.known[id] = true
+	}
+	if res.fallback != "" && !res.known[res.fallback] {
+		return nil, fmt.Errorf("tenancy: the default tenant %q is not configured", res.fallback)
+	}
+
+	return res, nil
+}
+
+// Resolve returns the tenant of a request
+// The tenant is named by the header or the subdomain of the request, bound is the tenant of the client's identity if any
+// and roaming tells whether a client bound to none may reach every tenant, e.g. an operator of the instance
+// A client bound to a tenant can only reach that one, a client bound to none that may not roam only the default tenant,
+// and a request naming none falls back to the default tenant
+func (res *Resolver) Resolve(r *http.Request, bound string, roaming bool) (string, error) {
+	requested := ""
+	if res.header != "" {
+		requested = strings.ToLower(strings.TrimSpace(r.Header.Get(res.header)))
+	}
+	if sub := res.subdomain(r.Host); sub != "" {
+		if requested != "" && requested != sub {
+			return "", fmt.Errorf("%w: the %s header names %q but the host names %q", ErrTenantMismatch, res.header, requested, sub)
+		}
+		requested = sub
+	}
+
+	// A token without a tenant claim must not open every school, it is held to the default one
+	if bound == "" && !roaming {
+		if res.fallback == "" {
+			return "", fmt.Errorf("%w: the client belongs to no tenant and there is no default one", ErrTenantMismatch)
+		}
+		bound = res.fallback
+	}
+
+	if bound != "" {
+		if requested != "" && requested != bound {
+			return "", fmt.Errorf("%w: the client belongs to tenant %q, not %q", ErrTenantMismatch, bound, requested)
+		}
+		requested = bound
+	}
+
+	if requested == "" {
+		requested = res.fallback
+	}
+	if requested == "" {
+		return "", fmt.Errorf("%w, name it with the %s header", ErrNoTenant, res.header)
+	}
+	if !res.known[requested] {
+		return "", fmt.Errorf("%w %q", ErrUnknownTenant, requested)
+	}
+
+	return requested, nil
+}
+
+// subdomain returns the tenant named by a host of the form <tenant>.<domain>, or "" for any other host
+func (res *Resolver) subdomain(host string) string {
+	if res.domain == "" {
+		return ""
+	}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+res.domain)
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
@@ -0,0 +1,125 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/config"
+	"github.com/Priyang1310/Students-API-GO/internal/tenant"
+)
+
+// newResolver builds a resolver of the default, north-high and south-academy tenants
+func newResolver(t *testing.T, fallback string) *tenant.Resolver {
+	t.Helper()
+
+	resolver, err := tenant.NewResolver(config.Tenancy{
+		Header:  "X-Tenant-ID",
+		Domain:  "schools.example.edu.",
+		Default: fallback,
+		Tenants: map[string]config.Tenant{"default": {}, "north-high": {}, "south-academy": {}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolver
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback string
+		host     string
+		header   string
+		bound    string // bound is the tenant claim of the client
+		roaming  bool   // roaming is whether the client may reach every tenant, e.g. through tenants:cross
+		want     string
+		wantErr  error
+	}{
+		{"header", "default", "api.example.edu", "north-high", "", true, "north-high", nil},
+		{"header in upper case", "default", "api.example.edu", " North-High ", "", true, "north-high", nil},
+		{"subdomain", "default", "north-high.schools.example.edu", "", "", true, "north-high", nil},
+		{"subdomain with a port", "default", "South-Academy.schools.example.edu:3000", "", "", true, "south-academy", nil},
+		{"nested subdomain", "default", "a.north-high.schools.example.edu", "", "", true, "default", nil},
+		{"other domain", "default", "north-high.example.org", "", "", true, "default", nil},
+		{"header matching the subdomain", "default", "north-high.schools.example.edu", "north-high", "", true, "north-high", nil},
+		{"header contradicting the subdomain", "default", "north-high.schools.example.edu", "south-academy", "", true, "", tenant.ErrTenantMismatch},
+		{"nothing named", "default", "api.example.edu", "", "", true, "default", nil},
+		{"nothing named without default", "", "api.example.edu", "", "", true, "", tenant.ErrNoTenant},
+		{"unknown tenant", "default", "api.example.edu", "east-high", "", true, "", tenant.ErrUnknownTenant},
+		{"unknown subdomain", "default", "east-high.schools.example.edu", "", "", true, "", tenant.ErrUnknownTenant},
+
+		// The tenant claim binds the client to its school
+		{"claim", "default", "api.example.edu", "", "north-high", false, "north-high", nil},
+		{"claim matching the header", "default", "api.example.edu", "north-high", "north-high", false, "north-high", nil},
+		{"claim asking for another tenant by header", "default", "api.example.edu", "south-academy", "north-high", false, "", tenant.ErrTenantMismatch},
+		{"claim asking for another tenant by subdomain", "default", "south-academy.schools.example.edu", "", "north-high", false, "", tenant.ErrTenantMismatch},
+		{"claim with tenants:cross still bound", "default", "api.example.edu", "south-academy", "north-high", true, "", tenant.ErrTenantMismatch},
+		{"claim of an unknown tenant", "default", "api.example.edu", "", "east-high", false, "", tenant.ErrUnknownTenant},
+
+		// A client without claim is held to the default tenant, unless it may roam
+		{"no claim", "default", "api.example.edu", "", "", false, "default", nil},
+		{"no claim asking for another tenant", "default", "api.example.edu", "north-high", "", false, "", tenant.ErrTenantMismatch},
+		{"no claim with tenants:cross", "default", "api.example.edu", "north-high", "", true, "north-high", nil},
+		{"no claim without default", "", "api.example.edu", "north-high", "", false, "", tenant.ErrTenantMismatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/students", nil)
+			req.Host = test.host
+			if test.header != "" {
+				req.Header.Set("X-Tenant-ID", test.header)
+			}
+
+			got, err := newResolver(t, test.fallback).Resolve(req, test.bound, test.roaming)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got %q, %v, want %v", got, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Fatalf("got %q, %v, want %q", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestNewResolverRejectsBadTenancy(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Tenancy
+	}{
+		{"no tenants", config.Tenancy{Default: "default"}},
+		{"upper case id", config.Tenancy{Tenants: map[string]config.Tenant{"North-High": {}}}},
+		{"id with a dot", config.Tenancy{Tenants: map[string]config.Tenant{"north.high": {}}}},
+		{"unknown default", config.Tenancy{Default: "default", Tenants: map[string]config.Tenant{"north-high": {}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := tenant.NewResolver(test.cfg); err == nil {
+				t.Fatal("got no error")
+			}
+		})
+	}
+}
+
+func TestSettings(t *testing.T) {
+	settings := tenant.Settings[string]{Default: "A4", Tenants: map[string]string{"north-high": "Letter"}}
+
+	if got := settings.For(context.Background()); got != "A4" {
+		t.Errorf("no tenant: got %q, want the default", got)
+	}
+	if got := settings.For(tenant.WithID(context.Background(), "north-high")); got != "Letter" {
+		t.Errorf("tenant overriding the setting: got %q, want its own", got)
+	}
+	if got := settings.For(tenant.WithID(context.Background(), "south-academy")); got != "A4" {
+		t.Errorf("tenant keeping the setting: got %q, want the default", got)
+	}
+	if got := tenant.ID(context.Background()); got != tenant.Default {
+		t.Errorf("ID without tenant: got %q, want %q", got, tenant.Default)
+	}
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// APIKey struct represents an API key of a service client, e.g. a nightly import job
+// Only the hash of the key is stored, the key itself is returned once when it is issued
+// Scopes are the permissions granted to the key, in the format of the rbac package (e.g. "grades:read")
+type APIKey struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Prefix is the start of the key, it tells keys apart without revealing them
+	Scopes     []string   `json:"scopes"`
+	Tenant     string     `json:"tenant"` // Tenant is the school the key was issued in, the only one it can reach
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyRequest struct holds the fields of a request to issue an API key, a key without expiry never expires
+type APIKeyRequest struct {
+	Name      string     `json:"name"       validate:"required,max=100"`
+	Scopes    []string   `json:"scopes"     validate:"required,min=1,max=50,dive,required,max=100"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// IssuedAPIKey struct is the response to issuing an API key, the only response ever holding the key
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+// Attachment struct represents a file attached to a student, e.g. a photo or a scanned document
+// The content lives in the blob directory under BlobKey, this is only its metadata
+type Attachment struct {
+	Id          int64     `json:"id"`
+	StudentId   int64     `json:"student_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+	BlobKey     string    `json:"-"`
+}
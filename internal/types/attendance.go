@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// Attendance statuses of a student for a day
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceLate    = "late"
+	AttendanceExcused = "excused"
+)
+
+// Attendance struct represents the attendance of a student on one day
+// Date is a calendar day formatted as YYYY-MM-DD, a student has at most one record per day
+type Attendance struct {
+	Id         int64     `json:"id"`
+	StudentId  int64     `json:"student_id" validate:"required,gt=0"`
+	Date       string    `json:"date"`
+	Status     string    `json:"status"     validate:"required,oneof=present absent late excused"`
+	Note       string    `json:"note,omitempty" validate:"max=500"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// AttendanceSheet struct is the attendance of a whole class for one day, submitted at once
+// The date of the sheet applies to every record, a student may appear only once
+type AttendanceSheet struct {
+	Date    string       `json:"date"    validate:"required,datetime=2006-01-02"`
+	Records []Attendance `json:"records" validate:"required,min=1,max=1000,unique=StudentId,dive"`
+}
+
+// AttendanceSummary struct counts the attendance records of a student over a date range
+// Rate is the share of days the student attended (present or late), excused days are not counted
+type AttendanceSummary struct {
+	StudentId int64   `json:"student_id"`
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Days      int     `json:"days"`
+	Present   int     `json:"present"`
+	Absent    int     `json:"absent"`
+	Late      int     `json:"late"`
+	Excused   int     `json:"excused"`
+	Rate      float64 `json:"rate"`
+}
@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+// Actions recorded in the history of a student
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+)
+
+// AuditEntry struct represents one change in the history of a student
+// Before is nil for a creation, the entries of a student are never changed or removed
+type AuditEntry struct {
+	Id        int64                  `json:"id"`
+	StudentId int64                  `json:"student_id"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor"`
+	RequestId string                 `json:"request_id,omitempty"`
+	At        time.Time              `json:"at"`
+	Before    *Student               `json:"before"`
+	After     *Student               `json:"after"`
+	Changes   map[string]FieldChange `json:"changes"`
+}
+
+// FieldChange struct holds the old and new value of a field changed by an AuditEntry
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
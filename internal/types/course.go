@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// Course struct represents a course of the catalog
+// Enrolled is computed by the storage and ignored on input
+type Course struct {
+	Id       int64  `json:"id"`
+	Code     string `json:"code"     validate:"required,min=2,max=20"`
+	Title    string `json:"title"    validate:"required,max=200"`
+	Credits  int    `json:"credits"  validate:"gte=0,lte=30"`
+	Capacity int    `json:"capacity" validate:"gte=1,lte=10000"`
+	Enrolled int    `json:"enrolled"`
+}
+
+// Enrollment struct represents a student taking a course
+type Enrollment struct {
+	Id         int64     `json:"id"`
+	StudentId  int64     `json:"student_id"`
+	CourseId   int64     `json:"course_id"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
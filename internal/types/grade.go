@@ -0,0 +1,42 @@
+package types
+
+import "time"
+
+// Grade struct represents the score of a student in a course for one term
+// Letter and Points are derived from the score by the configured grading scale
+// Score is a pointer so that a missing score is told apart from a score of 0
+// TermStart is the first day of the term formatted as YYYY-MM-DD, transcripts list the terms in that order
+type Grade struct {
+	Id        int64     `json:"id"`
+	StudentId int64     `json:"student_id"`
+	CourseId  int64     `json:"course_id" validate:"required,gt=0"`
+	Term      string    `json:"term"      validate:"required,max=20"`
+	TermStart string    `json:"term_start" validate:"required,datetime=2006-01-02"`
+	Score     *float64  `json:"score"     validate:"required,gte=0,lte=100"`
+	Letter    string    `json:"letter"`
+	Points    float64   `json:"points"`
+	GradedAt  time.Time `json:"graded_at"`
+}
+
+// CourseGrade struct is a grade together with the course it was given in
+type CourseGrade struct {
+	Grade
+	Course Course `json:"course"`
+}
+
+// TermSummary struct holds the grades of one term and their credit weighted GPA
+type TermSummary struct {
+	Term      string        `json:"term"`
+	TermStart string        `json:"term_start"`
+	Grades    []CourseGrade `json:"grades"`
+	Credits   int           `json:"credits"`
+	GPA       float64       `json:"gpa"`
+}
+
+// Transcript struct holds every term of a student and the cumulative credit weighted GPA
+type Transcript struct {
+	StudentId int64         `json:"student_id"`
+	Terms     []TermSummary `json:"terms"`
+	Credits   int           `json:"credits"`
+	GPA       float64       `json:"gpa"`
+}
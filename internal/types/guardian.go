@@ -0,0 +1,19 @@
+package types
+
+// Guardian struct represents a guardian or emergency contact of a student
+// Name, phone and email belong to the guardian and are shared by all their students,
+// relationship and priority describe the link to one student (priority 1 is contacted first)
+type Guardian struct {
+	Id           int64  `json:"id"`
+	Name         string `json:"name"            validate:"required,person_name"`
+	Relationship string `json:"relationship"    validate:"required,max=50"`
+	Phone        string `json:"phone"           validate:"required,e164"`
+	Email        string `json:"email,omitempty" validate:"omitempty,email,max=254"`
+	Priority     int    `json:"priority"        validate:"gte=1,lte=10"`
+}
+
+// GuardianLink struct holds the fields linking an existing guardian to another student
+type GuardianLink struct {
+	Relationship string `json:"relationship" validate:"required,max=50"`
+	Priority     int    `json:"priority"     validate:"gte=1,lte=10"`
+}
@@ -1,8 +1,33 @@
 package types
 
+import "time"
+
+// Student struct represents a student
+// Requests creating or changing a student are decoded into a StudentRequest and validated there
 type Student struct {
 	Id    int64  `json:"id"`
-	Email string `json:"email" validate:"required"`
-	Name  string `json:"name"  validate:"required"`
-	Age   int    `json:"age"  validate:"required"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Age   int    `json:"age"`
+	// Version is incremented by every update, it is exposed as the ETag of the student
+	Version int64 `json:"version"`
+	// DeletedAt is when the student was moved to the trash, it is only set on trashed students
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// StudentRequest struct holds the fields of a request creating, replacing or patching a student
+// The student_age and person_name rules are registered by the validation package, their limits come from the config
+// Age is a pointer, so a missing age is reported as required while an age of 0 is checked against the range like any other
+type StudentRequest struct {
+	Email string `json:"email" validate:"required,email,max=254"`
+	Name  string `json:"name"  validate:"required,person_name"`
+	Age   *int   `json:"age"   validate:"required,student_age"`
+}
+
+// StudentPatch holds the fields of a partial student update
+// A nil field is left unchanged
+type StudentPatch struct {
+	Name  *string
+	Email *string
+	Age   *int
 }
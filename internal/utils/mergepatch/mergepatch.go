@@ -0,0 +1,56 @@
+package mergepatch
+
+import (
+	"encoding/json" // Package for JSON encoding and decoding
+	"errors"
+)
+
+// ErrNotObject is returned when a patch is not a JSON object
+var ErrNotObject = errors.New("merge patch must be a JSON object")
+
+// Apply applies an RFC 7396 JSON Merge Patch to a JSON document
+// It takes the original document and the patch and returns the patched document
+// Members of the patch replace the members of the document, null removes a member
+// and nested objects are merged recursively
+func Apply(document []byte, patch []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, err
+	}
+
+	var changes any
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, err
+	}
+
+	// A resource is always an object, replacing it by a scalar or an array is not a partial update
+	if _, ok := changes.(map[string]any); !ok {
+		return nil, ErrNotObject
+	}
+
+	return json.Marshal(merge(target, changes))
+}
+
+// merge implements the MergePatch(Target, Patch) function of RFC 7396
+func merge(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		// A non-object patch replaces the target entirely
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = merge(targetObject[name], value)
+	}
+
+	return targetObject
+}
@@ -0,0 +1,61 @@
+package mergepatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/utils/mergepatch"
+)
+
+// The cases with an object patch are the examples of RFC 7396, appendix A
+func TestApply(t *testing.T) {
+	tests := []struct {
+		document string
+		patch    string
+		want     string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		got, err := mergepatch.Apply([]byte(tt.document), []byte(tt.patch))
+		if err != nil {
+			t.Fatalf("Apply(%s, %s): %v", tt.document, tt.patch, err)
+		}
+
+		var gotValue, wantValue any
+		if err := json.Unmarshal(got, &gotValue); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gotValue, wantValue) {
+			t.Errorf("Apply(%s, %s): got %s, want %s", tt.document, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestApplyRejectsNonObjectPatches(t *testing.T) {
+	for _, patch := range []string{`["c"]`, `"bar"`, `null`, `3`} {
+		if _, err := mergepatch.Apply([]byte(`{"a":"b"}`), []byte(patch)); !errors.Is(err, mergepatch.ErrNotObject) {
+			t.Errorf("Apply with the patch %s: got error %v, want ErrNotObject", patch, err)
+		}
+	}
+
+	if _, err := mergepatch.Apply([]byte(`{"a":"b"}`), []byte(`{"a":`)); err == nil {
+		t.Errorf("Apply with malformed JSON: got no error")
+	}
+}
@@ -0,0 +1,39 @@
+package request
+
+import (
+	"encoding/json" // Package for JSON encoding and decoding
+	"errors"        // Package for error handling
+	"fmt"           // Package for formatted I/O
+	"io"            // Package for I/O primitives
+	"net/http"      // Package for HTTP client and server
+	"strconv"       // Package for parsing numbers
+
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response" // Package for writing problem responses
+)
+
+// PathID parses the ID held by the named path value of the request
+// kind names the resource in the error message, e.g. "invalid course id"
+func PathID(r *http.Request, name string, kind string) (int64, error) {
+	value := r.PathValue(name)
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s id %q", kind, value)
+	}
+	return id, nil
+}
+
+// DecodeJSON decodes the JSON request body into v
+// It writes a 400 Bad Request problem and returns false if the body is empty or malformed
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if errors.Is(err, io.EOF) {
+		response.WriteError(w, r, http.StatusBadRequest, fmt.Errorf("empty body"))
+		return false
+	}
+	if err != nil {
+		response.WriteError(w, r, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
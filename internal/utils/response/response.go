@@ -2,18 +2,42 @@ package response
 
 import (
 	"encoding/json" // Package for encoding and decoding JSON
-	"fmt"           // Package for formatted I/O
+	"errors"        // Package for error handling
 	"net/http"      // Package for HTTP client and server
-	"strings"       // Package for string manipulation
+	"strconv"       // Package for converting numbers to strings
 
-	"github.com/go-playground/validator/v10" // Package for data validation
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation" // Package for the shared validator and its translations
+	ut "github.com/go-playground/universal-translator"                 // Package for translating messages
+	"github.com/go-playground/validator/v10"                           // Package for data validation
 )
 
-// Response struct defines the structure of the JSON response
-// It contains two fields: Status and Error
-type Response struct {
-	Status string // Status of the response (e.g., "Error" or "Success")
-	Error  string // Error message if applicable
+const (
+	// ProblemContentType is the media type of RFC 7807 problem details
+	ProblemContentType = "application/problem+json"
+
+	// TypeBlank is the problem type of errors that need no explanation beyond their HTTP status
+	TypeBlank = "about:blank"
+	// TypeValidation is the problem type of requests whose body failed validation
+	TypeValidation = "urn:students-api:problem:validation-error"
+)
+
+// Problem struct defines an RFC 7807 problem details response body
+// Errors is an extension member listing every invalid field of a validation problem
+type Problem struct {
+	Type     string       `json:"type"`               // URI identifying the kind of problem
+	Title    string       `json:"title"`              // Short summary of the kind of problem
+	Status   int          `json:"status"`             // HTTP status code of the response
+	Detail   string       `json:"detail,omitempty"`   // Explanation specific to this occurrence
+	Instance string       `json:"instance,omitempty"` // URI of the request that caused the problem
+	Errors   []FieldError `json:"errors,omitempty"`   // Invalid fields, only set for validation problems
+}
+
+// FieldError struct describes why a single field failed validation
+type FieldError struct {
+	Field   string `json:"field"`           // Name of the field as it appears in the JSON body
+	Tag     string `json:"tag"`             // Validation rule that failed (e.g. "required")
+	Param   string `json:"param,omitempty"` // Parameter of the rule, if any (e.g. "3" for "min=3")
+	Message string `json:"message"`         // Human readable description of the failure
 }
 
 // WriteJSON writes a JSON response to the http.ResponseWriter
@@ -29,38 +53,88 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) error {
 	return json.NewEncoder(w).Encode(data)
 }
 
-// GeneralError creates a generic error response
-// It takes an error as input and returns a Response struct with the error message
-func GeneralError(err error) Response {
-	// Create a Response struct with the error message
-	return Response{
-		Status: "Error", // Set status to "Error"
-		Error:  err.Error(), // Include the error message
+// WriteProblem writes an application/problem+json response to the http.ResponseWriter
+// The instance defaults to the path of the request when it is not set
+func WriteProblem(w http.ResponseWriter, r *http.Request, problem Problem) error {
+	if problem.Instance == "" {
+		problem.Instance = r.URL.Path
+	}
+
+	// Set the Content-Type header to application/problem+json
+	w.Header().Set("Content-Type", ProblemContentType)
+	// Write the HTTP status code
+	w.WriteHeader(problem.Status)
+
+	// Encode the problem as JSON and write it to the response
+	return json.NewEncoder(w).Encode(problem)
+}
+
+// GeneralError creates a problem for an error that needs no explanation beyond its HTTP status
+// It takes the HTTP status code and the error, whose message becomes the detail of the problem
+func GeneralError(status int, err error) Problem {
+	return Problem{
+		Type:   TypeBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Error(),
 	}
 }
 
-// ValidationError creates a validation error response based on validation errors
-// It takes a slice of validation errors as input and returns a Response struct with the error messages
-func ValidationError(errs validator.ValidationErrors) Response {
-	// Create a slice to hold individual error messages
-	var errMsgs []string
+// WriteError writes a GeneralError problem response for the error
+func WriteError(w http.ResponseWriter, r *http.Request, status int, err error) error {
+	return WriteProblem(w, r, GeneralError(status, err))
+}
+
+// ValidationError creates a 400 Bad Request problem based on validation errors
+// It takes a slice of validation errors and the translator of the client's language,
+// and returns a Problem listing every invalid field with a message in that language
+func ValidationError(errs validator.ValidationErrors, trans ut.Translator) Problem {
+	// Create a slice to hold one entry per invalid field
+	fieldErrs := make([]FieldError, 0, len(errs))
 
 	// Iterate over each validation error
 	for _, err := range errs {
-		// Check the type of validation error
-		switch err.ActualTag() {
-		case "required":
-			// If the field is required but not provided, add a specific message
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is required", err.Field()))
-		default:
-			// For other types of validation errors, add a general invalid message
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is invalid", err.Field()))
-		}
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:   err.Field(),
+			Tag:     err.ActualTag(),
+			Param:   err.Param(),
+			Message: err.Translate(trans),
+		})
 	}
 
-	// Join all error messages into a single string and return the response
-	return Response{
-		Status: "Error", // Set status to "Error"
-		Error: strings.Join(errMsgs, ", "), // Combine all error messages
+	return Problem{
+		Type:   TypeValidation,
+		Title:  validation.Message(trans, validation.KeyTitle),
+		Status: http.StatusBadRequest,
+		Detail: validation.Message(trans, validation.KeyDetail, strconv.Itoa(len(fieldErrs))),
+		Errors: fieldErrs,
 	}
-}
\ No newline at end of file
+}
+
+// WriteValidationError writes a ValidationError problem response for the validation errors
+// The language of the messages is negotiated from the Accept-Language header of the request
+func WriteValidationError(w http.ResponseWriter, r *http.Request, errs validator.ValidationErrors) error {
+	trans := validation.Translator(r.Header.Get("Accept-Language"))
+
+	// Tell the client which language the messages are in, and caches that it depends on the request
+	w.Header().Set("Content-Language", trans.Locale())
+	w.Header().Add("Vary", "Accept-Language")
+
+	return WriteProblem(w, r, ValidationError(errs, trans))
+}
+
+// Validated writes the problem matching the result of a validation and reports whether it succeeded
+// Validation errors become a ValidationError problem, any other error means the value could not be validated at all
+func Validated(w http.ResponseWriter, r *http.Request, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var validateErr validator.ValidationErrors
+	if errors.As(err, &validateErr) {
+		WriteValidationError(w, r, validateErr)
+	} else {
+		WriteError(w, r, http.StatusInternalServerError, err)
+	}
+	return false
+}
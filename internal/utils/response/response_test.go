@@ -0,0 +1,190 @@
+package response_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/response"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+// decode checks that the response is a problem and decodes it
+func decode(t *testing.T, res *httptest.ResponseRecorder) response.Problem {
+	t.Helper()
+
+	if contentType := res.Header().Get("Content-Type"); contentType != response.ProblemContentType {
+		t.Fatalf("got Content-Type %q, want %s", contentType, response.ProblemContentType)
+	}
+
+	var problem response.Problem
+	if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
+
+func TestWriteError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/students/7?fields=name", nil)
+	res := httptest.NewRecorder()
+	response.WriteError(res, req, http.StatusNotFound, fmt.Errorf("student not found with id 7"))
+
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want 404", res.Code)
+	}
+	want := response.Problem{
+		Type:     response.TypeBlank,
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "student not found with id 7",
+		Instance: "/api/students/7",
+	}
+	if problem := decode(t, res); fmt.Sprint(problem) != fmt.Sprint(want) {
+		t.Fatalf("got %+v, want %+v", problem, want)
+	}
+
+	// The members of RFC 7807 are spelled as the RFC does, the empty errors extension is left out
+	var members map[string]any
+	if err := json.Unmarshal(res.Body.Bytes(), &members); err != nil {
+		t.Fatal(err)
+	}
+	for _, member := range []string{"type", "title", "status", "detail", "instance"} {
+		if _, ok := members[member]; !ok {
+			t.Errorf("problem lacks the %q member: %s", member, res.Body)
+		}
+	}
+	if _, ok := members["errors"]; ok {
+		t.Errorf("problem of a general error lists errors: %s", res.Body)
+	}
+}
+
+func TestWriteProblemKeepsInstance(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+	res := httptest.NewRecorder()
+	response.WriteProblem(res, req, response.Problem{Type: response.TypeBlank, Title: "Conflict", Status: http.StatusConflict, Instance: "/api/students/7"})
+
+	if problem := decode(t, res); res.Code != http.StatusConflict || problem.Instance != "/api/students/7" {
+		t.Fatalf("got %d %+v, want 409 for the given instance", res.Code, problem)
+	}
+}
+
+func TestWriteValidationError(t *testing.T) {
+	age := 200
+	err := validation.Student(&types.StudentRequest{Name: "Ada Lovelace", Email: "not an email", Age: &age})
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want validation errors", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/students", nil)
+	req.Header.Set("Accept-Language", "fr-CA, en;q=0.5")
+	res := httptest.NewRecorder()
+	response.WriteValidationError(res, req, errs)
+
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", res.Code)
+	}
+	if language := res.Header().Get("Content-Language"); language != "fr" {
+		t.Fatalf("got Content-Language %q, want fr", language)
+	}
+	if vary := res.Header().Get("Vary"); vary != "Accept-Language" {
+		t.Fatalf("got Vary %q, want Accept-Language", vary)
+	}
+
+	problem := decode(t, res)
+	if problem.Type != response.TypeValidation || problem.Status != http.StatusBadRequest || problem.Instance != "/api/students" {
+		t.Fatalf("got %+v, want a validation problem of /api/students", problem)
+	}
+	if problem.Title != "Votre requête n'est pas valide" || problem.Detail != "2 champ(s) n'ont pas passé la validation" {
+		t.Fatalf("got title %q and detail %q, want them in French", problem.Title, problem.Detail)
+	}
+
+	want := []response.FieldError{
+		{Field: "email", Tag: "email", Message: "email doit être une adresse email valide"},
+		{Field: "age", Tag: validation.TagStudentAge, Message: "age doit être compris entre 1 et 120"},
+	}
+	if fmt.Sprint(problem.Errors) != fmt.Sprint(want) {
+		t.Fatalf("got errors %+v, want %+v", problem.Errors, want)
+	}
+}
+
+func TestValidated(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/students", nil)
+
+	if res := httptest.NewRecorder(); !response.Validated(res, req, nil) || res.Body.Len() != 0 {
+		t.Fatalf("a valid value: got a response %s, want none", res.Body)
+	}
+
+	// An error that is not a validation error means the value could not be validated at all
+	res := httptest.NewRecorder()
+	if response.Validated(res, req, &validator.InvalidValidationError{}) {
+		t.Fatal("got validated, want a failure")
+	}
+	if problem := decode(t, res); res.Code != http.StatusInternalServerError || problem.Type != response.TypeBlank {
+		t.Fatalf("got %d %+v, want a 500 problem", res.Code, problem)
+	}
+}
+
+func TestStorageStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{storage.StudentNotFound(7), http.StatusNotFound},
+		{storage.EmailTaken("ada@example.com"), http.StatusConflict},
+		{storage.CourseFull(1, 30), http.StatusConflict},
+		{storage.NotEnrolled(7, 1), http.StatusConflict},
+		{storage.ErrVersionMismatch, http.StatusPreconditionFailed},
+		{storage.ErrInvalidCursor, http.StatusBadRequest},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{fmt.Errorf("query: %w", context.Canceled), response.StatusClientClosedRequest},
+		{errors.New("disk I/O error"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		if status := response.StorageStatus(test.err); status != test.status {
+			t.Errorf("%v: got %d, want %d", test.err, status, test.status)
+		}
+	}
+}
+
+func TestWriteStorageErrorHidesTimeouts(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+	res := httptest.NewRecorder()
+	response.WriteStorageError(res, req, fmt.Errorf("sqlite3: interrupted: %w", context.DeadlineExceeded))
+
+	if problem := decode(t, res); res.Code != http.StatusGatewayTimeout || problem.Detail != "storage query timed out" {
+		t.Fatalf("got %d %+v, want a 504 without the driver's wording", res.Code, problem)
+	}
+}
+
+func TestWriteStorageErrorCanceled(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+	res := httptest.NewRecorder()
+	response.WriteStorageError(res, req, fmt.Errorf("sqlite3: interrupted: %w", context.Canceled))
+
+	problem := decode(t, res)
+	if res.Code != response.StatusClientClosedRequest || problem.Title != "Client Closed Request" || strings.Contains(problem.Detail, "sqlite3") {
+		t.Fatalf("got %d %+v, want a 499 without the driver's wording", res.Code, problem)
+	}
+
+	// The client went away, the server did nothing wrong
+	if strings.Contains(logs.String(), "level=ERROR") {
+		t.Fatalf("got an error logged for a canceled request: %s", logs.String())
+	}
+}
@@ -0,0 +1,60 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Priyang1310/Students-API-GO/internal/storage"
+)
+
+// StatusClientClosedRequest is the non-standard status of a request the client gave up on before it was answered
+// net/http has no name for it, the status is the one popularized by nginx
+const StatusClientClosedRequest = 499
+
+// StorageStatus returns the HTTP status matching an error returned by the storage
+// Unexpected errors map to 500 Internal Server Error
+func StorageStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrConflict), errors.Is(err, storage.ErrCourseFull), errors.Is(err, storage.ErrNotEnrolled):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, storage.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteStorageError writes the problem response matching an error returned by the storage
+// Unexpected errors are logged, and timeouts are reported without the driver's wording
+// A query canceled because the client went away is not an error of the server, it is not logged as one
+func WriteStorageError(w http.ResponseWriter, r *http.Request, err error) error {
+	status := StorageStatus(err)
+
+	switch status {
+	case StatusClientClosedRequest:
+		slog.Info("Request canceled by the client", slog.String("path", r.URL.Path))
+		return WriteProblem(w, r, Problem{
+			Type:   TypeBlank,
+			Title:  "Client Closed Request",
+			Status: status,
+			Detail: "the request was canceled before it was answered",
+		})
+	case http.StatusGatewayTimeout:
+		err = fmt.Errorf("storage query timed out")
+	case http.StatusInternalServerError:
+		slog.Error("Storage error", slog.String("error", err.Error()))
+	}
+
+	return WriteError(w, r, status, err)
+}
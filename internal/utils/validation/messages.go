@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// messages holds the texts of every supported language that are not tied to a validation tag
+var messages = map[string]map[string]string{
+	"en": {
+		KeyTitle:  "Your request is not valid",
+		KeyDetail: "{0} field(s) failed validation",
+	},
+	"es": {
+		KeyTitle:  "Su solicitud no es válida",
+		KeyDetail: "{0} campo(s) no superaron la validación",
+	},
+	"fr": {
+		KeyTitle:  "Votre requête n'est pas valide",
+		KeyDetail: "{0} champ(s) n'ont pas passé la validation",
+	},
+	"hi": {
+		KeyTitle:  "आपका अनुरोध मान्य नहीं है",
+		KeyDetail: "{0} फ़ील्ड सत्यापन में विफल रहे",
+	},
+}
+
+// ruleMessages holds the messages of the custom tags in every supported language
+// {0} is the field, {1} and {2} are the lower and upper limits configured for the rule
+var ruleMessages = map[string]map[string]string{
+	"en": {
+		TagStudentAge: "{0} must be between {1} and {2}",
+		TagPersonName: "{0} must be {1} to {2} characters long and contain only letters, spaces, apostrophes, hyphens and periods",
+	},
+	"es": {
+		TagStudentAge: "{0} debe estar entre {1} y {2}",
+		TagPersonName: "{0} debe tener entre {1} y {2} caracteres y contener solo letras, espacios, apóstrofos, guiones y puntos",
+	},
+	"fr": {
+		TagStudentAge: "{0} doit être compris entre {1} et {2}",
+		TagPersonName: "{0} doit contenir de {1} à {2} caractères et uniquement des lettres, espaces, apostrophes, traits d'union et points",
+	},
+	"hi": {
+		TagStudentAge: "{0} {1} और {2} के बीच होना चाहिए",
+		TagPersonName: "{0} {1} से {2} वर्णों का होना चाहिए और उसमें केवल अक्षर, रिक्त स्थान, एपॉस्ट्रॉफ़ी, हाइफ़न और पूर्णविराम हो सकते हैं",
+	},
+}
+
+// hindi holds the Hindi messages of the built-in tags, the validator has no Hindi translations of its own
+// Tags whose message depends on the kind of the field have a "text" variant used for strings
+var hindi = map[string]string{
+	"required":   "{0} एक आवश्यक फ़ील्ड है",
+	"email":      "{0} एक मान्य ईमेल पता होना चाहिए",
+	"min":        "{0} कम से कम {1} होना चाहिए",
+	"min-text":   "{0} कम से कम {1} वर्णों का होना चाहिए",
+	"max":        "{0} अधिकतम {1} हो सकता है",
+	"max-text":   "{0} अधिकतम {1} वर्णों का हो सकता है",
+	"len":        "{0} ठीक {1} होना चाहिए",
+	"len-text":   "{0} ठीक {1} वर्णों का होना चाहिए",
+	"gte":        "{0} कम से कम {1} होना चाहिए",
+	"lte":        "{0} अधिकतम {1} हो सकता है",
+	"gt":         "{0} {1} से अधिक होना चाहिए",
+	"lt":         "{0} {1} से कम होना चाहिए",
+	"oneof":      "{0} इनमें से एक होना चाहिए: {1}",
+	"alphanum":   "{0} में केवल अक्षर और अंक हो सकते हैं",
+	"numeric":    "{0} एक संख्या होनी चाहिए",
+	"url":        "{0} एक मान्य URL होना चाहिए",
+	"datetime":   "{0} का प्रारूप {1} होना चाहिए",
+	"e164":       "{0} एक मान्य E.164 फ़ोन नंबर होना चाहिए",
+	"uuid":       "{0} एक मान्य UUID होना चाहिए",
+	"excludes":   "{0} में '{1}' नहीं हो सकता",
+	"unique":     "{0} में दोहराए गए मान नहीं हो सकते",
+	"printascii": "{0} में केवल प्रिंट करने योग्य ASCII वर्ण हो सकते हैं",
+}
+
+// registerHindi registers the Hindi messages of the built-in tags
+func registerHindi(v *validator.Validate, trans ut.Translator) error {
+	for key, text := range hindi {
+		if err := trans.Add(key, text, false); err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range []string{"required", "email", "min", "max", "len", "gte", "lte", "gt", "lt", "oneof", "alphanum", "numeric", "url", "datetime", "e164", "uuid", "excludes", "unique", "printascii"} {
+		err := v.RegisterTranslation(tag, trans, func(ut.Translator) error { return nil }, translateFieldError)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// translateFieldError renders a field error with the message registered for its tag
+// Strings use the "text" variant of the message when there is one, since their limits count characters
+func translateFieldError(trans ut.Translator, fe validator.FieldError) string {
+	key := fe.Tag()
+	if fe.Kind() == reflect.String {
+		if _, err := trans.T(key+"-text", fe.Field(), fe.Param()); err == nil {
+			key += "-text"
+		}
+	}
+
+	text, err := trans.T(key, fe.Field(), fe.Param())
+	if err != nil {
+		return fe.Error()
+	}
+	return text
+}
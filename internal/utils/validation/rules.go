@@ -0,0 +1,221 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom validation tags registered by this package
+const (
+	// TagStudentAge checks that an age lies within Rules.AgeMin and Rules.AgeMax
+	TagStudentAge = "student_age"
+	// TagPersonName checks the length and the characters of a name
+	TagPersonName = "person_name"
+)
+
+// Rules holds the configurable limits of the custom validators
+type Rules struct {
+	AgeMin        int // AgeMin is the youngest accepted age
+	AgeMax        int // AgeMax is the oldest accepted age
+	NameMinLength int // NameMinLength is the minimum number of characters of a name
+	NameMaxLength int // NameMaxLength is the maximum number of characters of a name
+}
+
+// rules are the limits in effect, Configure replaces them at startup
+var rules = Rules{AgeMin: 1, AgeMax: 120, NameMinLength: 2, NameMaxLength: 100}
+
+// Configure replaces the limits of the custom validators
+// It must be called before the validator is used, and returns an error if an age is negative or the limits are inconsistent
+func Configure(r Rules) error {
+	if r.AgeMin < 0 || r.AgeMin > r.AgeMax {
+		return fmt.Errorf("validation: age range %d-%d is invalid", r.AgeMin, r.AgeMax)
+	}
+	if r.NameMinLength < 1 || r.NameMinLength > r.NameMaxLength {
+		return fmt.Errorf("validation: name length range %d-%d is invalid", r.NameMinLength, r.NameMaxLength)
+	}
+
+	rules = r
+	return nil
+}
+
+// registerValidators registers the custom validation tags
+func registerValidators(v *validator.Validate) error {
+	if err := v.RegisterValidation(TagStudentAge, validStudentAge); err != nil {
+		return err
+	}
+	return v.RegisterValidation(TagPersonName, validPersonName)
+}
+
+// validStudentAge reports whether the age lies within the configured range
+// The validator hands it the age a pointer field points to, a nil one has already failed required
+func validStudentAge(fl validator.FieldLevel) bool {
+	age := fl.Field().Int()
+	return age >= int64(rules.AgeMin) && age <= int64(rules.AgeMax)
+}
+
+// validPersonName reports whether the name has an accepted length and only accepted characters
+// Names start with a letter and may contain letters of any script, combining marks, spaces,
+// apostrophes, hyphens and periods (e.g. "María-José O'Neil Jr.")
+func validPersonName(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+
+	length := utf8.RuneCountInString(name)
+	if length < rules.NameMinLength || length > rules.NameMaxLength {
+		return false
+	}
+
+	for i, c := range name {
+		switch {
+		case unicode.IsLetter(c):
+		case i == 0:
+			return false
+		case unicode.Is(unicode.M, c), c == ' ', c == '\'', c == '’', c == '-', c == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// ruleParams returns the parameters of the message of a custom tag, taken from the limits in effect
+func ruleParams(tag string) (string, string) {
+	switch tag {
+	case TagStudentAge:
+		return strconv.Itoa(rules.AgeMin), strconv.Itoa(rules.AgeMax)
+	case TagPersonName:
+		return strconv.Itoa(rules.NameMinLength), strconv.Itoa(rules.NameMaxLength)
+	default:
+		return "", ""
+	}
+}
+
+// registerRuleTranslations registers the messages of the custom tags in one language
+func registerRuleTranslations(v *validator.Validate, trans ut.Translator, texts map[string]string) error {
+	for _, tag := range []string{TagStudentAge, TagPersonName} {
+		if err := trans.Add(tag, texts[tag], false); err != nil {
+			return err
+		}
+
+		err := v.RegisterTranslation(tag, trans, func(ut.Translator) error { return nil }, func(trans ut.Translator, fe validator.FieldError) string {
+			low, high := ruleParams(fe.Tag())
+			text, err := trans.T(fe.Tag(), fe.Field(), low, high)
+			if err != nil {
+				return fe.Error()
+			}
+			return text
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NormalizeStudent puts the student's fields in their canonical form before validation
+// Surrounding and repeated whitespace is removed from the name, the email is trimmed and lowercased
+func NormalizeStudent(student *types.StudentRequest) {
+	student.Name = strings.Join(strings.Fields(student.Name), " ")
+	student.Email = strings.ToLower(strings.TrimSpace(student.Email))
+}
+
+// Student normalizes the student and validates it against the rules of types.StudentRequest
+// Create, update and patch all go through it, so they accept exactly the same data
+func Student(student *types.StudentRequest) error {
+	NormalizeStudent(student)
+	return validate.Struct(student)
+}
+
+// NormalizeCourse puts the course's fields in their canonical form before validation
+// Codes are trimmed and uppercased, surrounding and repeated whitespace is removed from the title
+func NormalizeCourse(course *types.Course) {
+	course.Code = strings.ToUpper(strings.TrimSpace(course.Code))
+	course.Title = strings.Join(strings.Fields(course.Title), " ")
+}
+
+// Course normalizes the course and validates it against the rules of types.Course
+func Course(course *types.Course) error {
+	NormalizeCourse(course)
+	return validate.Struct(course)
+}
+
+// Grade trims the term and its start and validates the grade against the rules of types.Grade
+func Grade(grade *types.Grade) error {
+	grade.Term = strings.TrimSpace(grade.Term)
+	grade.TermStart = strings.TrimSpace(grade.TermStart)
+	return validate.Struct(grade)
+}
+
+// AttendanceSheet normalizes the records of the sheet and validates it against the rules of types.AttendanceSheet
+// Statuses are lowercased and notes trimmed, so "Present" and "present" are the same status
+func AttendanceSheet(sheet *types.AttendanceSheet) error {
+	sheet.Date = strings.TrimSpace(sheet.Date)
+	for i := range sheet.Records {
+		sheet.Records[i].Status = strings.ToLower(strings.TrimSpace(sheet.Records[i].Status))
+		sheet.Records[i].Note = strings.TrimSpace(sheet.Records[i].Note)
+	}
+	return validate.Struct(sheet)
+}
+
+// phoneFormatting strips the characters people use to format phone numbers
+var phoneFormatting = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+// NormalizeGuardian puts the guardian's fields in their canonical form before validation
+// Phone numbers lose their formatting and a leading international "00" becomes "+", so "0044 20 7946 0000"
+// is stored as "+442079460000"; the priority defaults to 1, the first guardian to contact
+func NormalizeGuardian(guardian *types.Guardian) {
+	guardian.Name = strings.Join(strings.Fields(guardian.Name), " ")
+	guardian.Relationship = strings.ToLower(strings.TrimSpace(guardian.Relationship))
+	guardian.Email = strings.ToLower(strings.TrimSpace(guardian.Email))
+
+	guardian.Phone = phoneFormatting.Replace(guardian.Phone)
+	if strings.HasPrefix(guardian.Phone, "00") {
+		guardian.Phone = "+" + strings.TrimPrefix(guardian.Phone, "00")
+	}
+
+	if guardian.Priority == 0 {
+		guardian.Priority = 1
+	}
+}
+
+// Guardian normalizes the guardian and validates it against the rules of types.Guardian
+func Guardian(guardian *types.Guardian) error {
+	NormalizeGuardian(guardian)
+	return validate.Struct(guardian)
+}
+
+// GuardianLink normalizes the link like NormalizeGuardian and validates it against the rules of types.GuardianLink
+func GuardianLink(link *types.GuardianLink) error {
+	link.Relationship = strings.ToLower(strings.TrimSpace(link.Relationship))
+	if link.Priority == 0 {
+		link.Priority = 1
+	}
+	return validate.Struct(link)
+}
+
+// APIKeyRequest normalizes the request and validates it against the rules of types.APIKeyRequest
+// Scopes are trimmed, lowercased and deduplicated, their syntax is checked by the rbac package
+func APIKeyRequest(req *types.APIKeyRequest) error {
+	req.Name = strings.Join(strings.Fields(req.Name), " ")
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	req.Scopes = scopes
+
+	return validate.Struct(req)
+}
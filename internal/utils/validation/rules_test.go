@@ -0,0 +1,57 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+)
+
+func TestConfigure(t *testing.T) {
+	defaults := validation.Rules{AgeMin: 1, AgeMax: 120, NameMinLength: 2, NameMaxLength: 100}
+	t.Cleanup(func() {
+		if err := validation.Configure(defaults); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		rules validation.Rules
+		valid bool
+	}{
+		{"defaults", defaults, true},
+		{"newborns", validation.Rules{AgeMin: 0, AgeMax: 5, NameMinLength: 2, NameMaxLength: 100}, true},
+		{"single age", validation.Rules{AgeMin: 18, AgeMax: 18, NameMinLength: 1, NameMaxLength: 1}, true},
+		{"negative age", validation.Rules{AgeMin: -1, AgeMax: 120, NameMinLength: 2, NameMaxLength: 100}, false},
+		{"age range reversed", validation.Rules{AgeMin: 30, AgeMax: 20, NameMinLength: 2, NameMaxLength: 100}, false},
+		{"empty names", validation.Rules{AgeMin: 1, AgeMax: 120, NameMinLength: 0, NameMaxLength: 100}, false},
+		{"name range reversed", validation.Rules{AgeMin: 1, AgeMax: 120, NameMinLength: 50, NameMaxLength: 10}, false},
+	}
+
+	for _, test := range tests {
+		if err := validation.Configure(test.rules); (err == nil) != test.valid {
+			t.Errorf("%s: got error %v, want valid %v", test.name, err, test.valid)
+		}
+	}
+}
+
+func TestConfigureKeepsRulesOnError(t *testing.T) {
+	age := 0
+	t.Cleanup(func() {
+		if err := validation.Configure(validation.Rules{AgeMin: 1, AgeMax: 120, NameMinLength: 2, NameMaxLength: 100}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if err := validation.Configure(validation.Rules{AgeMin: -5, AgeMax: 120, NameMinLength: 2, NameMaxLength: 100}); err == nil {
+		t.Fatal("got no error for a negative age, want one")
+	}
+
+	// The refused rules are not applied, an age of 0 is still below the default minimum
+	messages := fieldErrors(t, &struct {
+		Age *int `json:"age" validate:"required,student_age"`
+	}{Age: &age}, "en")
+	if messages["age"] != "age must be between 1 and 120" {
+		t.Fatalf("got %q, want the default range", messages["age"])
+	}
+}
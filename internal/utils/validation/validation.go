@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/hi"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	"golang.org/x/text/language"
+)
+
+// Message keys of the texts that are not tied to a validation tag
+const (
+	// KeyTitle is the title of a validation problem
+	KeyTitle = "problem.validation.title"
+	// KeyDetail is the detail of a validation problem, {0} is the number of invalid fields
+	KeyDetail = "problem.validation.detail"
+)
+
+var (
+	// validate is the validator shared by every handler, it caches the rules of each struct
+	validate = validator.New()
+
+	// universal holds one translator per supported language, English is the fallback
+	universal = ut.New(en.New(), en.New(), es.New(), fr.New(), hi.New())
+
+	// matcher negotiates the Accept-Language header against the supported languages, English first as the default
+	matcher = language.NewMatcher([]language.Tag{language.English, language.Spanish, language.French, language.Hindi})
+)
+
+func init() {
+	// Report fields by their JSON name, so clients can match errors to the fields they sent
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	// Register the custom tags before their translations
+	if err := registerValidators(validate); err != nil {
+		panic(err)
+	}
+
+	// The validator ships translations for English, Spanish and French, Hindi is registered by this package
+	registrations := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"es": es_translations.RegisterDefaultTranslations,
+		"fr": fr_translations.RegisterDefaultTranslations,
+		"hi": registerHindi,
+	}
+
+	for locale, register := range registrations {
+		trans, _ := universal.GetTranslator(locale)
+
+		if err := register(validate, trans); err != nil {
+			panic(err)
+		}
+		for key, text := range messages[locale] {
+			if err := trans.Add(key, text, false); err != nil {
+				panic(err)
+			}
+		}
+		if err := registerRuleTranslations(validate, trans, ruleMessages[locale]); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Validator returns the shared validator
+func Validator() *validator.Validate {
+	return validate
+}
+
+// Translator returns the translator best matching an Accept-Language header
+// It falls back to English when the header is empty or names no supported language
+func Translator(acceptLanguage string) ut.Translator {
+	tag, _ := language.MatchStrings(matcher, acceptLanguage)
+	base, _ := tag.Base()
+
+	trans, found := universal.GetTranslator(base.String())
+	if !found {
+		trans = universal.GetFallback()
+	}
+
+	return trans
+}
+
+// Message returns the translation of a message key, or the key itself if it has none
+func Message(trans ut.Translator, key string, params ...string) string {
+	text, err := trans.T(key, params...)
+	if err != nil {
+		return key
+	}
+	return text
+}
@@ -0,0 +1,144 @@
+package validation_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Priyang1310/Students-API-GO/internal/types"
+	"github.com/Priyang1310/Students-API-GO/internal/utils/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestTranslator(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		locale         string
+	}{
+		{"", "en"},
+		{"es", "es"},
+		{"fr-CA", "fr"},
+		{"hi-IN", "hi"},
+		{"fr;q=0.5, es;q=0.8", "es"},
+		{"de, fr;q=0.3", "fr"},
+		{"*;q=0.5, hi", "hi"},
+		{"de-DE, ja", "en"},
+		{"not a language", "en"},
+	}
+
+	for _, test := range tests {
+		if locale := validation.Translator(test.acceptLanguage).Locale(); locale != test.locale {
+			t.Errorf("Accept-Language %q: got %s, want %s", test.acceptLanguage, locale, test.locale)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		locale string
+		title  string
+		detail string
+	}{
+		{"en", "Your request is not valid", "2 field(s) failed validation"},
+		{"es", "Su solicitud no es válida", "2 campo(s) no superaron la validación"},
+		{"fr", "Votre requête n'est pas valide", "2 champ(s) n'ont pas passé la validation"},
+		{"hi", "आपका अनुरोध मान्य नहीं है", "2 फ़ील्ड सत्यापन में विफल रहे"},
+	}
+
+	for _, test := range tests {
+		trans := validation.Translator(test.locale)
+		if title := validation.Message(trans, validation.KeyTitle); title != test.title {
+			t.Errorf("%s title: got %q, want %q", test.locale, title, test.title)
+		}
+		if detail := validation.Message(trans, validation.KeyDetail, "2"); detail != test.detail {
+			t.Errorf("%s detail: got %q, want %q", test.locale, detail, test.detail)
+		}
+	}
+
+	// A key without a translation comes back as it is
+	if text := validation.Message(validation.Translator("en"), "problem.unknown"); text != "problem.unknown" {
+		t.Fatalf("unknown key: got %q, want the key", text)
+	}
+}
+
+// fieldErrors validates the value and returns its field errors translated to the locale, keyed by field
+func fieldErrors(t *testing.T, value any, locale string) map[string]string {
+	t.Helper()
+
+	var errs validator.ValidationErrors
+	if err := validation.Validator().Struct(value); !errors.As(err, &errs) {
+		t.Fatalf("got %v, want validation errors", err)
+	}
+
+	messages := map[string]string{}
+	trans := validation.Translator(locale)
+	for _, fe := range errs {
+		messages[fe.Field()] = fe.Translate(trans)
+	}
+	return messages
+}
+
+func TestStudentTranslations(t *testing.T) {
+	age := 200
+	student := types.StudentRequest{Name: "R2-D2", Email: "not an email", Age: &age}
+
+	tests := []struct {
+		locale string
+		want   map[string]string
+	}{
+		{"en", map[string]string{
+			"name":  "name must be 2 to 100 characters long and contain only letters, spaces, apostrophes, hyphens and periods",
+			"email": "email must be a valid email address",
+			"age":   "age must be between 1 and 120",
+		}},
+		{"es", map[string]string{
+			"name":  "name debe tener entre 2 y 100 caracteres y contener solo letras, espacios, apóstrofos, guiones y puntos",
+			"email": "email debe ser una dirección de correo electrónico válida",
+			"age":   "age debe estar entre 1 y 120",
+		}},
+		{"fr", map[string]string{
+			"name":  "name doit contenir de 2 à 100 caractères et uniquement des lettres, espaces, apostrophes, traits d'union et points",
+			"email": "email doit être une adresse email valide",
+			"age":   "age doit être compris entre 1 et 120",
+		}},
+		{"hi", map[string]string{
+			"name":  "name 2 से 100 वर्णों का होना चाहिए और उसमें केवल अक्षर, रिक्त स्थान, एपॉस्ट्रॉफ़ी, हाइफ़न और पूर्णविराम हो सकते हैं",
+			"email": "email एक मान्य ईमेल पता होना चाहिए",
+			"age":   "age 1 और 120 के बीच होना चाहिए",
+		}},
+	}
+
+	for _, test := range tests {
+		messages := fieldErrors(t, &student, test.locale)
+		for field, want := range test.want {
+			if messages[field] != want {
+				t.Errorf("%s %s: got %q, want %q", test.locale, field, messages[field], want)
+			}
+		}
+	}
+}
+
+func TestHindiTextVariants(t *testing.T) {
+	value := struct {
+		Code    string   `json:"code" validate:"min=3"`
+		Credits int      `json:"credits" validate:"min=3"`
+		Term    string   `json:"term" validate:"required"`
+		Scopes  []string `json:"scopes" validate:"max=1"`
+	}{Code: "C", Credits: 1, Scopes: []string{"a", "b"}}
+
+	want := map[string]string{
+		// Limits of strings count characters
+		"code": "code कम से कम 3 वर्णों का होना चाहिए",
+		// Other kinds use the plain message
+		"credits": "credits कम से कम 3 होना चाहिए",
+		"scopes":  "scopes अधिकतम 1 हो सकता है",
+		// Tags without a text variant read the same for strings
+		"term": "term एक आवश्यक फ़ील्ड है",
+	}
+
+	messages := fieldErrors(t, &value, "hi")
+	for field, text := range want {
+		if messages[field] != text {
+			t.Errorf("%s: got %q, want %q", field, messages[field], text)
+		}
+	}
+}